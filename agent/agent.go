@@ -0,0 +1,323 @@
+/*
+ * Copyright (c) 2026 The XGo Authors (xgo.dev). All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/goplus/xai"
+)
+
+var (
+	// ErrMaxTurns is returned by `Run` if the agent doesn't stop within the
+	// maximum number of turns.
+	ErrMaxTurns = errors.New("agent: max turns exceeded")
+)
+
+// -----------------------------------------------------------------------------
+
+// Tool is a tool that the agent executes locally when the model uses it.
+type Tool interface {
+	// Name returns the unique name of the tool.
+	Name() string
+
+	// Description explains the functionality and usage of the tool to the model.
+	Description() string
+
+	// InputSchema returns the JSON schema of the tool input.
+	InputSchema() string
+
+	// Execute executes the tool with the JSON input generated by the model, and
+	// returns the output to be sent back to the model.
+	Execute(ctx context.Context, input json.RawMessage) (string, error)
+}
+
+// ToolTimeout is an optional interface of Tool. If a tool implements it, its
+// timeout overrides `Config.ToolTimeout`.
+type ToolTimeout interface {
+	Timeout() time.Duration
+}
+
+// -----------------------------------------------------------------------------
+
+// Turn represents a turn of the agent loop, i.e. a request to the model, its
+// response, and the tool calls made by the model.
+type Turn struct {
+	// Index is the index of the turn, starting from 0.
+	Index int
+
+	// Response is the response of the model.
+	Response xai.GenResponse
+
+	// ToolUses are the tool uses in the response.
+	ToolUses []xai.ToolUse
+
+	// Results are the results of the tool uses, in the same order. They are
+	// available after the tools are called.
+	Results []xai.ToolResult
+}
+
+// StopCondition is called after each turn, once the tools have been called. It
+// reports whether the agent should stop. If it shouldn't, but the model made no
+// tool call, nudge is sent to the model as a user message to keep it working.
+type StopCondition = func(ctx context.Context, turn *Turn) (stop bool, nudge string)
+
+// NoToolCalls returns a StopCondition that stops the agent when the model makes
+// no tool call, which means the model considers the task done.
+func NoToolCalls() StopCondition {
+	return func(ctx context.Context, turn *Turn) (bool, string) {
+		return len(turn.ToolUses) == 0, ""
+	}
+}
+
+// Until returns a StopCondition that stops the agent when the model makes no tool
+// call and done reports true. If done reports false, nudge is sent to the model.
+// It is useful when the task has verifiable deliverables.
+func Until(done func(ctx context.Context) bool, nudge string) StopCondition {
+	return func(ctx context.Context, turn *Turn) (bool, string) {
+		if len(turn.ToolUses) > 0 {
+			return false, ""
+		}
+		return done(ctx), nudge
+	}
+}
+
+// -----------------------------------------------------------------------------
+
+// Hooks are callbacks invoked by the agent loop, e.g. for logging and tracing.
+// All of them are optional. Tool calls run concurrently, so BeforeToolCall and
+// AfterToolCall may be called concurrently.
+type Hooks struct {
+	// OnTurn is called after the model responds, before the tools are called.
+	OnTurn func(ctx context.Context, turn *Turn)
+
+	// BeforeToolCall is called before a tool is called.
+	BeforeToolCall func(ctx context.Context, use xai.ToolUse)
+
+	// AfterToolCall is called after a tool is called.
+	AfterToolCall func(ctx context.Context, use xai.ToolUse, result xai.ToolResult)
+}
+
+// Config holds everything the agent needs.
+type Config struct {
+	// Service is the service of the model. Any registered Service can be used.
+	Service xai.Service
+
+	// Model is the model that drives the agent.
+	Model xai.Model
+
+	// System is the system prompt.
+	System string
+
+	// Tools are the tools that the model may use.
+	Tools []Tool
+
+	// MaxTurns is the upper bound of the turns (default 30).
+	MaxTurns int
+
+	// MaxOutputTokens is the maximum number of tokens of each response
+	// (default 8192).
+	MaxOutputTokens int64
+
+	// ToolTimeout is the timeout of each tool call. Zero means no timeout.
+	ToolTimeout time.Duration
+
+	// Params sets additional parameters of each request, e.g. `Thinking`.
+	Params func(params xai.GenParams)
+
+	// Stop decides when the agent stops (default `NoToolCalls`).
+	Stop StopCondition
+
+	Hooks
+}
+
+// Result is the result of `Run`.
+type Result struct {
+	// Turns is the number of turns.
+	Turns int
+
+	// Usage is the total token usage of all turns.
+	Usage xai.Usage
+
+	// Conversation holds the whole history, which can be used to continue
+	// the conversation with the model.
+	Conversation *xai.Conversation
+}
+
+// Run runs the agent loop on task until the stop condition is met. Each turn, the
+// model is called with the conversation so far, and the tools it uses are called
+// concurrently, with their results (or errors) sent back in the next turn.
+//
+// If the stop condition isn't met within `Config.MaxTurns` turns, Run returns
+// the result along with ErrMaxTurns.
+//
+// Runs may share a Service concurrently. The tools are defined on the Service by
+// the first run that uses them, so the runs should give the same description and
+// input schema to the tools of the same name.
+func Run(ctx context.Context, cfg Config, task string) (*Result, error) {
+	if cfg.MaxTurns <= 0 {
+		cfg.MaxTurns = 30
+	}
+	if cfg.MaxOutputTokens <= 0 {
+		cfg.MaxOutputTokens = 8192
+	}
+	if cfg.Stop == nil {
+		cfg.Stop = NoToolCalls()
+	}
+
+	svc := cfg.Service
+	tools := make(map[string]Tool, len(cfg.Tools))
+	for _, t := range cfg.Tools {
+		tools[t.Name()] = t
+	}
+	refs := defineTools(svc, cfg.Tools)
+
+	conv := xai.NewConversation(svc).Tools(refs...).Params(func(params xai.GenParams) {
+		params.Model(cfg.Model).MaxOutputTokens(cfg.MaxOutputTokens)
+		if cfg.Params != nil {
+			cfg.Params(params)
+		}
+	})
+	if cfg.System != "" {
+		conv.System(cfg.System)
+	}
+
+	ret := &Result{Conversation: conv}
+	msg := svc.UserMsg().Text(task)
+	for ret.Turns < cfg.MaxTurns {
+		var msgs []xai.MsgBuilder
+		if msg != nil {
+			msgs = append(msgs, msg)
+		}
+		resp, err := conv.Send(ctx, msgs...)
+		if err != nil {
+			return ret, fmt.Errorf("agent: turn %d: %w", ret.Turns, err)
+		}
+		ret.Usage.Add(resp.Usage())
+
+		turn := &Turn{Index: ret.Turns, Response: resp, ToolUses: conv.ToolUses()}
+		ret.Turns++
+		if cfg.OnTurn != nil {
+			cfg.OnTurn(ctx, turn)
+		}
+		turn.Results = callTools(ctx, &cfg, tools, turn.ToolUses)
+		for _, result := range turn.Results {
+			conv.ToolResult(result)
+		}
+
+		stop, nudge := cfg.Stop(ctx, turn)
+		if stop {
+			return ret, nil
+		}
+		msg = nil
+		if len(turn.ToolUses) == 0 {
+			if nudge == "" {
+				nudge = "Please continue."
+			}
+			msg = svc.UserMsg().Text(nudge)
+		}
+	}
+	return ret, ErrMaxTurns
+}
+
+// toolsMu serializes the tool definitions of runs, as services don't define tools
+// concurrently.
+var toolsMu sync.Mutex
+
+// defineTools returns the definitions of tools on svc. Tools are defined by the
+// first run that uses them, and reused as is by later ones, so that concurrent
+// runs don't change the definitions in use.
+func defineTools(svc xai.Service, tools []Tool) []xai.ToolBase {
+	toolsMu.Lock()
+	defer toolsMu.Unlock()
+	ret := make([]xai.ToolBase, len(tools))
+	for i, t := range tools {
+		ref := svc.Tool(t.Name())
+		if ref == nil {
+			ref = svc.ToolDef(t.Name()).Description(t.Description()).InputSchema(xai.RawMessage(t.InputSchema()))
+		}
+		ret[i] = ref
+	}
+	return ret
+}
+
+func callTools(ctx context.Context, cfg *Config, tools map[string]Tool, uses []xai.ToolUse) []xai.ToolResult {
+	results := make([]xai.ToolResult, len(uses))
+	var wg sync.WaitGroup
+	for i, use := range uses {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if cfg.BeforeToolCall != nil {
+				cfg.BeforeToolCall(ctx, use)
+			}
+			ret := xai.ToolResult{ID: use.ID, Name: use.Name}
+			if out, err := safeCallTool(ctx, cfg, tools, use); err != nil {
+				ret.Result, ret.IsError = err, true
+			} else {
+				ret.Result = out
+			}
+			if cfg.AfterToolCall != nil {
+				cfg.AfterToolCall(ctx, use, ret)
+			}
+			results[i] = ret
+		}()
+	}
+	wg.Wait()
+	return results
+}
+
+// safeCallTool calls the tool of use, and turns its panic into an error.
+func safeCallTool(ctx context.Context, cfg *Config, tools map[string]Tool, use xai.ToolUse) (out string, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("tool %s panicked: %v", use.Name, r)
+		}
+	}()
+	return callTool(ctx, cfg, tools, use)
+}
+
+func callTool(ctx context.Context, cfg *Config, tools map[string]Tool, use xai.ToolUse) (string, error) {
+	tool, ok := tools[use.Name]
+	if !ok {
+		return "", fmt.Errorf("unknown tool: %s", use.Name)
+	}
+	input, ok := use.Input.(xai.RawMessage)
+	if !ok {
+		var err error
+		if input, err = json.Marshal(use.Input); err != nil {
+			return "", fmt.Errorf("invalid tool input: %w", err)
+		}
+	}
+	timeout := cfg.ToolTimeout
+	if t, ok := tool.(ToolTimeout); ok {
+		timeout = t.Timeout()
+	}
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	return tool.Execute(ctx, input)
+}
+
+// -----------------------------------------------------------------------------
@@ -0,0 +1,203 @@
+/*
+ * Copyright (c) 2026 The XGo Authors (xgo.dev). All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/goplus/xai"
+)
+
+// -----------------------------------------------------------------------------
+
+type part struct {
+	xai.Part
+	use *xai.ToolUse
+}
+
+func (p part) AsToolUse() (ret xai.ToolUse, ok bool) {
+	if p.use != nil {
+		return *p.use, true
+	}
+	return
+}
+
+type resp []part
+
+func (p resp) Len() int                   { return 1 }
+func (p resp) At(i int) xai.Candidate     { return p }
+func (p resp) Usage() xai.Usage           { return xai.Usage{InputTokens: 10, OutputTokens: 1} }
+func (p resp) Parts() int                 { return len(p) }
+func (p resp) Part(i int) xai.Part        { return p[i] }
+func (p resp) StopReason() xai.StopReason { return xai.EndTurn }
+func (p resp) ToMsg() xai.MsgBuilder      { return msg{} }
+
+type msg struct{ xai.MsgBuilder }
+
+func (p msg) Text(text string) xai.MsgBuilder            { return p }
+func (p msg) ToolResult(v xai.ToolResult) xai.MsgBuilder { return p }
+
+type params struct{ xai.GenParams }
+
+func (p params) Model(xai.Model) xai.GenParams            { return p }
+func (p params) MaxOutputTokens(int64) xai.GenParams      { return p }
+func (p params) Tools(...xai.ToolBase) xai.GenParams      { return p }
+func (p params) Messages(...xai.MsgBuilder) xai.GenParams { return p }
+
+type toolDef struct{ xai.Tool }
+
+func (p toolDef) Description(string) xai.Tool { return p }
+func (p toolDef) InputSchema(any) xai.Tool    { return p }
+
+// service replies with the scripted responses in order.
+type service struct {
+	xai.Service
+	replies []resp
+	turn    int
+	tools   map[string]bool
+}
+
+func (p *service) ToolDef(name string) xai.Tool {
+	if p.tools[name] {
+		panic("tool already defined: " + name)
+	}
+	if p.tools == nil {
+		p.tools = make(map[string]bool)
+	}
+	p.tools[name] = true
+	return toolDef{}
+}
+
+func (p *service) Tool(name string) xai.Tool {
+	if p.tools[name] {
+		return toolDef{}
+	}
+	return nil
+}
+func (p *service) UserMsg() xai.MsgBuilder  { return msg{} }
+func (p *service) GenParams() xai.GenParams { return params{} }
+
+func (p *service) Gen(ctx context.Context, _ xai.GenParams) (xai.GenResponse, error) {
+	ret := p.replies[p.turn]
+	p.turn++
+	return ret, nil
+}
+
+type sleepTool struct{}
+
+func (sleepTool) Name() string           { return "sleep" }
+func (sleepTool) Description() string    { return "sleep for a while" }
+func (sleepTool) InputSchema() string    { return `{"type":"object"}` }
+func (sleepTool) Timeout() time.Duration { return time.Millisecond }
+
+func (sleepTool) Execute(ctx context.Context, input json.RawMessage) (string, error) {
+	select {
+	case <-ctx.Done():
+		return "", ctx.Err()
+	case <-time.After(time.Second):
+		return "done", nil
+	}
+}
+
+type panicTool struct{}
+
+func (panicTool) Name() string        { return "panic" }
+func (panicTool) Description() string { return "panic" }
+func (panicTool) InputSchema() string { return `{"type":"object"}` }
+
+func (panicTool) Execute(ctx context.Context, input json.RawMessage) (string, error) {
+	panic("boom")
+}
+
+func TestRun(t *testing.T) {
+	use := func(id, name string) part {
+		return part{use: &xai.ToolUse{ID: id, Name: name, Input: xai.RawMessage(`{}`)}}
+	}
+	svc := &service{replies: []resp{
+		{use("1", "sleep"), use("2", "unknown"), use("3", "panic")},
+		{},
+		{},
+	}}
+	var done, calls atomic.Int32
+	cfg := Config{
+		Service: svc,
+		Tools:   []Tool{sleepTool{}, panicTool{}},
+		Stop: Until(func(ctx context.Context) bool {
+			return done.Add(1) == 2
+		}, "keep going"),
+		Hooks: Hooks{
+			AfterToolCall: func(ctx context.Context, use xai.ToolUse, result xai.ToolResult) {
+				calls.Add(1)
+				if !result.IsError {
+					t.Errorf("tool %s: expected error, got %v", use.Name, result.Result)
+				}
+				if use.Name == "sleep" && !errors.Is(result.Result.(error), context.DeadlineExceeded) {
+					t.Errorf("tool sleep: expected timeout, got %v", result.Result)
+				}
+			},
+		},
+	}
+	ret, err := Run(context.Background(), cfg, "task")
+	if err != nil {
+		t.Fatal("Run:", err)
+	}
+	if ret.Turns != 3 || calls.Load() != 3 || ret.Usage.InputTokens != 30 {
+		t.Fatalf("Run: %d turns, %d tool calls, usage %+v", ret.Turns, calls.Load(), ret.Usage)
+	}
+
+	// tools defined by the first run are reused
+	svc.turn = 0
+	cfg.MaxTurns = 2
+	done.Store(-10)
+	if _, err = Run(context.Background(), cfg, "task"); err != ErrMaxTurns {
+		t.Fatal("Run: expected ErrMaxTurns, got", err)
+	}
+}
+
+// doneService replies with no tool uses.
+type doneService struct{ *service }
+
+func (p doneService) Gen(ctx context.Context, _ xai.GenParams) (xai.GenResponse, error) {
+	return resp{}, nil
+}
+
+func TestRunConcurrent(t *testing.T) {
+	svc := doneService{&service{}}
+	cfg := Config{Service: svc, Tools: []Tool{sleepTool{}, panicTool{}}}
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := Run(context.Background(), cfg, "task"); err != nil {
+				t.Error("Run:", err)
+			}
+		}()
+	}
+	wg.Wait()
+	if len(svc.tools) != 2 {
+		t.Fatal("tools:", svc.tools)
+	}
+}
+
+// -----------------------------------------------------------------------------
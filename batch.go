@@ -0,0 +1,50 @@
+/*
+ * Copyright (c) 2026 The XGo Authors (xgo.dev). All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package xai
+
+// -----------------------------------------------------------------------------
+
+// BatchResult is the result of a request of a batch submitted by SubmitBatch of
+// Service. It is returned by the Results of the BatchResponse, in the order of
+// the requests.
+type BatchResult struct {
+	// CustomID identifies the request in the batch, which is the index of the
+	// request in decimal, e.g. "0" for the first request.
+	CustomID string
+
+	// Response is the response of the request, or nil if it failed with Err.
+	Response GenResponse
+	Err      error
+}
+
+func (*BatchResult) generated() {}
+
+// BatchResponse is the OperationResponse of a batch submitted by SubmitBatch of
+// Service. Results of a batch are `*BatchResult`s, which are available once the
+// batch ends, i.e. Done returns true.
+type BatchResponse interface {
+	OperationResponse
+
+	// ID returns the ID of the batch, as of the provider.
+	ID() string
+
+	// Counts returns the number of ended (succeeded or failed) requests, and the
+	// number of all requests of the batch.
+	Counts() (ended, total int)
+}
+
+// -----------------------------------------------------------------------------
@@ -0,0 +1,209 @@
+/*
+ * Copyright (c) 2026 The XGo Authors (xgo.dev). All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package xai
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// -----------------------------------------------------------------------------
+
+// GenCodec is implemented by services whose `Gen` responses can be cached by
+// `Cache`.
+type GenCodec interface {
+	// MarshalGenParams returns the canonical encoding of params, which covers
+	// the model, system prompt, messages, tools and sampling parameters, but not
+	// the request options such as BaseURL and Timeout. Equal encodings mean the
+	// same request.
+	MarshalGenParams(params GenParams) ([]byte, error)
+
+	// MarshalGenResponse encodes a response returned by `Gen`.
+	MarshalGenResponse(resp GenResponse) ([]byte, error)
+
+	// UnmarshalGenResponse decodes a response encoded by MarshalGenResponse.
+	UnmarshalGenResponse(data []byte) (GenResponse, error)
+}
+
+// CacheStore stores cached responses by their keys. It must be safe for
+// concurrent use.
+type CacheStore interface {
+	// Get returns the data stored with key, or false if there is none.
+	Get(key string) ([]byte, bool)
+
+	// Put stores data with key.
+	Put(key string, data []byte) error
+}
+
+// Cache returns a Service that caches the responses of `Gen`, keyed on the
+// canonical encoding of its GenParams (see `GenCodec`). Responses from the cache
+// support all methods, including `Candidate.ToMsg` and `Candidate.Part`. Other
+// methods are forwarded to svc.
+//
+// It returns svc itself if neither svc nor the services it wraps (see `Unwrap`)
+// implement GenCodec.
+func Cache(svc Service, store CacheStore) Service {
+	codec, ok := unwrapAs[GenCodec](svc)
+	if !ok {
+		return svc
+	}
+	return &cacheService{Service: svc, codec: codec, store: store}
+}
+
+// Unwrap returns the Service wrapped by svc, e.g. by `Wrap` or `Cache`. It returns
+// nil if svc doesn't wrap another Service.
+func Unwrap(svc Service) Service {
+	if w, ok := svc.(interface{ Unwrap() Service }); ok {
+		return w.Unwrap()
+	}
+	return nil
+}
+
+func unwrapAs[T any](svc Service) (ret T, ok bool) {
+	for svc != nil {
+		if ret, ok = svc.(T); ok {
+			return
+		}
+		svc = Unwrap(svc)
+	}
+	return
+}
+
+type cacheService struct {
+	Service
+	codec GenCodec
+	store CacheStore
+}
+
+func (p *cacheService) Unwrap() Service {
+	return p.Service
+}
+
+func (p *cacheService) Gen(ctx context.Context, params GenParams) (GenResponse, error) {
+	data, err := p.codec.MarshalGenParams(params)
+	if err != nil {
+		return p.Service.Gen(ctx, params)
+	}
+	sum := sha256.Sum256(data)
+	key := hex.EncodeToString(sum[:])
+	if data, ok := p.store.Get(key); ok {
+		if resp, err := p.codec.UnmarshalGenResponse(data); err == nil {
+			return resp, nil
+		}
+	}
+	resp, err := p.Service.Gen(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+	if data, err := p.codec.MarshalGenResponse(resp); err == nil {
+		p.store.Put(key, data) // caching is best-effort
+	}
+	return resp, nil
+}
+
+// -----------------------------------------------------------------------------
+
+type lruEntry struct {
+	key  string
+	data []byte
+}
+
+type lruCache struct {
+	mu    sync.Mutex
+	max   int
+	items map[string]*list.Element
+	order list.List // most recently used first
+}
+
+// NewLRUCache creates an in-memory CacheStore that keeps at most maxEntries
+// entries, evicting the least recently used ones.
+func NewLRUCache(maxEntries int) CacheStore {
+	return &lruCache{max: maxEntries, items: make(map[string]*list.Element)}
+}
+
+func (p *lruCache) Get(key string) ([]byte, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if e, ok := p.items[key]; ok {
+		p.order.MoveToFront(e)
+		return e.Value.(*lruEntry).data, true
+	}
+	return nil, false
+}
+
+func (p *lruCache) Put(key string, data []byte) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if e, ok := p.items[key]; ok {
+		e.Value.(*lruEntry).data = data
+		p.order.MoveToFront(e)
+		return nil
+	}
+	p.items[key] = p.order.PushFront(&lruEntry{key, data})
+	for p.max > 0 && p.order.Len() > p.max {
+		e := p.order.Back()
+		p.order.Remove(e)
+		delete(p.items, e.Value.(*lruEntry).key)
+	}
+	return nil
+}
+
+// -----------------------------------------------------------------------------
+
+type dirCache struct {
+	dir string
+}
+
+// NewDirCache creates an on-disk CacheStore that stores each entry as a file in
+// dir. The directory is created if it doesn't exist.
+func NewDirCache(dir string) (CacheStore, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	return dirCache{dir}, nil
+}
+
+func (p dirCache) Get(key string) ([]byte, bool) {
+	data, err := os.ReadFile(filepath.Join(p.dir, key))
+	return data, err == nil
+}
+
+func (p dirCache) Put(key string, data []byte) error {
+	// write to a temporary file first, so that readers never see partial data
+	f, err := os.CreateTemp(p.dir, key+".*.tmp")
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(data)
+	if e := f.Close(); err == nil {
+		err = e
+	}
+	if err == nil {
+		err = os.Rename(f.Name(), filepath.Join(p.dir, key))
+	}
+	if err != nil {
+		os.Remove(f.Name())
+	}
+	return err
+}
+
+// -----------------------------------------------------------------------------
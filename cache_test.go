@@ -0,0 +1,86 @@
+/*
+ * Copyright (c) 2026 The XGo Authors (xgo.dev). All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package xai
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+// -----------------------------------------------------------------------------
+
+// codecService is an echoService that implements GenCodec and counts its calls.
+type codecService struct {
+	echoService
+	calls *int
+}
+
+func (p codecService) Gen(ctx context.Context, params GenParams) (GenResponse, error) {
+	*p.calls++
+	return p.echoService.Gen(ctx, params)
+}
+
+func (p codecService) MarshalGenParams(params GenParams) ([]byte, error) {
+	var parts []string
+	for _, msg := range params.(*echoParams).msgs {
+		parts = append(parts, msg.(*echoMsg).String())
+	}
+	return []byte(strings.Join(parts, "\n")), nil
+}
+
+func (p codecService) MarshalGenResponse(resp GenResponse) ([]byte, error) {
+	return []byte(resp.At(0).Part(0).Text()), nil
+}
+
+func (p codecService) UnmarshalGenResponse(data []byte) (GenResponse, error) {
+	text := string(data)
+	return echoResp{textCandidate{textPart(text)}, &echoMsg{role: "assistant", parts: []string{text}}}, nil
+}
+
+func TestCache(t *testing.T) {
+	dir, err := NewDirCache(t.TempDir())
+	if err != nil {
+		t.Fatal("NewDirCache:", err)
+	}
+	for _, store := range []CacheStore{NewLRUCache(1), dir} {
+		var calls int
+		svc := Cache(Wrap(codecService{calls: &calls}, Middleware{}), store)
+		gen := func(text string) string {
+			params := svc.GenParams().Messages(svc.UserMsg().Text(text), svc.UserMsg().Text(text))
+			resp, err := svc.Gen(context.Background(), params)
+			if err != nil {
+				t.Fatal("Gen:", err)
+			}
+			return resp.At(0).ToMsg().(*echoMsg).String()
+		}
+		for _, text := range []string{"a", "a", "b", "a"} {
+			if got := gen(text); got != "assistant:2" {
+				t.Fatal("Gen:", got)
+			}
+		}
+		// the LRU cache keeps only one entry, so "a" is evicted by "b"
+		want := 2
+		if store != dir {
+			want = 3
+		}
+		if calls != want {
+			t.Fatalf("%T: %d calls, want %d", store, calls, want)
+		}
+	}
+}
+
+// -----------------------------------------------------------------------------
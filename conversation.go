@@ -0,0 +1,262 @@
+/*
+ * Copyright (c) 2026 The XGo Authors (xgo.dev). All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package xai
+
+import (
+	"context"
+	"errors"
+	"iter"
+	"slices"
+	"strings"
+)
+
+// -----------------------------------------------------------------------------
+
+// Conversation is a multi-turn conversation with a model. It owns the history of
+// the conversation: the messages sent in each turn and the responses of the
+// model are appended automatically, and the tool results of a turn are collected
+// and sent with the next turn.
+//
+// A turn is a request to the model and its response. The history can be rewound
+// to any turn, or forked into an independent conversation.
+//
+// A Conversation is not safe for concurrent use.
+type Conversation struct {
+	svc    Service
+	system []string
+	tools  []ToolBase
+	params []func(GenParams)
+
+	msgs    []MsgBuilder
+	turns   []convTurn
+	results []ToolResult // tool results to be sent with the next turn
+}
+
+type convTurn struct {
+	start int // index of the first message of the turn
+	resp  GenResponse
+}
+
+// NewConversation creates a conversation with the model of the service svc.
+func NewConversation(svc Service) *Conversation {
+	return &Conversation{svc: svc}
+}
+
+// Service returns the service of the conversation.
+func (p *Conversation) Service() Service {
+	return p.svc
+}
+
+// System sets the system prompt of the conversation.
+func (p *Conversation) System(prompt ...string) *Conversation {
+	p.system = prompt
+	return p
+}
+
+// Tools sets the tools that the model may use in the conversation.
+func (p *Conversation) Tools(tools ...ToolBase) *Conversation {
+	p.tools = tools
+	return p
+}
+
+// Params adds a function that sets parameters of each request, e.g. the model
+// and `MaxOutputTokens`. The system prompt, tools and messages are set by the
+// conversation itself.
+func (p *Conversation) Params(fn func(params GenParams)) *Conversation {
+	p.params = append(p.params, fn)
+	return p
+}
+
+// Messages returns the messages of the conversation so far.
+func (p *Conversation) Messages() []MsgBuilder {
+	return slices.Clone(p.msgs)
+}
+
+// Turns returns the number of turns of the conversation.
+func (p *Conversation) Turns() int {
+	return len(p.turns)
+}
+
+// Response returns the response of the i-th turn.
+func (p *Conversation) Response(i int) GenResponse {
+	return p.turns[i].resp
+}
+
+// LastResponse returns the response of the last turn, or nil if there is no turn.
+func (p *Conversation) LastResponse() GenResponse {
+	if n := len(p.turns); n > 0 {
+		return p.turns[n-1].resp
+	}
+	return nil
+}
+
+// ToolUses returns the tool uses in the response of the last turn. Uses of the
+// standard tools, whose names start with "std/", are excluded, as they are run by
+// the provider and their results are in the response.
+func (p *Conversation) ToolUses() (ret []ToolUse) {
+	resp := p.LastResponse()
+	if resp == nil || resp.Len() == 0 {
+		return
+	}
+	c := resp.At(0)
+	for i, n := 0, c.Parts(); i < n; i++ {
+		if use, ok := c.Part(i).AsToolUse(); ok && !strings.HasPrefix(use.Name, "std/") {
+			ret = append(ret, use)
+		}
+	}
+	return
+}
+
+// ToolResult adds the result of a tool use of the last turn. Tool results are
+// sent with the next turn.
+func (p *Conversation) ToolResult(v ToolResult) *Conversation {
+	p.results = append(p.results, v)
+	return p
+}
+
+// CallTools calls the Go tools for the tool uses of the last turn, and adds their
+// results by `ToolResult`. Tool uses of unknown tools are reported as error
+// results. It returns the number of tool uses.
+func (p *Conversation) CallTools(ctx context.Context, tools ...*GoTool) int {
+	uses := p.ToolUses()
+	for _, use := range uses {
+		idx := slices.IndexFunc(tools, func(t *GoTool) bool { return t.Name() == use.Name })
+		if idx < 0 {
+			p.ToolResult(ToolResult{
+				ID: use.ID, Name: use.Name, Result: errors.New("unknown tool: " + use.Name), IsError: true,
+			})
+			continue
+		}
+		p.ToolResult(tools[idx].Call(ctx, use))
+	}
+	return len(uses)
+}
+
+// Send sends msgs, along with the tool results collected since the last turn, to
+// the model as a new turn, and appends them and the response to the history.
+// Call it without msgs to send the tool results only. If it fails, the history
+// is left unchanged.
+func (p *Conversation) Send(ctx context.Context, msgs ...MsgBuilder) (GenResponse, error) {
+	params, history := p.request(msgs)
+	resp, err := p.svc.Gen(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+	p.commit(history, resp)
+	return resp, nil
+}
+
+// SendText sends a user message with the text to the model. See `Send`.
+func (p *Conversation) SendText(ctx context.Context, text string) (GenResponse, error) {
+	return p.Send(ctx, p.svc.UserMsg().Text(text))
+}
+
+// Stream is like `Send`, but streams the response. The history is updated when
+// the stream is finished, i.e. when the EventStop event is yielded.
+func (p *Conversation) Stream(ctx context.Context, msgs ...MsgBuilder) iter.Seq2[StreamEvent, error] {
+	params, history := p.request(msgs)
+	return func(yield func(StreamEvent, error) bool) {
+		for ev, err := range p.svc.GenStream(ctx, params) {
+			if err == nil && ev.Type == EventStop {
+				p.commit(history, ev.Response)
+			}
+			if !yield(ev, err) || err != nil {
+				return
+			}
+		}
+	}
+}
+
+func (p *Conversation) request(msgs []MsgBuilder) (GenParams, []MsgBuilder) {
+	history := slices.Clip(p.msgs) // appending to history doesn't modify p.msgs
+	if len(p.results) > 0 {
+		msg := p.svc.UserMsg()
+		for _, v := range p.results {
+			msg.ToolResult(v)
+		}
+		history = append(history, msg)
+	}
+	history = append(history, msgs...)
+
+	params := p.svc.GenParams()
+	for _, fn := range p.params {
+		fn(params)
+	}
+	if len(p.system) > 0 {
+		params.System(p.system...)
+	}
+	if len(p.tools) > 0 {
+		params.Tools(p.tools...)
+	}
+	params.Messages(history...)
+	return params, history
+}
+
+func (p *Conversation) commit(history []MsgBuilder, resp GenResponse) {
+	p.turns = append(p.turns, convTurn{start: len(p.msgs), resp: resp})
+	if resp.Len() > 0 {
+		history = append(history, resp.At(0).ToMsg())
+	}
+	p.msgs = history
+	p.results = nil
+}
+
+// Rewind rewinds the conversation to the start of the i-th turn, i.e. it removes
+// the i-th and later turns from the history, as well as the pending tool results.
+// Rewind(0) clears the history. i is clamped to [0, p.Turns()].
+func (p *Conversation) Rewind(i int) *Conversation {
+	i = min(max(i, 0), len(p.turns))
+	start := len(p.msgs)
+	if i < len(p.turns) {
+		start = p.turns[i].start
+	}
+	p.msgs = p.msgs[:start:start]
+	p.turns = p.turns[:i:i]
+	p.results = nil
+	return p
+}
+
+// Fork returns an independent copy of the conversation, rewound to the start of
+// the i-th turn. Fork(p.Turns()) copies the whole conversation, including the
+// pending tool results. i is clamped to [0, p.Turns()] as Rewind does.
+func (p *Conversation) Fork(i int) *Conversation {
+	ret := *p
+	ret.params = slices.Clip(p.params)
+	ret.msgs = slices.Clone(p.msgs)
+	ret.turns = slices.Clone(p.turns)
+	ret.results = slices.Clone(p.results)
+	if i < len(p.turns) {
+		ret.Rewind(i)
+	}
+	return &ret
+}
+
+// Transcript exports the history of the conversation to a Transcript, e.g. to
+// persist it. The pending tool results are not exported. See `ExportMsgs`.
+func (p *Conversation) Transcript() (*Transcript, error) {
+	return ExportMsgs(p.msgs...)
+}
+
+// Load appends the messages of the transcript t, e.g. exported by a conversation
+// with another service, to the history. It should be called before the first
+// turn, as the messages loaded are not counted as turns and kept by `Rewind`.
+func (p *Conversation) Load(t *Transcript) *Conversation {
+	p.msgs = append(slices.Clip(p.msgs), t.Msgs(p.svc)...)
+	return p
+}
+
+// -----------------------------------------------------------------------------
@@ -0,0 +1,132 @@
+/*
+ * Copyright (c) 2026 The XGo Authors (xgo.dev). All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package xai
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// -----------------------------------------------------------------------------
+
+// echoMsg is a fake MsgBuilder that records its content as text.
+type echoMsg struct {
+	MsgBuilder
+	role  string
+	parts []string
+}
+
+func (p *echoMsg) Text(text string) MsgBuilder {
+	p.parts = append(p.parts, text)
+	return p
+}
+
+func (p *echoMsg) ToolResult(v ToolResult) MsgBuilder {
+	p.parts = append(p.parts, fmt.Sprintf("%s=%v", v.ID, v.Result))
+	return p
+}
+
+func (p *echoMsg) String() string {
+	return p.role + ":" + strings.Join(p.parts, ",")
+}
+
+type echoResp struct {
+	textCandidate
+	msg *echoMsg
+}
+
+func (p echoResp) Len() int               { return 1 }
+func (p echoResp) At(i int) Candidate     { return p }
+func (p echoResp) Usage() Usage           { return Usage{} }
+func (p echoResp) ToMsg() MsgBuilder      { return p.msg }
+func (p echoResp) StopReason() StopReason { return EndTurn }
+
+type echoParams struct {
+	GenParams
+	msgs []MsgBuilder
+}
+
+func (p *echoParams) Messages(msgs ...MsgBuilder) GenParams {
+	p.msgs = msgs
+	return p
+}
+
+// echoService is a fake Service that replies with the number of input messages.
+type echoService struct {
+	Service
+}
+
+func (p echoService) UserMsg() MsgBuilder  { return &echoMsg{role: "user"} }
+func (p echoService) GenParams() GenParams { return &echoParams{} }
+
+func (p echoService) Gen(ctx context.Context, params GenParams) (GenResponse, error) {
+	text := fmt.Sprint(len(params.(*echoParams).msgs))
+	return echoResp{textCandidate{textPart(text)}, &echoMsg{role: "assistant", parts: []string{text}}}, nil
+}
+
+func historyOf(c *Conversation) string {
+	var ret []string
+	for _, msg := range c.Messages() {
+		ret = append(ret, msg.(*echoMsg).String())
+	}
+	return strings.Join(ret, " ")
+}
+
+func TestConversation(t *testing.T) {
+	ctx := context.Background()
+	c := NewConversation(echoService{})
+	c.SendText(ctx, "hi")
+	c.ToolResult(ToolResult{ID: "1", Result: "ok"})
+	c.Send(ctx)
+	c.SendText(ctx, "bye")
+	if got := historyOf(c); got != "user:hi assistant:1 user:1=ok assistant:3 user:bye assistant:5" {
+		t.Fatal("history:", got)
+	}
+	if c.Turns() != 3 {
+		t.Fatal("Turns:", c.Turns())
+	}
+
+	fork := c.Fork(1)
+	fork.SendText(ctx, "again")
+	if got := historyOf(fork); got != "user:hi assistant:1 user:again assistant:3" {
+		t.Fatal("fork:", got)
+	}
+	if got := historyOf(c); got != "user:hi assistant:1 user:1=ok assistant:3 user:bye assistant:5" {
+		t.Fatal("history after fork:", got)
+	}
+
+	c.Rewind(2)
+	c.SendText(ctx, "ok")
+	if got := historyOf(c); got != "user:hi assistant:1 user:1=ok assistant:3 user:ok assistant:5" {
+		t.Fatal("rewind:", got)
+	}
+
+	// out-of-range turns are clamped
+	if fork = c.Fork(10); fork.Turns() != 3 {
+		t.Fatal("Fork(10):", fork.Turns())
+	}
+	if fork = c.Fork(-1); fork.Turns() != 0 || historyOf(fork) != "" {
+		t.Fatal("Fork(-1):", historyOf(fork))
+	}
+	if c.Rewind(10).Turns() != 3 || c.Rewind(-1).Turns() != 0 {
+		t.Fatal("Rewind: out of range")
+	}
+}
+
+// -----------------------------------------------------------------------------
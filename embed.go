@@ -0,0 +1,63 @@
+/*
+ * Copyright (c) 2026 The XGo Authors (xgo.dev). All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package xai
+
+// -----------------------------------------------------------------------------
+
+// EmbedTask is the intended use of embeddings, which models may optimize the
+// embeddings for. It is ignored by providers that don't support it, e.g. OpenAI.
+type EmbedTask string
+
+const (
+	EmbedTaskDefault        EmbedTask = ""
+	EmbedRetrievalQuery     EmbedTask = "RETRIEVAL_QUERY"
+	EmbedRetrievalDocument  EmbedTask = "RETRIEVAL_DOCUMENT"
+	EmbedSemanticSimilarity EmbedTask = "SEMANTIC_SIMILARITY"
+	EmbedClassification     EmbedTask = "CLASSIFICATION"
+	EmbedClustering         EmbedTask = "CLUSTERING"
+	EmbedQuestionAnswering  EmbedTask = "QUESTION_ANSWERING"
+	EmbedCodeRetrieval      EmbedTask = "CODE_RETRIEVAL_QUERY"
+)
+
+// EmbedParams is the parameters of an `Embed` request.
+type EmbedParams struct {
+	Model Model
+
+	// Texts and Images are the inputs to embed. An embedding is returned for each
+	// text, then for each image. Images are created by `Service.Images`, and are
+	// only supported by multimodal embedding models.
+	Texts  []string
+	Images []ImageData
+
+	// Dimensions is the number of dimensions of the embeddings, if the model
+	// supports reducing them. Zero means the default of the model.
+	Dimensions int64
+
+	// Task is the intended use of the embeddings.
+	Task EmbedTask
+}
+
+// Embeddings is the response of an `Embed` request.
+type Embeddings struct {
+	// Vectors holds the embeddings of the texts, then the images, in order.
+	Vectors [][]float32
+
+	// Usage is the token usage of the request, if reported by the provider.
+	Usage Usage
+}
+
+// -----------------------------------------------------------------------------
@@ -0,0 +1,97 @@
+/*
+ * Copyright (c) 2026 The XGo Authors (xgo.dev). All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package xai
+
+import (
+	"errors"
+	"time"
+)
+
+// -----------------------------------------------------------------------------
+
+// Provider-neutral error kinds. Errors returned by `Gen`, `GenStream` and
+// `Operation.Call` wrap one of them, so callers can test them with `errors.Is`
+// without knowing which provider served the request.
+var (
+	// ErrRateLimited is returned when the request is rejected because of a rate
+	// limit or quota (HTTP 429). Use `errors.As` with *Error to get RetryAfter.
+	ErrRateLimited = errors.New("rate limited")
+
+	// ErrAuth is returned when the credentials are missing, invalid, or don't
+	// have permission to perform the request (HTTP 401, 403).
+	ErrAuth = errors.New("authentication failed")
+
+	// ErrContextWindowExceeded is returned when the input (prompt plus requested
+	// output tokens) doesn't fit into the context window of the model.
+	ErrContextWindowExceeded = errors.New("context window exceeded")
+
+	// ErrInvalidRequest is returned when the provider rejects the request as
+	// malformed or invalid (HTTP 400, 413, 422).
+	ErrInvalidRequest = errors.New("invalid request")
+
+	// ErrOverloaded is returned when the provider is temporarily unable to serve
+	// the request (HTTP 503, 529).
+	ErrOverloaded = errors.New("service overloaded")
+
+	// ErrServer is returned when the provider fails with an internal error
+	// (HTTP 500, 502, 504).
+	ErrServer = errors.New("server error")
+
+	// ErrContentFiltered is returned when the input or output is blocked by the
+	// safety or content policy filters of the provider.
+	ErrContentFiltered = errors.New("content filtered")
+)
+
+// Error represents a failed provider request. Kind is one of the error kinds
+// defined in this package (ErrRateLimited, ErrAuth, ErrNotFound, etc.), and Err
+// is the original error returned by the provider SDK. Both are reachable from
+// `errors.Is` and `errors.As`.
+type Error struct {
+	// Kind is the provider-neutral kind of the error.
+	Kind error
+
+	// Err is the original error returned by the provider.
+	Err error
+
+	// StatusCode is the HTTP status code of the response, or 0 if unknown.
+	StatusCode int
+
+	// RetryAfter is the delay the provider asks to wait before retrying, or 0
+	// if the provider didn't specify it.
+	RetryAfter time.Duration
+}
+
+func (e *Error) Error() string {
+	return e.Kind.Error() + ": " + e.Err.Error()
+}
+
+func (e *Error) Unwrap() []error {
+	return []error{e.Kind, e.Err}
+}
+
+// RetryAfter returns the delay the provider asks to wait before retrying the
+// request that failed with err. It returns false if err is not an *Error or
+// the provider didn't specify the delay.
+func RetryAfter(err error) (time.Duration, bool) {
+	var e *Error
+	if errors.As(err, &e) && e.RetryAfter > 0 {
+		return e.RetryAfter, true
+	}
+	return 0, false
+}
+
+// -----------------------------------------------------------------------------
@@ -0,0 +1,92 @@
+/*
+ * Copyright (c) 2026 The XGo Authors (xgo.dev). All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package xai
+
+import (
+	"context"
+	"io"
+	"iter"
+	"time"
+)
+
+// -----------------------------------------------------------------------------
+
+// File is a file uploaded by `Files.Upload`, which is referenced by its ID in the
+// messages of later requests, e.g. m.ImageFile(f.AsImage()), so that it isn't
+// sent with every request.
+type File struct {
+	// ID is the fileID passed to the ImageFile and DocFile methods of MsgBuilder.
+	ID string
+
+	// Name is the display name or filename of the file.
+	Name string
+
+	// MIME type of the file.
+	MIME string
+
+	// Size of the file in bytes.
+	Size int64
+
+	CreatedAt time.Time
+
+	// ExpiresAt is when the file is deleted by the provider, or zero if it is
+	// kept until deleted.
+	ExpiresAt time.Time
+}
+
+// AsImage returns the arguments of `MsgBuilder.ImageFile` for the file.
+func (p *File) AsImage() (mime ImageType, fileID string) {
+	return ImageType(p.MIME), p.ID
+}
+
+// AsDoc returns the arguments of `MsgBuilder.DocFile` for the file.
+func (p *File) AsDoc() (mime DocumentType, fileID string) {
+	return DocumentType(p.MIME), p.ID
+}
+
+// UploadParams are the parameters of `Files.Upload`.
+type UploadParams struct {
+	// Name is the display name or filename of the file.
+	Name string
+
+	// MIME type of the file. Required.
+	MIME string
+
+	// ExpiresIn is how long the file is kept after upload, if it isn't zero and
+	// the provider supports it. Some providers expire files regardless, e.g. the
+	// files of Gemini expire after 48 hours.
+	ExpiresIn time.Duration
+}
+
+// Files manages the files uploaded to a provider. Files of a provider only work
+// with the services of the same provider (and account).
+type Files interface {
+	// Upload uploads a file of the content read from src.
+	Upload(ctx context.Context, src io.Reader, params UploadParams) (*File, error)
+
+	// Get returns the file of id, or an error that wraps ErrNotFound if there is
+	// no such file.
+	Get(ctx context.Context, id string) (*File, error)
+
+	// List lists the uploaded files.
+	List(ctx context.Context) iter.Seq2[*File, error]
+
+	// Delete deletes the file of id.
+	Delete(ctx context.Context, id string) error
+}
+
+// -----------------------------------------------------------------------------
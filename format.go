@@ -0,0 +1,150 @@
+/*
+ * Copyright (c) 2026 The XGo Authors (xgo.dev). All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package xai
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// -----------------------------------------------------------------------------
+
+// FormatType represents the type of the output that the model must generate.
+type FormatType string
+
+const (
+	FormatText       FormatType = "text"        // plain text (default)
+	FormatJSON       FormatType = "json_object" // any valid JSON object
+	FormatJSONSchema FormatType = "json_schema" // JSON that matches a schema
+)
+
+// ResponseFormat specifies the format of the output that the model must generate.
+// Use `JSONSchema` or `JSONObject` to create a ResponseFormat, and pass it to
+// `GenParams.ResponseFormat`.
+type ResponseFormat struct {
+	Type FormatType
+
+	// Name of the schema. Required by some providers when Type is FormatJSONSchema.
+	Name string
+
+	// Description of the schema, used by the model to determine how to respond.
+	Description string
+
+	// Schema is the JSON schema the output must match when Type is FormatJSONSchema.
+	Schema map[string]any
+
+	// Strict enables strict schema adherence on providers that support it. In
+	// strict mode, only a subset of JSON schema is supported, e.g. all object
+	// properties must be required and `additionalProperties` must be false.
+	Strict bool
+}
+
+// JSONSchema creates a ResponseFormat that constrains the output to JSON that
+// matches schema. The schema expects anything that can be marshaled to JSON,
+// including RawMessage. It returns an error if the schema is not a JSON object.
+func JSONSchema(name string, schema any) (ResponseFormat, error) {
+	m, err := parseSchema(schema)
+	if err != nil {
+		return ResponseFormat{}, err
+	}
+	return ResponseFormat{Type: FormatJSONSchema, Name: name, Schema: m}, nil
+}
+
+// MustJSONSchema is like JSONSchema but panics if the schema is invalid. It is
+// intended for schemas known to be valid, e.g. constants of the program.
+func MustJSONSchema(name string, schema any) ResponseFormat {
+	ret, err := JSONSchema(name, schema)
+	if err != nil {
+		panic(err)
+	}
+	return ret
+}
+
+// ParseSchema converts a JSON schema into the generic form used by providers.
+// The schema expects anything that can be marshaled to JSON, including RawMessage.
+// It panics if the schema is not a JSON object.
+func ParseSchema(schema any) map[string]any {
+	ret, err := parseSchema(schema)
+	if err != nil {
+		panic(err)
+	}
+	return ret
+}
+
+func parseSchema(schema any) (map[string]any, error) {
+	var b []byte
+	if v, ok := schema.(RawMessage); ok {
+		b = v
+	} else {
+		var err error
+		if b, err = json.Marshal(schema); err != nil {
+			return nil, fmt.Errorf("invalid json schema: %w", err)
+		}
+	}
+	// always round-trip through JSON, so that the schema has the same value
+	// types (float64, []any, etc.) as values decoded by encoding/json.
+	var ret map[string]any
+	if err := json.Unmarshal(b, &ret); err != nil {
+		return nil, fmt.Errorf("invalid json schema: %w", err)
+	}
+	return ret, nil
+}
+
+// JSONObject creates a ResponseFormat that constrains the output to a valid JSON
+// object without a schema.
+func JSONObject() ResponseFormat {
+	return ResponseFormat{Type: FormatJSON}
+}
+
+// Decode decodes the text of the candidate c into v. If the format has a schema,
+// the text is validated against it first, and violations are reported as errors
+// that wrap *SchemaError.
+func (f ResponseFormat) Decode(c Candidate, v any) error {
+	text := jsonText(textOf(c))
+	data := []byte(text)
+	if f.Schema != nil {
+		if err := ValidateJSON(f.Schema, data); err != nil {
+			return err
+		}
+	}
+	return json.Unmarshal(data, v)
+}
+
+// textOf returns the output text of the candidate c.
+func textOf(c Candidate) string {
+	var b strings.Builder
+	for i, n := 0, c.Parts(); i < n; i++ {
+		b.WriteString(c.Part(i).Text())
+	}
+	return b.String()
+}
+
+// jsonText strips the markdown code fence that some models wrap around JSON.
+func jsonText(text string) string {
+	text = strings.TrimSpace(text)
+	if strings.HasPrefix(text, "```") && strings.HasSuffix(text, "```") {
+		text = strings.TrimSuffix(text[3:], "```")
+		if pos := strings.IndexByte(text, '\n'); pos >= 0 {
+			text = text[pos+1:] // skip the language tag, e.g. "json"
+		}
+		text = strings.TrimSpace(text)
+	}
+	return text
+}
+
+// -----------------------------------------------------------------------------
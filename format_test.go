@@ -0,0 +1,118 @@
+/*
+ * Copyright (c) 2026 The XGo Authors (xgo.dev). All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package xai
+
+import (
+	"errors"
+	"testing"
+)
+
+// -----------------------------------------------------------------------------
+
+type textPart string
+
+func (p textPart) AsBlob() (ret Blob, ok bool)             { return }
+func (p textPart) AsThinking() (ret Thinking, ok bool)     { return }
+func (p textPart) AsToolUse() (ret ToolUse, ok bool)       { return }
+func (p textPart) AsToolResult() (ret ToolResult, ok bool) { return }
+func (p textPart) AsCompaction() (ret Compaction, ok bool) { return }
+func (p textPart) Text() string                            { return string(p) }
+func (p textPart) Citations() []Citation                   { return nil }
+func (p textPart) Underlying() any                         { return nil }
+
+type textCandidate []textPart
+
+func (p textCandidate) Parts() int             { return len(p) }
+func (p textCandidate) Part(i int) Part        { return p[i] }
+func (p textCandidate) StopReason() StopReason { return EndTurn }
+func (p textCandidate) ToMsg() MsgBuilder      { return nil }
+
+// -----------------------------------------------------------------------------
+
+const personSchema = `{
+	"type": "object",
+	"properties": {
+		"name": {"type": "string", "minLength": 1},
+		"age": {"type": "integer", "minimum": 0},
+		"tags": {"type": "array", "items": {"$ref": "#/$defs/tag"}}
+	},
+	"required": ["name", "age"],
+	"additionalProperties": false,
+	"$defs": {
+		"tag": {"enum": ["a", "b"]}
+	}
+}`
+
+type person struct {
+	Name string   `json:"name"`
+	Age  int      `json:"age"`
+	Tags []string `json:"tags"`
+}
+
+func TestDecode(t *testing.T) {
+	format := MustJSONSchema("person", RawMessage(personSchema))
+	var ret person
+	c := textCandidate{"```json\n{\"name\": \"Ann\",", " \"age\": 30, \"tags\": [\"a\"]}\n```"}
+	if err := format.Decode(c, &ret); err != nil {
+		t.Fatal("Decode:", err)
+	}
+	if ret.Name != "Ann" || ret.Age != 30 || len(ret.Tags) != 1 {
+		t.Fatal("Decode: unexpected result", ret)
+	}
+}
+
+func TestValidateJSON(t *testing.T) {
+	if _, err := JSONSchema("bad", RawMessage(`[]`)); err == nil {
+		t.Fatal("JSONSchema: expected error of invalid schema")
+	}
+	format, err := JSONSchema("person", RawMessage(personSchema))
+	if err != nil {
+		t.Fatal("JSONSchema:", err)
+	}
+	cases := []struct {
+		doc   string
+		paths []string
+	}{
+		{`{"name": "Ann", "age": 30}`, nil},
+		{`{"name": "", "age": 1.5}`, []string{"/age", "/name"}},
+		{`{"age": 3, "tags": ["c"], "x": 1}`, []string{"", "/tags/0", ""}},
+		{`[]`, []string{""}},
+	}
+	for _, c := range cases {
+		err := ValidateJSON(format.Schema, []byte(c.doc))
+		var paths []string
+		if err != nil {
+			for _, e := range err.(interface{ Unwrap() []error }).Unwrap() {
+				var se *SchemaError
+				if !errors.As(e, &se) {
+					t.Fatalf("%s: unexpected error %v", c.doc, e)
+				}
+				paths = append(paths, se.Path)
+			}
+		}
+		if len(paths) != len(c.paths) {
+			t.Fatalf("%s: got %v, want %v (%v)", c.doc, paths, c.paths, err)
+		}
+		for i := range paths {
+			if paths[i] != c.paths[i] {
+				t.Fatalf("%s: got %v, want %v (%v)", c.doc, paths, c.paths, err)
+			}
+		}
+	}
+}
+
+// -----------------------------------------------------------------------------
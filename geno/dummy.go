@@ -33,10 +33,26 @@ func (p *Service[T]) Gen(ctx context.Context, params xai.GenParams) (xai.GenResp
 	panic("unsupported")
 }
 
-func (p *Service[T]) GenStream(ctx context.Context, params xai.GenParams) iter.Seq2[xai.GenResponse, error] {
+func (p *Service[T]) GenStream(ctx context.Context, params xai.GenParams) iter.Seq2[xai.StreamEvent, error] {
 	panic("unsupported")
 }
 
+func (p *Service[T]) CountTokens(ctx context.Context, params xai.GenParams) (int64, error) {
+	panic("unsupported")
+}
+
+func (p *Service[T]) Embed(ctx context.Context, params xai.EmbedParams) (*xai.Embeddings, error) {
+	return nil, xai.ErrNotFound
+}
+
+func (p *Service[T]) SubmitBatch(ctx context.Context, reqs []xai.GenParams) (xai.BatchResponse, error) {
+	return nil, xai.ErrNotFound
+}
+
+func (p *Service[T]) Files() xai.Files {
+	return nil
+}
+
 func (p *Service[T]) GenParams() xai.GenParams {
 	panic("unsupported")
 }
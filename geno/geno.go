@@ -21,6 +21,7 @@ import (
 	"encoding/json"
 	"errors"
 	"net/http"
+	"slices"
 	"time"
 	"unsafe"
 
@@ -81,6 +82,9 @@ type serviceAdapter interface {
 	// Actions returns the list of actions supported by the given model.
 	Actions(model xai.Model) []xai.Action
 
+	// Models returns the list of known models, as the APIs don't list them.
+	Models() []*xai.ModelInfo
+
 	// InputSchema returns the input schema for the given action.
 	InputSchema(action xai.Action) xai.InputSchema
 
@@ -111,8 +115,18 @@ func (p *Service[T]) Actions(model xai.Model) []xai.Action {
 	return adapter.Actions(model)
 }
 
+// implement xai.Service
+func (p *Service[T]) Models(ctx context.Context) ([]*xai.ModelInfo, error) {
+	var adapter T
+	return adapter.Models(), nil
+}
+
 // implement xai.Service
 func (p *Service[T]) Operation(model xai.Model, action xai.Action) (xai.Operation, error) {
+	var adapter T
+	if !slices.Contains(adapter.Actions(model), action) {
+		return nil, xai.ErrNotFound
+	}
 	return &Operation[T]{
 		c:      &p.c,
 		body:   make(map[string]any, 16),
@@ -150,6 +164,14 @@ func (p *CallParamsBase) Timeout(timeout time.Duration) xai.CallParams {
 	return p
 }
 
+func (p *CallParamsBase) Retry(policy xai.RetryPolicy) xai.CallParams {
+	if p.opts == nil {
+		p.opts = &HTTPOptions{}
+	}
+	p.opts.Retry(policy)
+	return p
+}
+
 func (p *CallParamsBase) Set(name string, val any) xai.CallParams {
 	panic("unreachable")
 }
@@ -23,7 +23,11 @@ import (
 	"io"
 	"net/http"
 	"net/url"
+	"strconv"
 	"time"
+
+	"github.com/goplus/xai"
+	"github.com/goplus/xai/util"
 )
 
 // -----------------------------------------------------------------------------
@@ -33,6 +37,7 @@ import (
 type Client struct {
 	client  http.Client
 	baseURL *url.URL
+	retry   xai.RetryPolicy
 }
 
 // NewClient creates a new Client instance with the given http.Client. If client is nil,
@@ -55,7 +60,7 @@ func NewClient(client *http.Client) *Client {
 	if client == nil {
 		client = http.DefaultClient
 	}
-	return &Client{client: *client}
+	return &Client{client: *client, retry: util.DefaultRetryPolicy}
 }
 
 // BaseURL sets the base URL for the client.
@@ -75,6 +80,13 @@ func (p *Client) Timeout(timeout time.Duration) *Client {
 	return p
 }
 
+// Retry sets the retry policy for requests failed with transient errors, e.g. rate
+// limits (429) and server errors (5xx). It defaults to util.DefaultRetryPolicy.
+func (p *Client) Retry(policy xai.RetryPolicy) *Client {
+	p.retry = policy
+	return p
+}
+
 // NewRequest creates a new Request with the given method and path. The path will
 // be resolved against the client's base URL if it is set.
 func (p *Client) NewRequest(method, path string) (*Request, error) {
@@ -93,6 +105,7 @@ func (p *Client) NewRequest(method, path string) (*Request, error) {
 type HTTPOptions struct {
 	baseURL *url.URL
 	timeout *time.Duration
+	retry   *xai.RetryPolicy
 }
 
 // BaseURL sets the base URL for the request. It will override the client's base
@@ -113,6 +126,13 @@ func (p *HTTPOptions) Timeout(timeout time.Duration) *HTTPOptions {
 	return p
 }
 
+// Retry sets the retry policy for the request. It will override the client's retry
+// policy if set.
+func (p *HTTPOptions) Retry(policy xai.RetryPolicy) *HTTPOptions {
+	p.retry = &policy
+	return p
+}
+
 // -----------------------------------------------------------------------------
 
 // Request represents an HTTP request to the Geno API. It provides methods for
@@ -140,12 +160,19 @@ func (p *Request) Json(v any) error {
 
 // Do sends the HTTP request and returns the response.
 // options can be nil, in which case the client's settings will be used.
+//
+// Requests failed with transient errors are retried according to the retry policy
+// of options or the client, and the timeout applies to each attempt.
+//
+// If the server responds with a non-2xx status code, Do consumes the response
+// body and returns an *xai.Error wrapping a *ResponseError.
 func (p *Request) Do(ctx context.Context, options *HTTPOptions) (*http.Response, error) {
 	var baseURL *url.URL
 	var timeout *time.Duration
 	if options != nil {
 		baseURL = options.baseURL
 		timeout = options.timeout
+		ctx = util.WithRetryPolicy(ctx, options.retry)
 	}
 	req := p.Request.WithContext(ctx)
 	client := &p.c.client
@@ -166,7 +193,42 @@ func (p *Request) Do(ctx context.Context, options *HTTPOptions) (*http.Response,
 			req.Host = req.URL.Host
 		}
 	}
-	return client.Do(req)
+	resp, err := util.DoWithRetry(req, p.c.retry, client.Do)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode/100 != 2 {
+		return nil, newResponseError(resp)
+	}
+	return resp, nil
+}
+
+// -----------------------------------------------------------------------------
+
+// ResponseError represents an HTTP response with a non-2xx status code.
+type ResponseError struct {
+	StatusCode int
+	Header     http.Header
+	Body       []byte // the response body, truncated to 64KB
+}
+
+func (e *ResponseError) Error() string {
+	msg := strconv.Itoa(e.StatusCode) + " " + http.StatusText(e.StatusCode)
+	if len(e.Body) > 0 {
+		msg += ": " + string(e.Body)
+	}
+	return msg
+}
+
+func newResponseError(resp *http.Response) error {
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+	err := &ResponseError{
+		StatusCode: resp.StatusCode,
+		Header:     resp.Header,
+		Body:       body,
+	}
+	return util.NewError(nil, resp.StatusCode, util.RetryAfter(resp.Header), err)
 }
 
 // -----------------------------------------------------------------------------
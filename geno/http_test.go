@@ -0,0 +1,114 @@
+/*
+ * Copyright (c) 2026 The XGo Authors (xgo.dev). All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package geno
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/goplus/xai"
+)
+
+// -----------------------------------------------------------------------------
+
+func TestResponseError(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "3")
+		w.WriteHeader(http.StatusTooManyRequests)
+		w.Write([]byte(`{"code":1302,"message":"too many requests"}`))
+	}))
+	defer ts.Close()
+
+	c := NewClient(nil).BaseURL(ts.URL).Retry(xai.RetryPolicy{})
+	req, err := c.NewRequest(http.MethodGet, "/v1/images/generations/1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = req.Do(context.Background(), nil)
+	if !errors.Is(err, xai.ErrRateLimited) {
+		t.Fatalf("errors.Is(ErrRateLimited) failed: %v", err)
+	}
+	if d, ok := xai.RetryAfter(err); !ok || d != 3*time.Second {
+		t.Fatalf("RetryAfter: got %v, %v", d, ok)
+	}
+	var re *ResponseError
+	if !errors.As(err, &re) || re.StatusCode != http.StatusTooManyRequests {
+		t.Fatalf("errors.As(*ResponseError) failed: %v", err)
+	}
+	if string(re.Body) != `{"code":1302,"message":"too many requests"}` {
+		t.Fatalf("unexpected body: %s", re.Body)
+	}
+}
+
+// -----------------------------------------------------------------------------
+
+func TestRetry(t *testing.T) {
+	var attempts int
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		body, _ := io.ReadAll(r.Body)
+		if string(body) != `{"prompt":"cat"}` {
+			t.Errorf("attempt %d: unexpected body: %s", attempts, body)
+		}
+		switch attempts {
+		case 1:
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusTooManyRequests)
+		case 2:
+			w.WriteHeader(http.StatusBadGateway)
+		default:
+			w.Write([]byte(`{}`))
+		}
+	}))
+	defer ts.Close()
+
+	c := NewClient(nil).BaseURL(ts.URL)
+	newReq := func() *Request {
+		req, err := c.NewRequest(http.MethodPost, "/v1/images/generations")
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Json(map[string]string{"prompt": "cat"})
+		return req
+	}
+	start := time.Now()
+	resp, err := newReq().Do(context.Background(), nil)
+	if err != nil {
+		t.Fatal("Do:", err)
+	}
+	resp.Body.Close()
+	if attempts != 3 {
+		t.Fatal("attempts:", attempts)
+	}
+	if d := time.Since(start); d < time.Second {
+		t.Fatal("Retry-After not honored:", d)
+	}
+
+	// Retry-After exceeds the max wait, so the request is not retried.
+	attempts = 0
+	opts := new(HTTPOptions).Retry(xai.RetryPolicy{MaxRetries: 5, MaxWait: time.Millisecond})
+	if _, err = newReq().Do(context.Background(), opts); !errors.Is(err, xai.ErrRateLimited) || attempts != 1 {
+		t.Fatalf("Do: attempts %d, err %v", attempts, err)
+	}
+}
+
+// -----------------------------------------------------------------------------
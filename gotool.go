@@ -0,0 +1,219 @@
+/*
+ * Copyright (c) 2026 The XGo Authors (xgo.dev). All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package xai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// -----------------------------------------------------------------------------
+
+// SchemaFor returns the JSON schema of the Go type T, which is usually a struct
+// describing the input of a tool. Struct fields are mapped as follows:
+//
+//   - The `json` tag defines the property name. Fields tagged with "-" and
+//     unexported fields are skipped, and anonymous struct fields are flattened.
+//   - A property is required unless the field is a pointer or its `json` tag
+//     has the `omitempty` or `omitzero` option.
+//   - The `desc` tag defines the description of the property.
+//   - The `enum` tag defines the allowed values, separated by commas.
+//
+// Objects derived from structs don't allow additional properties.
+func SchemaFor[T any]() map[string]any {
+	return schemaOf(reflect.TypeFor[T](), nil)
+}
+
+var (
+	typeTime       = reflect.TypeFor[time.Time]()
+	typeRawMessage = reflect.TypeFor[json.RawMessage]()
+)
+
+func schemaOf(t reflect.Type, visiting map[reflect.Type]bool) map[string]any {
+	switch t {
+	case typeTime:
+		return map[string]any{"type": "string", "format": "date-time"}
+	case typeRawMessage:
+		return map[string]any{}
+	}
+	switch t.Kind() {
+	case reflect.Pointer:
+		return schemaOf(t.Elem(), visiting)
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]any{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return map[string]any{"type": "string", "contentEncoding": "base64"}
+		}
+		return map[string]any{"type": "array", "items": schemaOf(t.Elem(), visiting)}
+	case reflect.Map:
+		if t.Key().Kind() != reflect.String {
+			panic("unsupported map key type: " + t.String())
+		}
+		return map[string]any{"type": "object", "additionalProperties": schemaOf(t.Elem(), visiting)}
+	case reflect.Struct:
+		if visiting[t] {
+			return map[string]any{} // recursive type, allow any value
+		}
+		if visiting == nil {
+			visiting = make(map[reflect.Type]bool)
+		}
+		visiting[t] = true
+		defer delete(visiting, t)
+
+		props := make(map[string]any)
+		required := make([]any, 0) // []any rather than []string, same as ParseSchema
+		structFields(t, visiting, props, &required)
+		return map[string]any{
+			"type":                 "object",
+			"properties":           props,
+			"required":             required,
+			"additionalProperties": false,
+		}
+	case reflect.Interface:
+		return map[string]any{}
+	}
+	panic("unsupported type: " + t.String())
+}
+
+func structFields(t reflect.Type, visiting map[reflect.Type]bool, props map[string]any, required *[]any) {
+	for i, n := 0, t.NumField(); i < n; i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+		if f.Anonymous && name == "" {
+			ft := f.Type
+			if ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				structFields(ft, visiting, props, required)
+				continue
+			}
+		}
+		if !f.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		prop := schemaOf(f.Type, visiting)
+		if desc := f.Tag.Get("desc"); desc != "" {
+			prop["description"] = desc
+		}
+		if enum := f.Tag.Get("enum"); enum != "" {
+			vals := strings.Split(enum, ",")
+			items := make([]any, len(vals))
+			for i, v := range vals {
+				items[i] = v
+			}
+			prop["enum"] = items
+		}
+		props[name] = prop
+		if f.Type.Kind() != reflect.Pointer && !strings.Contains(","+opts+",", ",omitempty,") &&
+			!strings.Contains(","+opts+",", ",omitzero,") {
+			*required = append(*required, name)
+		}
+	}
+}
+
+// -----------------------------------------------------------------------------
+
+// GoTool is a user-defined tool implemented by a Go function. It embeds the Tool
+// defined by `Service.ToolDef`, so it can be passed to `GenParams.Tools`
+// directly. Use `DefGoTool` to create it.
+type GoTool struct {
+	Tool
+
+	name   string
+	schema map[string]any
+	call   func(ctx context.Context, input []byte) (any, error)
+}
+
+// DefGoTool defines a tool named `name` with the Go function fn. The input schema
+// of the tool is derived from the type In by `SchemaFor`.
+func DefGoTool[In, Out any](svc Service, name, desc string, fn func(ctx context.Context, in In) (Out, error)) *GoTool {
+	schema := SchemaFor[In]()
+	tool := svc.ToolDef(name).Description(desc).InputSchema(schema)
+	return &GoTool{
+		Tool:   tool,
+		name:   name,
+		schema: schema,
+		call: func(ctx context.Context, input []byte) (any, error) {
+			var in In
+			if err := json.Unmarshal(input, &in); err != nil {
+				return nil, err
+			}
+			return fn(ctx, in)
+		},
+	}
+}
+
+// Name returns the name of the tool.
+func (p *GoTool) Name() string {
+	return p.name
+}
+
+// Call decodes the input of use, calls the Go function of the tool, and returns
+// the ToolResult to be sent back to the model. Invalid input and errors returned
+// by the Go function are reported as error results, so that the model can see
+// what went wrong and retry.
+func (p *GoTool) Call(ctx context.Context, use ToolUse) ToolResult {
+	ret := ToolResult{ID: use.ID, Name: use.Name}
+	out, err := p.invoke(ctx, use.Input)
+	if err != nil {
+		ret.Result, ret.IsError = err, true
+	} else {
+		ret.Result = out
+	}
+	return ret
+}
+
+func (p *GoTool) invoke(ctx context.Context, input any) (any, error) {
+	var b []byte
+	if v, ok := input.(RawMessage); ok {
+		b = v
+	} else {
+		var err error
+		if b, err = json.Marshal(input); err != nil {
+			return nil, fmt.Errorf("invalid tool input: %w", err)
+		}
+	}
+	if len(b) == 0 {
+		b = []byte("{}")
+	}
+	if err := ValidateJSON(p.schema, b); err != nil {
+		return nil, fmt.Errorf("invalid tool input: %w", err)
+	}
+	return p.call(ctx, b)
+}
+
+// -----------------------------------------------------------------------------
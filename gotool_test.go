@@ -0,0 +1,78 @@
+/*
+ * Copyright (c) 2026 The XGo Authors (xgo.dev). All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package xai
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// -----------------------------------------------------------------------------
+
+type weatherInput struct {
+	City  string  `json:"city" desc:"name of the city"`
+	Unit  string  `json:"unit,omitempty" enum:"celsius,fahrenheit"`
+	Days  *int    `json:"days"`
+	Debug bool    `json:"-"`
+	inner float64 // unexported fields are skipped
+}
+
+func TestSchemaFor(t *testing.T) {
+	schema := SchemaFor[weatherInput]()
+	want := ParseSchema(RawMessage(`{
+		"type": "object",
+		"properties": {
+			"city": {"type": "string", "description": "name of the city"},
+			"unit": {"type": "string", "enum": ["celsius", "fahrenheit"]},
+			"days": {"type": "integer"}
+		},
+		"required": ["city"],
+		"additionalProperties": false
+	}`))
+	got := ParseSchema(schema)
+	if !reflect.DeepEqual(got, want) {
+		b, _ := json.Marshal(schema)
+		t.Fatalf("SchemaFor: got %s", b)
+	}
+}
+
+func TestGoToolInvoke(t *testing.T) {
+	tool := &GoTool{
+		schema: SchemaFor[weatherInput](),
+		call: func(ctx context.Context, input []byte) (any, error) {
+			var in weatherInput
+			if err := json.Unmarshal(input, &in); err != nil {
+				return nil, err
+			}
+			return "sunny in " + in.City, nil
+		},
+	}
+	out, err := tool.invoke(context.Background(), RawMessage(`{"city": "Paris"}`))
+	if err != nil || out != "sunny in Paris" {
+		t.Fatal("invoke:", out, err)
+	}
+	_, err = tool.invoke(context.Background(), map[string]any{"unit": "kelvin"})
+	var se *SchemaError
+	if !errors.As(err, &se) {
+		t.Fatal("invoke: expected schema error, got", err)
+	}
+}
+
+// -----------------------------------------------------------------------------
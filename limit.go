@@ -0,0 +1,262 @@
+/*
+ * Copyright (c) 2026 The XGo Authors (xgo.dev). All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package xai
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// -----------------------------------------------------------------------------
+
+// Limits specifies the client-side limits of requests to a Service. Zero values
+// mean no limit.
+type Limits struct {
+	// RPM is the max number of requests per minute.
+	RPM int
+
+	// TPM is the max number of tokens (input and output) per minute. Tokens are
+	// counted by the usage of responses when the requests complete, so a request
+	// is admitted as long as the tokens of the last minute are under the limit.
+	TPM int64
+
+	// MaxConcurrency is the max number of concurrent requests. A GenStream
+	// request is in progress until its stream ends, and an `Operation.Call`
+	// request until its operation is done, i.e. its response is done or `Wait`
+	// on the response returns, as the task keeps running on the server, e.g. the
+	// tasks of kling. So wait for the operations to release their slots.
+	MaxConcurrency int
+}
+
+// IsZero reports whether l has no limit.
+func (l Limits) IsZero() bool {
+	return l == Limits{}
+}
+
+// ParseLimits parses the `rpm`, `tpm` and `max_concurrency` parameters of a
+// service URI.
+func ParseLimits(params url.Values) (ret Limits, err error) {
+	if rpm := params["rpm"]; len(rpm) > 0 {
+		if ret.RPM, err = strconv.Atoi(rpm[0]); err != nil {
+			return
+		}
+	}
+	if tpm := params["tpm"]; len(tpm) > 0 {
+		if ret.TPM, err = strconv.ParseInt(tpm[0], 10, 64); err != nil {
+			return
+		}
+	}
+	if n := params["max_concurrency"]; len(n) > 0 {
+		if ret.MaxConcurrency, err = strconv.Atoi(n[0]); err != nil {
+			return
+		}
+	}
+	return
+}
+
+// ErrLimitExceeded is the underlying error of an *Error with kind ErrRateLimited,
+// returned when a request can't be admitted by a Limiter before the deadline of
+// its context.
+var ErrLimitExceeded = errors.New("client-side limit exceeded")
+
+// Limit returns a Service that applies the limits to `Gen`, `GenStream` and
+// `Operation.Call` of svc. It returns svc itself if limits is zero.
+//
+// Use `NewLimiter` and `Wrap` instead to share the limits across services, e.g.
+// services using the same account.
+func Limit(svc Service, limits Limits) Service {
+	if limits.IsZero() {
+		return svc
+	}
+	return Wrap(svc, NewLimiter(limits).Middleware())
+}
+
+// -----------------------------------------------------------------------------
+
+const limitWindow = time.Minute
+
+type tokenRecord struct {
+	at     time.Time
+	tokens int64
+}
+
+// Limiter limits the rate and concurrency of requests. It is safe for concurrent
+// use.
+type Limiter struct {
+	limits Limits
+	sem    chan struct{}
+
+	mu       sync.Mutex
+	requests []time.Time   // start times of requests in the last minute
+	tokens   []tokenRecord // tokens of requests completed in the last minute
+	used     int64         // sum of tokens
+}
+
+// NewLimiter creates a Limiter with the given limits.
+func NewLimiter(limits Limits) *Limiter {
+	ret := &Limiter{limits: limits}
+	if limits.MaxConcurrency > 0 {
+		ret.sem = make(chan struct{}, limits.MaxConcurrency)
+	}
+	return ret
+}
+
+// Acquire blocks until a request is admitted, and returns a function to release
+// it with the token usage of the request.
+//
+// If ctx is done before that, it returns ctx.Err(). If ctx has a deadline that
+// would expire before the rate limits allow the request, it fails fast with an
+// *Error of kind ErrRateLimited, whose RetryAfter is the time to wait.
+func (p *Limiter) Acquire(ctx context.Context) (release func(usage Usage), err error) {
+	if p.sem != nil {
+		select {
+		case p.sem <- struct{}{}:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	for {
+		wait := p.reserve(time.Now())
+		if wait <= 0 {
+			break
+		}
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < wait {
+			p.done()
+			return nil, &Error{Kind: ErrRateLimited, Err: ErrLimitExceeded, RetryAfter: wait}
+		}
+		timer := time.NewTimer(wait)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			p.done()
+			return nil, ctx.Err()
+		}
+	}
+	var once sync.Once
+	return func(usage Usage) {
+		once.Do(func() {
+			p.record(time.Now(), usage.InputTokens+usage.OutputTokens)
+			p.done()
+		})
+	}, nil
+}
+
+func (p *Limiter) done() {
+	if p.sem != nil {
+		<-p.sem
+	}
+}
+
+// reserve records a request started at now if the rate limits allow it, or
+// returns the time to wait otherwise.
+func (p *Limiter) reserve(now time.Time) time.Duration {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.expire(now)
+	var wait time.Duration
+	if rpm := p.limits.RPM; rpm > 0 && len(p.requests) >= rpm {
+		wait = p.requests[len(p.requests)-rpm].Add(limitWindow).Sub(now)
+	}
+	if tpm := p.limits.TPM; tpm > 0 && p.used >= tpm {
+		// wait until enough records expire to bring the usage under the limit
+		used := p.used
+		for _, r := range p.tokens {
+			if used -= r.tokens; used < tpm {
+				wait = max(wait, r.at.Add(limitWindow).Sub(now))
+				break
+			}
+		}
+	}
+	if wait <= 0 && p.limits.RPM > 0 {
+		p.requests = append(p.requests, now)
+	}
+	return wait
+}
+
+func (p *Limiter) record(now time.Time, tokens int64) {
+	if p.limits.TPM <= 0 || tokens <= 0 {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.tokens = append(p.tokens, tokenRecord{now, tokens})
+	p.used += tokens
+}
+
+func (p *Limiter) expire(now time.Time) {
+	since := now.Add(-limitWindow)
+	i := 0
+	for i < len(p.requests) && !p.requests[i].After(since) {
+		i++
+	}
+	p.requests = p.requests[i:]
+	i = 0
+	for i < len(p.tokens) && !p.tokens[i].at.After(since) {
+		p.used -= p.tokens[i].tokens
+		i++
+	}
+	p.tokens = p.tokens[i:]
+}
+
+type limiterKey struct{ *Limiter }
+
+// Middleware returns a Middleware that applies the limiter to the calls. Tokens
+// of `Gen` and `GenStream` calls are counted by the usage of their responses.
+func (p *Limiter) Middleware() Middleware {
+	return Middleware{
+		Before: func(ctx context.Context, call *Call) (context.Context, error) {
+			release, err := p.Acquire(ctx)
+			if err != nil {
+				return ctx, err
+			}
+			return context.WithValue(ctx, limiterKey{p}, release), nil
+		},
+		After: func(ctx context.Context, call *Call) {
+			release, ok := ctx.Value(limiterKey{p}).(func(Usage))
+			if !ok {
+				return
+			}
+			if resp := call.OpResponse; call.Err == nil && resp != nil && !resp.Done() {
+				call.OpResponse = &limitedOpResponse{resp, release}
+				return
+			}
+			var usage Usage
+			if call.Response != nil {
+				usage = call.Response.Usage()
+			}
+			release(usage)
+		},
+	}
+}
+
+// limitedOpResponse holds the request slot of an operation until Wait returns.
+type limitedOpResponse struct {
+	OperationResponse
+	release func(Usage)
+}
+
+func (p *limitedOpResponse) Wait(ctx context.Context, params WaitParams) (Results, error) {
+	defer p.release(Usage{})
+	return p.OperationResponse.Wait(ctx, params)
+}
+
+// -----------------------------------------------------------------------------
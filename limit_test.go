@@ -0,0 +1,148 @@
+/*
+ * Copyright (c) 2026 The XGo Authors (xgo.dev). All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package xai
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"testing"
+	"time"
+)
+
+// -----------------------------------------------------------------------------
+
+type usageResp struct{ echoResp }
+
+func (p usageResp) Usage() Usage { return Usage{InputTokens: 60, OutputTokens: 40} }
+
+type usageService struct{ echoService }
+
+func (p usageService) Gen(ctx context.Context, params GenParams) (GenResponse, error) {
+	resp, err := p.echoService.Gen(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+	return usageResp{resp.(echoResp)}, nil
+}
+
+func TestLimit(t *testing.T) {
+	limits, err := ParseLimits(url.Values{"rpm": {"3"}, "tpm": {"150"}, "max_concurrency": {"1"}})
+	if err != nil || limits != (Limits{RPM: 3, TPM: 150, MaxConcurrency: 1}) {
+		t.Fatal("ParseLimits:", limits, err)
+	}
+	gen := func(svc Service) error {
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+		_, err := svc.Gen(ctx, svc.GenParams().Messages(svc.UserMsg().Text("hi")))
+		return err
+	}
+
+	// the third request exceeds the tpm limit, as the first two used 200 tokens
+	svc := Limit(usageService{}, limits)
+	if err = gen(svc); err != nil {
+		t.Fatal("Gen:", err)
+	}
+	if err = gen(svc); err != nil {
+		t.Fatal("Gen:", err)
+	}
+	err = gen(svc)
+	if d, ok := RetryAfter(err); !errors.Is(err, ErrRateLimited) || !ok || d < 50*time.Second {
+		t.Fatal("Gen: expected rate limited, got", err, d)
+	}
+
+	// the request waits for the concurrency slot until the context is done
+	limiter := NewLimiter(Limits{MaxConcurrency: 1})
+	release, err := limiter.Acquire(context.Background())
+	if err != nil {
+		t.Fatal("Acquire:", err)
+	}
+	if err = gen(Wrap(echoService{}, limiter.Middleware())); err != context.DeadlineExceeded {
+		t.Fatal("Gen: expected DeadlineExceeded, got", err)
+	}
+	release(Usage{})
+	if err = gen(Wrap(echoService{}, limiter.Middleware())); err != nil {
+		t.Fatal("Gen:", err)
+	}
+
+	// After doesn't require the release of Before
+	limiter.Middleware().After(context.Background(), &Call{})
+}
+
+func TestNewLimits(t *testing.T) {
+	newEcho := func(ctx context.Context, uri string) (Service, error) { return echoService{}, nil }
+	Register("limit-echo", newEcho)
+	RegisterOpaque("limit-opaque", newEcho)
+	ctx := context.Background()
+	if svc, err := New(ctx, "limit-echo:rpm=60"); err != nil || svc == (echoService{}) {
+		t.Fatal("New: expected a limited service, got", svc, err)
+	}
+	if _, err := New(ctx, "limit-echo:rpm=x"); err == nil {
+		t.Fatal("New: expected an invalid rpm")
+	}
+	// the URIs of opaque schemes are passed as is
+	svc, err := New(ctx, `limit-opaque:{"uri":"x:rpm=60&max_concurrency=1","y":"%zz"}`)
+	if err != nil || svc != (echoService{}) {
+		t.Fatal("New: expected the service as is, got", svc, err)
+	}
+}
+
+// taskService starts tasks that are done once waited.
+type taskService struct{ echoService }
+
+type taskOp struct{ Operation }
+
+type taskResp struct{ OperationResponse }
+
+func (taskService) Operation(model Model, action Action) (Operation, error) { return taskOp{}, nil }
+
+func (taskOp) Call(ctx context.Context, params CallParams) (OperationResponse, error) {
+	return taskResp{}, nil
+}
+
+func (taskResp) Done() bool { return false }
+
+func (taskResp) Wait(ctx context.Context, params WaitParams) (Results, error) { return nil, nil }
+
+func TestLimitTasks(t *testing.T) {
+	svc := Limit(taskService{}, Limits{MaxConcurrency: 1})
+	op, err := svc.Operation("m", GenVideo)
+	if err != nil {
+		t.Fatal("Operation:", err)
+	}
+	call := func() (OperationResponse, error) {
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+		return op.Call(ctx, nil)
+	}
+	resp, err := call()
+	if err != nil {
+		t.Fatal("Call:", err)
+	}
+	// the task holds the slot until it is waited
+	if _, err = call(); err != context.DeadlineExceeded {
+		t.Fatal("Call: expected DeadlineExceeded, got", err)
+	}
+	if _, err = resp.Wait(context.Background(), nil); err != nil {
+		t.Fatal("Wait:", err)
+	}
+	if _, err = call(); err != nil {
+		t.Fatal("Call:", err)
+	}
+}
+
+// -----------------------------------------------------------------------------
@@ -19,6 +19,7 @@ package xai
 import (
 	"encoding/json"
 	"io"
+	"time"
 )
 
 // -----------------------------------------------------------------------------
@@ -57,6 +58,13 @@ type MsgBuilder interface {
 	DocURL(mime DocumentType, url string) MsgBuilder
 	DocFile(mime DocumentType, fileID string) MsgBuilder
 
+	// DocCitations enables citations of the document added last by Doc, DocURL
+	// or DocFile, so that the text parts of the response that are based on it
+	// return their sources by `Part.Citations`. It has no effect if the last
+	// content isn't a document, or the provider doesn't cite documents (only
+	// Claude does for now).
+	DocCitations() MsgBuilder
+
 	// Part is used to add a part of the GenResponse message to the content.
 	Part(Part) MsgBuilder
 
@@ -90,6 +98,19 @@ type MsgBuilder interface {
 	// is an opaque string that is passed back by the provider when the compaction
 	// is triggered. The format of the content is provider-specific.
 	Compaction(data string) MsgBuilder
+
+	// CacheBreakpoint marks the content added so far as a prompt cache breakpoint.
+	// The prompt up to the breakpoint, i.e. the tools, the system prompt and the
+	// messages before, is cached for ttl (or the default TTL of the provider if
+	// ttl is zero), so that later requests with the same prefix read it from the
+	// cache at a lower price. Cache hits are reported by `Usage.CacheReadTokens`.
+	//
+	// Claude caches the prompt by cache_control, whose TTL is 5 minutes or an
+	// hour. Gemini creates a CachedContent of the prompt up to the message of the
+	// last breakpoint, except the last message, and reuses it until it expires.
+	// OpenAI caches prompts automatically, and keeps them for 24 hours if ttl
+	// exceeds an hour.
+	CacheBreakpoint(ttl time.Duration) MsgBuilder
 }
 
 type RawMessage = json.RawMessage
@@ -149,6 +170,26 @@ type Compaction struct {
 	Data string
 }
 
+// Citation is a source cited by a text part of a response, e.g. a document of the
+// request or a web page found by a search.
+type Citation struct {
+	// Start and End are the byte offsets of the span of the text of the part that
+	// is supported by the source.
+	Start, End int
+
+	// CitedText is the text of the source that is cited, if available.
+	CitedText string
+
+	// URL and Title of the source, if available.
+	URL, Title string
+
+	// DocIndex is the index of the cited document among the documents of the
+	// request, or -1 if the source isn't a document of the request.
+	DocIndex int
+
+	Underlying any // for provider-specific extensions
+}
+
 type Part interface {
 	AsBlob() (ret Blob, ok bool)
 	AsThinking() (ret Thinking, ok bool)
@@ -156,6 +197,12 @@ type Part interface {
 	AsToolResult() (ret ToolResult, ok bool)
 	AsCompaction() (ret Compaction, ok bool)
 	Text() string
+
+	// Citations returns the sources cited by the text of the part, e.g. Claude
+	// document and search citations, OpenAI url_citation annotations or Gemini
+	// grounding metadata, or nil if there are none.
+	Citations() []Citation
+
 	Underlying() any
 }
 
@@ -0,0 +1,203 @@
+/*
+ * Copyright (c) 2026 The XGo Authors (xgo.dev). All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package xai
+
+import (
+	"context"
+	"iter"
+	"time"
+)
+
+// -----------------------------------------------------------------------------
+
+// CallKind represents the kind of a call intercepted by Middleware.
+type CallKind int
+
+const (
+	CallGen       CallKind = iota // Service.Gen
+	CallGenStream                 // Service.GenStream
+	CallOperation                 // Operation.Call
+)
+
+// Call describes a call intercepted by Middleware.
+type Call struct {
+	Kind CallKind
+
+	// Service is the wrapped service, i.e. the one that serves the call.
+	Service Service
+
+	// Params is the parameters of CallGen and CallGenStream calls.
+	Params GenParams
+
+	// Model, Action and CallParams are the parameters of CallOperation calls.
+	Model      Model
+	Action     Action
+	CallParams CallParams
+
+	// Start is the time when the call starts, after the Before hooks.
+	Start time.Time
+
+	// Duration is the duration of the call. It is set for After hooks.
+	Duration time.Duration
+
+	// Response is the response of CallGen calls, or the complete response of
+	// CallGenStream calls, set for After hooks. It is nil if the call fails, or
+	// the stream is stopped early by the consumer.
+	Response GenResponse
+
+	// OpResponse is the response of CallOperation calls, set for After hooks.
+	OpResponse OperationResponse
+
+	// Err is the error of the call, set for After hooks.
+	Err error
+}
+
+// Middleware intercepts calls to a Service. Use `Wrap` to apply it. Both hooks are
+// optional.
+type Middleware struct {
+	// Before is called before the call, and may modify its parameters, e.g. for
+	// redaction. If it returns an error, e.g. for a policy violation, the call
+	// is aborted with the error. The returned context is used for the call.
+	Before func(ctx context.Context, call *Call) (context.Context, error)
+
+	// After is called after the call, and may replace its Response, OpResponse
+	// or Err, which are then returned to the caller. For CallGenStream calls, it
+	// is called when the stream ends, and replacing Response has no effect, as
+	// the events are already yielded. Err is yielded as the last error of the
+	// stream, unless the caller stops iterating before the stream ends.
+	After func(ctx context.Context, call *Call)
+}
+
+// Wrap returns a Service that forwards all methods to svc, and applies the
+// middlewares to `Gen`, `GenStream` and `Operation.Call`. Before hooks are called
+// in order, and After hooks are called in reverse order, so the first middleware
+// is the outermost one.
+func Wrap(svc Service, mws ...Middleware) Service {
+	if len(mws) == 0 {
+		return svc
+	}
+	return &wrapService{Service: svc, mws: mws}
+}
+
+type wrapService struct {
+	Service
+	mws []Middleware
+}
+
+func (p *wrapService) Unwrap() Service {
+	return p.Service
+}
+
+// before calls the Before hooks, and returns the number of middlewares whose
+// After hooks should be called.
+func (p *wrapService) before(ctx context.Context, call *Call) (context.Context, int, error) {
+	for i, mw := range p.mws {
+		if mw.Before != nil {
+			// keep ctx on error, as the After hooks of the previous middlewares
+			// are called with it.
+			next, err := mw.Before(ctx, call)
+			if err != nil {
+				return ctx, i, err
+			}
+			ctx = next
+		}
+	}
+	call.Start = time.Now()
+	return ctx, len(p.mws), nil
+}
+
+func (p *wrapService) after(ctx context.Context, call *Call, n int) {
+	if !call.Start.IsZero() {
+		call.Duration = time.Since(call.Start)
+	}
+	for i := n - 1; i >= 0; i-- {
+		if after := p.mws[i].After; after != nil {
+			after(ctx, call)
+		}
+	}
+}
+
+func (p *wrapService) Gen(ctx context.Context, params GenParams) (GenResponse, error) {
+	call := &Call{Kind: CallGen, Service: p.Service, Params: params}
+	ctx, n, err := p.before(ctx, call)
+	if err == nil {
+		call.Response, call.Err = p.Service.Gen(ctx, call.Params)
+	} else {
+		call.Err = err
+	}
+	p.after(ctx, call, n)
+	return call.Response, call.Err
+}
+
+func (p *wrapService) GenStream(ctx context.Context, params GenParams) iter.Seq2[StreamEvent, error] {
+	return func(yield func(StreamEvent, error) bool) {
+		call := &Call{Kind: CallGenStream, Service: p.Service, Params: params}
+		ctx, n, err := p.before(ctx, call)
+		if err != nil {
+			call.Err = err
+		} else {
+			// errors end the stream, and are yielded after the After hooks,
+			// which may replace them.
+			for ev, err := range p.Service.GenStream(ctx, call.Params) {
+				if err != nil {
+					call.Err = err
+					break
+				}
+				if ev.Type == EventStop {
+					call.Response = ev.Response
+				}
+				if !yield(ev, nil) {
+					p.after(ctx, call, n)
+					return
+				}
+			}
+		}
+		p.after(ctx, call, n)
+		if call.Err != nil {
+			yield(StreamEvent{}, call.Err)
+		}
+	}
+}
+
+func (p *wrapService) Operation(model Model, action Action) (Operation, error) {
+	op, err := p.Service.Operation(model, action)
+	if err != nil {
+		return nil, err
+	}
+	return &wrapOperation{Operation: op, svc: p, model: model, action: action}, nil
+}
+
+type wrapOperation struct {
+	Operation
+	svc    *wrapService
+	model  Model
+	action Action
+}
+
+func (p *wrapOperation) Call(ctx context.Context, params CallParams) (OperationResponse, error) {
+	call := &Call{Kind: CallOperation, Service: p.svc.Service, Model: p.model, Action: p.action, CallParams: params}
+	ctx, n, err := p.svc.before(ctx, call)
+	if err == nil {
+		call.OpResponse, call.Err = p.Operation.Call(ctx, call.CallParams)
+	} else {
+		call.Err = err
+	}
+	p.svc.after(ctx, call, n)
+	return call.OpResponse, call.Err
+}
+
+// -----------------------------------------------------------------------------
@@ -0,0 +1,113 @@
+/*
+ * Copyright (c) 2026 The XGo Authors (xgo.dev). All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package xai
+
+import (
+	"context"
+	"errors"
+	"iter"
+	"strings"
+	"testing"
+)
+
+// -----------------------------------------------------------------------------
+
+func TestWrap(t *testing.T) {
+	var trace []string
+	logger := func(name string) Middleware {
+		return Middleware{
+			Before: func(ctx context.Context, call *Call) (context.Context, error) {
+				trace = append(trace, name+".before")
+				return ctx, nil
+			},
+			After: func(ctx context.Context, call *Call) {
+				trace = append(trace, name+".after")
+				if ctx == nil {
+					t.Errorf("%s: nil context", name)
+				}
+				if call.Err == nil && call.Response == nil {
+					t.Errorf("%s: no response", name)
+				}
+			},
+		}
+	}
+	errDenied := errors.New("denied")
+	deny := false
+	policy := Middleware{
+		Before: func(ctx context.Context, call *Call) (context.Context, error) {
+			if deny {
+				return nil, errDenied
+			}
+			return ctx, nil
+		},
+	}
+
+	svc := Wrap(echoService{}, logger("a"), policy, logger("b"))
+	params := svc.GenParams().Messages(svc.UserMsg().Text("hi"))
+	if _, err := svc.Gen(context.Background(), params); err != nil {
+		t.Fatal("Gen:", err)
+	}
+	if got := strings.Join(trace, " "); got != "a.before b.before b.after a.after" {
+		t.Fatal("trace:", got)
+	}
+
+	trace, deny = nil, true
+	if _, err := svc.Gen(context.Background(), params); err != errDenied {
+		t.Fatal("Gen: expected errDenied, got", err)
+	}
+	if got := strings.Join(trace, " "); got != "a.before a.after" {
+		t.Fatal("trace:", got)
+	}
+}
+
+// streamService streams an error.
+type streamService struct {
+	echoService
+}
+
+func (p streamService) GenStream(ctx context.Context, params GenParams) iter.Seq2[StreamEvent, error] {
+	return func(yield func(StreamEvent, error) bool) {
+		if yield(StreamEvent{Type: EventStart}, nil) {
+			yield(StreamEvent{}, errors.New("overloaded"))
+		}
+	}
+}
+
+func TestWrapStreamErr(t *testing.T) {
+	errRetry := errors.New("retry later")
+	svc := Wrap(streamService{}, Middleware{
+		After: func(ctx context.Context, call *Call) {
+			if call.Err != nil {
+				call.Err = errRetry
+			}
+		},
+	})
+	var events int
+	var last error
+	for _, err := range svc.GenStream(context.Background(), svc.GenParams()) {
+		if err != nil {
+			last = err
+		} else {
+			events++
+		}
+	}
+	if events != 1 || last != errRetry {
+		t.Fatal("GenStream:", events, last)
+	}
+}
+
+// -----------------------------------------------------------------------------
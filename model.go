@@ -16,9 +16,77 @@
 
 package xai
 
+import (
+	"context"
+	"fmt"
+	"slices"
+)
+
 // -----------------------------------------------------------------------------
 
 // The model that will complete your prompt.
 type Model string
 
 // -----------------------------------------------------------------------------
+
+// Modality is a kind of content that models accept or produce.
+type Modality string
+
+const (
+	ModalityText     Modality = "text"
+	ModalityImage    Modality = "image"
+	ModalityDocument Modality = "document"
+	ModalityAudio    Modality = "audio"
+	ModalityVideo    Modality = "video"
+)
+
+// ModelInfo describes a model and its capabilities, as listed by `Service.Models`.
+// Zero values mean unknown, or unsupported for the bool fields.
+type ModelInfo struct {
+	ID          Model
+	DisplayName string
+
+	// ContextWindow is the max number of input tokens, and MaxOutputTokens is the
+	// max number of output tokens of a request.
+	ContextWindow   int64
+	MaxOutputTokens int64
+
+	InputModalities  []Modality
+	OutputModalities []Modality
+
+	// Tools, Thinking and StructuredOutput report whether the model supports tool
+	// use, thinking and `ResponseFormat` of GenParams.
+	Tools            bool
+	Thinking         bool
+	StructuredOutput bool
+
+	// Actions are the operations available for the model, see `Service.Actions`.
+	Actions []Action
+}
+
+// Accepts reports whether the model accepts inputs of modality m.
+func (p *ModelInfo) Accepts(m Modality) bool {
+	return slices.Contains(p.InputModalities, m)
+}
+
+// Produces reports whether the model produces outputs of modality m.
+func (p *ModelInfo) Produces(m Modality) bool {
+	return slices.Contains(p.OutputModalities, m)
+}
+
+// FindModel returns the info of model listed by `svc.Models`, or an error that
+// wraps ErrNotFound if it isn't listed.
+func FindModel(ctx context.Context, svc Service, model Model) (*ModelInfo, error) {
+	models, err := svc.Models(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, m := range models {
+		if m.ID == model {
+			return m, nil
+		}
+	}
+	return nil, fmt.Errorf("model %q: %w", model, ErrNotFound)
+}
+
+// -----------------------------------------------------------------------------
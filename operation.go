@@ -50,7 +50,8 @@ type Results interface {
 	// At retrieves a generated image or video from the results by index.
 	// For GenVideo, returns *OutputVideo;
 	// For SegmentImage, returns *OutputImageMask;
-	// For GenImage, EditImage, RecontextImage, UpscaleImage, returns *OutputImage.
+	// For GenImage, EditImage, RecontextImage, UpscaleImage, returns *OutputImage;
+	// For batches of SubmitBatch, returns *BatchResult.
 	At(i int) Generated
 }
 
@@ -101,6 +102,9 @@ type CallParams interface {
 	// Timeout sets a timeout for the API request. If the request takes longer than
 	// the specified duration, it will be aborted and an error will be returned.
 	Timeout(time.Duration) CallParams
+
+	// Retry overrides the retry policy of the service for this request.
+	Retry(RetryPolicy) CallParams
 }
 
 // Operation represents a long-running task that may take some time to complete, such as
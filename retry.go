@@ -0,0 +1,44 @@
+/*
+ * Copyright (c) 2026 The XGo Authors (xgo.dev). All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package xai
+
+import (
+	"time"
+)
+
+// -----------------------------------------------------------------------------
+
+// RetryPolicy specifies how requests failed with transient errors are retried,
+// e.g. rate limits (429), server errors (5xx) and network errors. Retries use
+// exponential backoff with jitter, and respect the Retry-After header of the
+// response.
+//
+// The default policy of a Service is set by the `retries` and `retry_max_wait`
+// parameters of its URI, and can be overridden per request by `GenParams.Retry`
+// and `CallParams.Retry`.
+type RetryPolicy struct {
+	// MaxRetries is the maximum number of retries after the first attempt.
+	// Zero disables retries.
+	MaxRetries int
+
+	// MaxWait is the maximum time to wait before a retry. If the server asks
+	// to wait longer by Retry-After, the request is not retried. Zero means
+	// the default (1 minute).
+	MaxWait time.Duration
+}
+
+// -----------------------------------------------------------------------------
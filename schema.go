@@ -228,6 +228,8 @@ type GenVideoMask any
 //     RecontextImage, UpscaleImage actions.
 //   - OutputImageMask: represents a generated image mask with detected entity labels,
 //     which is returned by SegmentImage action.
+//   - BatchResult: represents the result of a request of a batch, which is returned
+//     by SubmitBatch.
 type Generated interface {
 	generated()
 }
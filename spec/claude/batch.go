@@ -0,0 +1,129 @@
+/*
+ * Copyright (c) 2026 The XGo Authors (xgo.dev). All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package claude
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"time"
+
+	"github.com/anthropics/anthropic-sdk-go"
+	"github.com/anthropics/anthropic-sdk-go/option"
+	"github.com/anthropics/anthropic-sdk-go/packages/param"
+	"github.com/goplus/xai"
+	"github.com/goplus/xai/util"
+)
+
+// -----------------------------------------------------------------------------
+
+// SubmitBatch submits reqs as a message batch. The batch is submitted by the
+// context and request options of the first request, with the betas of all the
+// requests. See https://docs.claude.com/en/docs/build-with-claude/batch-processing.
+func (p *Service) SubmitBatch(ctx context.Context, reqs []xai.GenParams) (xai.BatchResponse, error) {
+	if len(reqs) == 0 {
+		return nil, errors.New("claude: empty batch")
+	}
+	ctx, _, opts := buildParams(ctx, reqs[0])
+	params := anthropic.BetaMessageBatchNewParams{
+		Requests: make([]anthropic.BetaMessageBatchNewParamsRequest, len(reqs)),
+	}
+	for i, gp := range reqs {
+		_, in, _ := buildParams(ctx, gp)
+		if err := checkParams(&in); err != nil {
+			return nil, err
+		}
+		for _, beta := range in.Betas {
+			if !slices.Contains(params.Betas, beta) {
+				params.Betas = append(params.Betas, beta)
+			}
+		}
+		params.Requests[i] = anthropic.BetaMessageBatchNewParamsRequest{
+			CustomID: util.CustomID(i),
+			Params:   param.Override[anthropic.BetaMessageBatchNewParamsRequestParams](in),
+		}
+	}
+	resp, err := p.batches.New(ctx, params, opts...)
+	if err != nil {
+		return nil, translateError(err)
+	}
+	poller := &batchPoller{p.batches, resp.ID, params.Betas, len(reqs), opts}
+	return util.NewBatch(poller.status(resp), poller, batchInterval), nil
+}
+
+const batchInterval = 30 * time.Second
+
+type batchPoller struct {
+	svc   anthropic.BetaMessageBatchService
+	id    string
+	betas []anthropic.AnthropicBeta
+	n     int
+	opts  []option.RequestOption
+}
+
+func (p *batchPoller) status(resp *anthropic.BetaMessageBatch) util.BatchStatus {
+	counts := resp.RequestCounts
+	return util.BatchStatus{
+		ID:            resp.ID,
+		Ended:         resp.ProcessingStatus == anthropic.BetaMessageBatchProcessingStatusEnded,
+		EndedRequests: int(counts.Succeeded + counts.Errored + counts.Canceled + counts.Expired),
+		Requests:      p.n,
+	}
+}
+
+func (p *batchPoller) Poll(ctx context.Context, wo *util.WaitOptions) (util.BatchStatus, []*xai.BatchResult, error) {
+	opts := p.opts
+	if wo.BaseURL != "" {
+		opts = append(slices.Clip(opts), option.WithBaseURL(wo.BaseURL))
+	}
+	resp, err := p.svc.Get(ctx, p.id, anthropic.BetaMessageBatchGetParams{Betas: p.betas}, opts...)
+	if err != nil {
+		return util.BatchStatus{}, nil, translateError(err)
+	}
+	status := p.status(resp)
+	if !status.Ended {
+		return status, nil, nil
+	}
+	var results []*xai.BatchResult
+	stream := p.svc.ResultsStreaming(ctx, p.id, anthropic.BetaMessageBatchResultsParams{Betas: p.betas}, opts...)
+	defer stream.Close()
+	for stream.Next() {
+		results = append(results, batchResult(stream.Current()))
+	}
+	if err = stream.Err(); err != nil {
+		return util.BatchStatus{}, nil, translateError(err)
+	}
+	return status, util.BatchResults(results, p.n, errBatchEnded), nil
+}
+
+var errBatchEnded = errors.New("claude: no result of the request in the batch")
+
+func batchResult(resp anthropic.BetaMessageBatchIndividualResponse) *xai.BatchResult {
+	ret := &xai.BatchResult{CustomID: resp.CustomID}
+	switch r := resp.Result; r.Type {
+	case "succeeded":
+		ret.Response = response{&r.Message}
+	case "errored":
+		e := r.Error.Error
+		ret.Err = util.NewError(errorKinds[e.Type], 0, 0, errors.New(e.Message))
+	default: // canceled or expired
+		ret.Err = errors.New("claude: request " + r.Type)
+	}
+	return ret
+}
+
+// -----------------------------------------------------------------------------
@@ -0,0 +1,56 @@
+/*
+ * Copyright (c) 2026 The XGo Authors (xgo.dev). All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package claude
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/anthropics/anthropic-sdk-go"
+	"github.com/goplus/xai"
+)
+
+// -----------------------------------------------------------------------------
+
+// MarshalGenParams implements xai.GenCodec.
+func (p *Service) MarshalGenParams(gp xai.GenParams) ([]byte, error) {
+	_, params, _ := buildParams(context.Background(), gp)
+	return json.Marshal(params)
+}
+
+// MarshalGenResponse implements xai.GenCodec.
+func (p *Service) MarshalGenResponse(resp xai.GenResponse) ([]byte, error) {
+	r, ok := resp.(response)
+	if !ok {
+		return nil, fmt.Errorf("claude: unexpected response type %T", resp)
+	}
+	if raw := r.msg.RawJSON(); raw != "" {
+		return []byte(raw), nil
+	}
+	return json.Marshal(r.msg)
+}
+
+// UnmarshalGenResponse implements xai.GenCodec.
+func (p *Service) UnmarshalGenResponse(data []byte) (xai.GenResponse, error) {
+	msg := new(anthropic.BetaMessage)
+	if err := msg.UnmarshalJSON(data); err != nil {
+		return nil, err
+	}
+	return response{msg}, nil
+}
+
+// -----------------------------------------------------------------------------
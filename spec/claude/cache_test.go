@@ -0,0 +1,77 @@
+/*
+ * Copyright (c) 2026 The XGo Authors (xgo.dev). All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package claude
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/goplus/xai"
+)
+
+// -----------------------------------------------------------------------------
+
+func TestGenCodec(t *testing.T) {
+	svc := newService(t)
+	newParams := func() xai.GenParams {
+		return svc.GenParams().Model("claude-sonnet-4-5").MaxOutputTokens(1024).System("be brief").
+			CacheSystem(time.Hour).Messages(svc.UserMsg().Text("hi"))
+	}
+	params := newParams()
+	k1, err := svc.MarshalGenParams(params)
+	if err != nil {
+		t.Fatal("MarshalGenParams:", err)
+	}
+	k2, _ := svc.MarshalGenParams(params)
+	k3, _ := svc.MarshalGenParams(newParams().Timeout(time.Second))
+	k4, _ := svc.MarshalGenParams(newParams().Temperature(0.5))
+	if !bytes.Equal(k1, k2) || !bytes.Equal(k1, k3) || bytes.Equal(k1, k4) ||
+		!strings.Contains(string(k1), `"cache_control":{"ttl":"1h","type":"ephemeral"}`) {
+		t.Fatalf("MarshalGenParams: %s, %s, %s, %s", k1, k2, k3, k4)
+	}
+
+	resp, err := svc.UnmarshalGenResponse([]byte(`{"id":"msg_1","type":"message","role":"assistant",
+		"model":"claude-sonnet-4-5","stop_reason":"tool_use","content":[
+			{"type":"text","text":"hello"},
+			{"type":"tool_use","id":"toolu_1","name":"f","input":{"a":1}}
+		],"usage":{"input_tokens":10,"output_tokens":5}}`))
+	if err != nil {
+		t.Fatal("UnmarshalGenResponse:", err)
+	}
+	data, err := svc.MarshalGenResponse(resp)
+	if err != nil {
+		t.Fatal("MarshalGenResponse:", err)
+	}
+	ret, err := svc.UnmarshalGenResponse(data)
+	if err != nil {
+		t.Fatal("UnmarshalGenResponse:", err)
+	}
+	c := ret.At(0)
+	if use, ok := c.Part(1).AsToolUse(); c.Parts() != 2 || c.Part(0).Text() != "hello" || !ok || use.ID != "toolu_1" || use.Name != "f" {
+		t.Fatal("UnmarshalGenResponse: unexpected response", string(data))
+	}
+	if ret.Usage() != (xai.Usage{InputTokens: 10, OutputTokens: 5}) {
+		t.Fatal("Usage:", ret.Usage())
+	}
+	if msg := c.ToMsg().(*msgBuilder); len(msg.content) != 2 || msg.content[1].OfToolUse == nil {
+		t.Fatalf("ToMsg: %+v", msg.content)
+	}
+}
+
+// -----------------------------------------------------------------------------
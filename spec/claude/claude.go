@@ -26,36 +26,82 @@ import (
 	"github.com/anthropics/anthropic-sdk-go"
 	"github.com/anthropics/anthropic-sdk-go/option"
 	"github.com/goplus/xai"
+	"github.com/goplus/xai/util"
 )
 
 // -----------------------------------------------------------------------------
 
 type Service struct {
 	messages anthropic.BetaMessageService
+	models   anthropic.BetaModelService
+	files    anthropic.BetaFileService
+	batches  anthropic.BetaMessageBatchService
 	tools    tools
 }
 
+// Provider returns the provider of the service, i.e. Scheme.
+func (p *Service) Provider() string {
+	return Scheme
+}
+
 func (p *Service) Features() xai.Feature {
-	return xai.FeatureGen | xai.FeatureGenStream
+	return xai.FeatureGen | xai.FeatureGenStream | xai.FeatureCountTokens | xai.FeatureFiles | xai.FeatureBatch
 }
 
 // -----------------------------------------------------------------------------
 
 func (p *Service) Gen(ctx context.Context, gp xai.GenParams) (xai.GenResponse, error) {
-	params, opts := buildParams(gp)
+	ctx, params, opts := buildParams(ctx, gp)
+	if err := checkParams(&params); err != nil {
+		return nil, err
+	}
 	resp, err := p.messages.New(ctx, params, opts...)
 	if err != nil {
-		return nil, err // TODO(xsw): translate error
+		return nil, translateError(err)
 	}
 	return response{resp}, nil
 }
 
-func (p *Service) GenStream(ctx context.Context, gp xai.GenParams) iter.Seq2[xai.GenResponse, error] {
-	params, opts := buildParams(gp)
+func (p *Service) GenStream(ctx context.Context, gp xai.GenParams) iter.Seq2[xai.StreamEvent, error] {
+	ctx, params, opts := buildParams(ctx, gp)
+	if err := checkParams(&params); err != nil {
+		return func(yield func(xai.StreamEvent, error) bool) {
+			yield(xai.StreamEvent{}, err)
+		}
+	}
 	resp := p.messages.NewStreaming(ctx, params, opts...)
 	return buildRespIter(resp)
 }
 
+func (p *Service) CountTokens(ctx context.Context, gp xai.GenParams) (int64, error) {
+	ctx, in, opts := buildParams(ctx, gp)
+	params := anthropic.BetaMessageCountTokensParams{
+		Messages:          in.Messages,
+		Model:             in.Model,
+		ContextManagement: in.ContextManagement,
+		MCPServers:        in.MCPServers,
+		OutputConfig:      in.OutputConfig,
+		OutputFormat:      in.OutputFormat,
+		Thinking:          in.Thinking,
+		ToolChoice:        in.ToolChoice,
+		Tools:             countTokensTools(in.Tools),
+		Betas:             in.Betas,
+	}
+	if len(in.System) > 0 {
+		params.System.OfBetaTextBlockArray = in.System
+	}
+	resp, err := p.messages.CountTokens(ctx, params, opts...)
+	if err != nil {
+		return 0, translateError(err)
+	}
+	return resp.InputTokens, nil
+}
+
+func (p *Service) Embed(ctx context.Context, params xai.EmbedParams) (*xai.Embeddings, error) {
+	// claude doesn't provide an embeddings API.
+	return nil, xai.ErrNotFound
+}
+
 // -----------------------------------------------------------------------------
 
 func (p *Service) Actions(model xai.Model) []xai.Action {
@@ -77,7 +123,9 @@ const (
 // uri should be in the format of "claude:base=service_base_url&key=api_key".
 //
 // `base` is the base URL of the API endpoint.
-// `timeout` is the request timeout duration (e.g., "30s").
+// `timeout` is the timeout of each attempt of requests (e.g., "30s").
+// `retries` is the max number of retries on transient errors (default 2).
+// `retry_max_wait` is the max wait duration before a retry (e.g., "30s").
 // `key` is the API key for authentication (don't use both `key` and `token`).
 // `token` is the authentication token for the API requests.
 //
@@ -94,12 +142,21 @@ func New(ctx context.Context, uri string) (xai.Service, error) {
 	if base := params["base"]; len(base) > 0 {
 		opts = append(opts, option.WithBaseURL(base[0]))
 	}
-	if timeout := params["timeout"]; len(timeout) > 0 {
-		d, err := time.ParseDuration(timeout[0])
-		if err != nil {
+	var timeout time.Duration
+	if v := params["timeout"]; len(v) > 0 {
+		if timeout, err = time.ParseDuration(v[0]); err != nil {
 			return nil, err
 		}
-		opts = append(opts, option.WithRequestTimeout(d))
+	}
+	retry, err := util.ParseRetryPolicy(params)
+	if err != nil {
+		return nil, err
+	}
+	// Retries are done by the middleware instead of the SDK, so that they behave
+	// the same across all services, and the timeout applies to each attempt.
+	opts = append(opts, option.WithMaxRetries(0), option.WithMiddleware(util.RetryMiddleware(retry, timeout)))
+	if client := xai.HTTPClient(ctx); client != nil {
+		opts = append(opts, option.WithHTTPClient(client))
 	}
 	if key := params["key"]; len(key) > 0 {
 		opts = append(opts, option.WithAPIKey(key[0]))
@@ -109,6 +166,9 @@ func New(ctx context.Context, uri string) (xai.Service, error) {
 	}
 	return &Service{
 		messages: anthropic.NewBetaMessageService(opts...),
+		models:   anthropic.NewBetaModelService(opts...),
+		files:    anthropic.NewBetaFileService(opts...),
+		batches:  anthropic.NewBetaMessageBatchService(opts...),
 		tools:    make(tools),
 	}, nil
 }
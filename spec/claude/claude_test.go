@@ -1,6 +1,3 @@
-//go:build xaitest
-// +build xaitest
-
 /*
  * Copyright (c) 2026 The XGo Authors (xgo.dev). All rights reserved.
  *
@@ -27,6 +24,7 @@ import (
 )
 
 func TestClaude(t *testing.T) {
-	// TODO(xsw): implement test cases for claude.
-	xaitest.Do(t, context.Background(), "claude:xxx")
+	srv := xaitest.NewAnthropicServer()
+	defer srv.Close()
+	xaitest.Do(t, context.Background(), "claude:base="+srv.URL+"&key=test", "claude-sonnet-4-5")
 }
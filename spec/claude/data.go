@@ -143,6 +143,12 @@ func fromText(text string) xai.DocumentData {
 	}
 }
 
+// AcceptsDoc reports whether documents of mime are accepted: PDF documents by
+// URL, and PDF and plain text documents by data.
+func (p *Service) AcceptsDoc(mime xai.DocumentType, url bool) bool {
+	return mime == xai.DocPDF || mime == xai.DocPlainText && !url
+}
+
 func (p *Service) Docs() xai.DocumentBuilder {
 	return docBuilder{}
 }
@@ -0,0 +1,77 @@
+/*
+ * Copyright (c) 2026 The XGo Authors (xgo.dev). All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package claude
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/anthropics/anthropic-sdk-go"
+	"github.com/goplus/xai"
+	"github.com/goplus/xai/util"
+)
+
+// -----------------------------------------------------------------------------
+
+// errorKinds maps the `error.type` of an anthropic error response to xai error
+// kinds. See https://docs.claude.com/en/api/errors.
+var errorKinds = map[string]error{
+	"invalid_request_error": xai.ErrInvalidRequest,
+	"authentication_error":  xai.ErrAuth,
+	"permission_error":      xai.ErrAuth,
+	"not_found_error":       xai.ErrNotFound,
+	"request_too_large":     xai.ErrInvalidRequest,
+	"rate_limit_error":      xai.ErrRateLimited,
+	"api_error":             xai.ErrServer,
+	"overloaded_error":      xai.ErrOverloaded,
+}
+
+// translateError converts an anthropic error into an *xai.Error. Errors that
+// don't come from the API (e.g. context canceled) are returned unchanged.
+func translateError(err error) error {
+	var e *anthropic.Error
+	if !errors.As(err, &e) {
+		return err
+	}
+	var body struct {
+		Error struct {
+			Type    string `json:"type"`
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	json.Unmarshal([]byte(e.RawJSON()), &body)
+
+	kind := errorKinds[body.Error.Type]
+	if kind == xai.ErrInvalidRequest && isContextWindowExceeded(body.Error.Message) {
+		kind = xai.ErrContextWindowExceeded
+	}
+	var retryAfter time.Duration
+	if e.Response != nil {
+		retryAfter = util.RetryAfter(e.Response.Header)
+	}
+	return util.NewError(kind, e.StatusCode, retryAfter, err)
+}
+
+func isContextWindowExceeded(msg string) bool {
+	return strings.HasPrefix(msg, "prompt is too long") ||
+		strings.Contains(msg, "exceed context limit") ||
+		strings.Contains(msg, "context window")
+}
+
+// -----------------------------------------------------------------------------
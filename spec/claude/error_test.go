@@ -0,0 +1,75 @@
+/*
+ * Copyright (c) 2026 The XGo Authors (xgo.dev). All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package claude
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/goplus/xai"
+)
+
+// -----------------------------------------------------------------------------
+
+func TestTranslateError(t *testing.T) {
+	cases := []struct {
+		status     int
+		header     string // Retry-After
+		typ, msg   string
+		kind       error
+		retryAfter time.Duration
+	}{
+		{400, "", "invalid_request_error", "max_tokens: Field required", xai.ErrInvalidRequest, 0},
+		{400, "", "invalid_request_error", "prompt is too long: 208310 tokens > 200000 maximum", xai.ErrContextWindowExceeded, 0},
+		{400, "", "invalid_request_error", "input length and `max_tokens` exceed context limit: 197626 + 21333 > 200000", xai.ErrContextWindowExceeded, 0},
+		{401, "", "authentication_error", "invalid x-api-key", xai.ErrAuth, 0},
+		{403, "", "permission_error", "Your API key does not have permission to use the specified resource.", xai.ErrAuth, 0},
+		{404, "", "not_found_error", "model: claude-x", xai.ErrNotFound, 0},
+		{413, "", "request_too_large", "Request exceeds the maximum allowed number of bytes.", xai.ErrInvalidRequest, 0},
+		{429, "30", "rate_limit_error", "Number of request tokens has exceeded your per-minute rate limit", xai.ErrRateLimited, 30 * time.Second},
+		{500, "", "api_error", "Internal server error", xai.ErrServer, 0},
+		{529, "", "overloaded_error", "Overloaded", xai.ErrOverloaded, 0},
+		{503, "", "unknown_error", "unknown", xai.ErrOverloaded, 0}, // by the status code
+	}
+	for _, c := range cases {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if c.header != "" {
+				w.Header().Set("Retry-After", c.header)
+			}
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(c.status)
+			fmt.Fprintf(w, `{"type":"error","error":{"type":%q,"message":%q}}`, c.typ, c.msg)
+		}))
+		svc, err := New(context.Background(), "claude:key=test&retries=0&base="+srv.URL)
+		if err != nil {
+			t.Fatal("New:", err)
+		}
+		_, err = svc.Gen(context.Background(), svc.GenParams().Model("m").Messages(svc.UserMsg().Text("hi")))
+		srv.Close()
+		var e *xai.Error
+		if !errors.As(err, &e) || !errors.Is(err, c.kind) || e.StatusCode != c.status || e.RetryAfter != c.retryAfter {
+			t.Errorf("%s: unexpected %v", c.typ, err)
+		}
+	}
+}
+
+// -----------------------------------------------------------------------------
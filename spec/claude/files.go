@@ -0,0 +1,92 @@
+/*
+ * Copyright (c) 2026 The XGo Authors (xgo.dev). All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package claude
+
+import (
+	"context"
+	"io"
+	"iter"
+
+	"github.com/anthropics/anthropic-sdk-go"
+	"github.com/goplus/xai"
+)
+
+// -----------------------------------------------------------------------------
+
+// files implements xai.Files by the Files API (beta) of Anthropic. Files don't
+// expire, so ExpiresIn of UploadParams is ignored.
+type files struct {
+	svc anthropic.BetaFileService
+}
+
+func (p files) Upload(ctx context.Context, src io.Reader, params xai.UploadParams) (*xai.File, error) {
+	name := params.Name
+	if name == "" {
+		name = "file" // required by multipart forms
+	}
+	ret, err := p.svc.Upload(ctx, anthropic.BetaFileUploadParams{
+		File: anthropic.File(src, name, params.MIME),
+	})
+	if err != nil {
+		return nil, translateError(err)
+	}
+	return fileOf(ret), nil
+}
+
+func (p files) Get(ctx context.Context, id string) (*xai.File, error) {
+	ret, err := p.svc.GetMetadata(ctx, id, anthropic.BetaFileGetMetadataParams{})
+	if err != nil {
+		return nil, translateError(err)
+	}
+	return fileOf(ret), nil
+}
+
+func (p files) List(ctx context.Context) iter.Seq2[*xai.File, error] {
+	return func(yield func(*xai.File, error) bool) {
+		iter := p.svc.ListAutoPaging(ctx, anthropic.BetaFileListParams{})
+		for iter.Next() {
+			f := iter.Current()
+			if !yield(fileOf(&f), nil) {
+				return
+			}
+		}
+		if err := iter.Err(); err != nil {
+			yield(nil, translateError(err))
+		}
+	}
+}
+
+func (p files) Delete(ctx context.Context, id string) error {
+	_, err := p.svc.Delete(ctx, id, anthropic.BetaFileDeleteParams{})
+	return translateError(err)
+}
+
+func fileOf(f *anthropic.FileMetadata) *xai.File {
+	return &xai.File{
+		ID:        f.ID,
+		Name:      f.Filename,
+		MIME:      f.MimeType,
+		Size:      f.SizeBytes,
+		CreatedAt: f.CreatedAt,
+	}
+}
+
+func (p *Service) Files() xai.Files {
+	return files{p.files}
+}
+
+// -----------------------------------------------------------------------------
@@ -17,7 +17,10 @@
 package claude
 
 import (
+	"time"
+
 	"github.com/anthropics/anthropic-sdk-go"
+	"github.com/anthropics/anthropic-sdk-go/packages/param"
 	"github.com/goplus/xai"
 )
 
@@ -100,6 +103,17 @@ func (p *msgBuilder) DocFile(mime xai.DocumentType, fileID string) xai.MsgBuilde
 	return p
 }
 
+// DocCitations enables citations of the last document, which is copied as its
+// data may be shared by messages.
+func (p *msgBuilder) DocCitations() xai.MsgBuilder {
+	if n := len(p.content); n > 0 && p.content[n-1].OfDocument != nil {
+		doc := *p.content[n-1].OfDocument
+		doc.Citations.Enabled = param.NewOpt(true)
+		p.content[n-1].OfDocument = &doc
+	}
+	return p
+}
+
 func (p *msgBuilder) Part(part xai.Part) xai.MsgBuilder {
 	p.content = append(p.content, buildPart(part))
 	return p
@@ -108,9 +122,9 @@ func (p *msgBuilder) Part(part xai.Part) xai.MsgBuilder {
 func (p *msgBuilder) Thinking(v xai.Thinking) xai.MsgBuilder {
 	var content anthropic.BetaContentBlockParamUnion
 	if v.Redacted {
-		content = anthropic.NewBetaThinkingBlock(v.Signature, v.Text)
-	} else {
 		content = anthropic.NewBetaRedactedThinkingBlock(v.Signature)
+	} else {
+		content = anthropic.NewBetaThinkingBlock(v.Signature, v.Text)
 	}
 	p.content = append(p.content, content)
 	return p
@@ -121,4 +135,47 @@ func (p *msgBuilder) Compaction(data string) xai.MsgBuilder {
 	return p
 }
 
+// CacheBreakpoint sets the cache_control of the last block. Blocks that can't
+// be cached, e.g. thinking blocks, are left as is. As claude allows at most 4
+// breakpoints in a request, only the last 4 breakpoints of the request are sent,
+// in the order of tools, system prompt and messages.
+func (p *msgBuilder) CacheBreakpoint(ttl time.Duration) xai.MsgBuilder {
+	if n := len(p.content); n > 0 {
+		p.content[n-1] = blockWithCache(p.content[n-1], cacheControl(ttl))
+	}
+	return p
+}
+
+// blockWithCache returns block with the cache_control cc. Blocks that may be
+// shared, e.g. documents of DocumentBuilder, are copied.
+func blockWithCache(block anthropic.BetaContentBlockParamUnion, cc anthropic.BetaCacheControlEphemeralParam) anthropic.BetaContentBlockParamUnion {
+	switch {
+	case block.OfText != nil:
+		v := *block.OfText
+		v.CacheControl = cc
+		block.OfText = &v
+	case block.OfImage != nil:
+		v := *block.OfImage
+		v.CacheControl = cc
+		block.OfImage = &v
+	case block.OfDocument != nil:
+		v := *block.OfDocument
+		v.CacheControl = cc
+		block.OfDocument = &v
+	case block.OfToolUse != nil:
+		v := *block.OfToolUse
+		v.CacheControl = cc
+		block.OfToolUse = &v
+	case block.OfToolResult != nil:
+		v := *block.OfToolResult
+		v.CacheControl = cc
+		block.OfToolResult = &v
+	default:
+		if v := block.GetCacheControl(); v != nil {
+			*v = cc
+		}
+	}
+	return block
+}
+
 // -----------------------------------------------------------------------------
@@ -0,0 +1,65 @@
+/*
+ * Copyright (c) 2026 The XGo Authors (xgo.dev). All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package claude
+
+import (
+	"context"
+
+	"github.com/anthropics/anthropic-sdk-go"
+	"github.com/goplus/xai"
+	"github.com/goplus/xai/util"
+)
+
+// -----------------------------------------------------------------------------
+
+// knownModels completes the models listed by the API, which only provides their
+// IDs and display names.
+var knownModels = util.ModelTable{
+	{ID: "claude-opus-4-5*", ContextWindow: 200000, MaxOutputTokens: 64000, Tools: true, Thinking: true, StructuredOutput: true},
+	{ID: "claude-opus-4*", ContextWindow: 200000, MaxOutputTokens: 32000, Tools: true, Thinking: true, StructuredOutput: true},
+	{ID: "claude-sonnet-4*", ContextWindow: 200000, MaxOutputTokens: 64000, Tools: true, Thinking: true, StructuredOutput: true},
+	{ID: "claude-haiku-4*", ContextWindow: 200000, MaxOutputTokens: 64000, Tools: true, Thinking: true, StructuredOutput: true},
+	{ID: "claude-3-7-sonnet*", ContextWindow: 200000, MaxOutputTokens: 64000, Tools: true, Thinking: true},
+	{ID: "claude-3-5-haiku*", ContextWindow: 200000, MaxOutputTokens: 8192, Tools: true},
+	{ID: "claude-3-haiku*", ContextWindow: 200000, MaxOutputTokens: 4096, Tools: true},
+	{ID: "claude-*", Tools: true},
+}
+
+func init() {
+	// all claude models accept text, images and documents, and produce text.
+	for i := range knownModels {
+		knownModels[i].InputModalities = []xai.Modality{xai.ModalityText, xai.ModalityImage, xai.ModalityDocument}
+		knownModels[i].OutputModalities = []xai.Modality{xai.ModalityText}
+	}
+}
+
+func (p *Service) Models(ctx context.Context) (ret []*xai.ModelInfo, err error) {
+	iter := p.models.ListAutoPaging(ctx, anthropic.BetaModelListParams{})
+	for iter.Next() {
+		m := iter.Current()
+		ret = append(ret, knownModels.Complete(&xai.ModelInfo{
+			ID:          xai.Model(m.ID),
+			DisplayName: m.DisplayName,
+		}))
+	}
+	if err = iter.Err(); err != nil {
+		return nil, translateError(err)
+	}
+	return
+}
+
+// -----------------------------------------------------------------------------
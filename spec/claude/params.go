@@ -17,7 +17,10 @@
 package claude
 
 import (
+	"context"
+	"fmt"
 	"reflect"
+	"slices"
 	"time"
 
 	"github.com/anthropics/anthropic-sdk-go"
@@ -72,6 +75,14 @@ type params struct {
 	params  anthropic.BetaMessageNewParams
 	pparams *util.Params[adapter]
 	opts    []option.RequestOption
+
+	disableParallel param.Opt[bool] // disable_parallel_tool_use of ToolChoice
+	retry           *xai.RetryPolicy
+	timeout         time.Duration // timeout of each attempt
+
+	// cache_control of the last system block and the last tool, which are set by
+	// buildParams as System and Tools replace them.
+	sysCache, toolsCache *anthropic.BetaCacheControlEphemeralParam
 }
 
 /*
@@ -178,6 +189,40 @@ func (p *params) Tools(tools ...xai.ToolBase) xai.GenParams {
 	return p
 }
 
+func (p *params) ToolChoice(choice xai.ToolChoice) xai.GenParams {
+	var ret anthropic.BetaToolChoiceUnionParam
+	switch choice.Mode {
+	case xai.ToolChoiceAny:
+		ret.OfAny = &anthropic.BetaToolChoiceAnyParam{DisableParallelToolUse: p.disableParallel}
+	case xai.ToolChoiceNone:
+		ret.OfNone = &anthropic.BetaToolChoiceNoneParam{}
+	case xai.ToolChoiceTool:
+		ret.OfTool = &anthropic.BetaToolChoiceToolParam{Name: choice.Name, DisableParallelToolUse: p.disableParallel}
+	default:
+		ret.OfAuto = &anthropic.BetaToolChoiceAutoParam{DisableParallelToolUse: p.disableParallel}
+	}
+	p.params.ToolChoice = ret
+	return p
+}
+
+func (p *params) ParallelToolCalls(enabled bool) xai.GenParams {
+	// claude specifies disable_parallel_tool_use in the tool choice, which
+	// defaults to auto.
+	p.disableParallel = param.NewOpt(!enabled)
+	choice := &p.params.ToolChoice
+	switch {
+	case choice.OfAny != nil:
+		choice.OfAny.DisableParallelToolUse = p.disableParallel
+	case choice.OfTool != nil:
+		choice.OfTool.DisableParallelToolUse = p.disableParallel
+	case choice.OfAuto != nil:
+		choice.OfAuto.DisableParallelToolUse = p.disableParallel
+	case choice.OfNone == nil:
+		choice.OfAuto = &anthropic.BetaToolChoiceAutoParam{DisableParallelToolUse: p.disableParallel}
+	}
+	return p
+}
+
 func (p *params) Model(model xai.Model) xai.GenParams {
 	p.params.Model = anthropic.Model(model) // TODO(xsw): validate model
 	return p
@@ -202,6 +247,31 @@ func (p *params) Compact(maxInputTokens int64) xai.GenParams {
 	return p
 }
 
+func (p *params) CacheSystem(ttl time.Duration) xai.GenParams {
+	cc := cacheControl(ttl)
+	p.sysCache = &cc
+	return p
+}
+
+func (p *params) CacheTools(ttl time.Duration) xai.GenParams {
+	cc := cacheControl(ttl)
+	p.toolsCache = &cc
+	return p
+}
+
+// cacheControl returns the cache_control of ttl, whose TTL is either 5 minutes
+// or an hour.
+func cacheControl(ttl time.Duration) anthropic.BetaCacheControlEphemeralParam {
+	ret := anthropic.NewBetaCacheControlEphemeralParam()
+	switch {
+	case ttl > 5*time.Minute:
+		ret.TTL = anthropic.BetaCacheControlEphemeralTTLTTL1h
+	case ttl > 0:
+		ret.TTL = anthropic.BetaCacheControlEphemeralTTLTTL5m
+	}
+	return ret
+}
+
 func (p *params) Temperature(v float64) xai.GenParams {
 	if v > 1 {
 		v = 1 // claude does not support temperature > 1
@@ -215,13 +285,49 @@ func (p *params) TopP(v float64) xai.GenParams {
 	return p
 }
 
+func (p *params) Thinking(conf xai.ThinkingConfig) xai.GenParams {
+	switch budget := conf.BudgetTokens(); {
+	case conf.Disabled():
+		p.params.Thinking = anthropic.BetaThinkingConfigParamUnion{
+			OfDisabled: &anthropic.BetaThinkingConfigDisabledParam{},
+		}
+	case budget > 0:
+		// claude requires a minimum budget of 1,024 tokens, and thinking is
+		// always returned (summarized by claude 4 models).
+		p.params.Thinking = anthropic.BetaThinkingConfigParamOfEnabled(max(budget, 1024))
+	}
+	return p
+}
+
+func (p *params) ResponseFormat(format xai.ResponseFormat) xai.GenParams {
+	switch format.Type {
+	case xai.FormatJSONSchema:
+		p.params.OutputConfig.Format = anthropic.BetaJSONOutputFormatParam{
+			Schema: format.Schema,
+		}
+	case xai.FormatText:
+		p.params.OutputConfig.Format = anthropic.BetaJSONOutputFormatParam{}
+	default:
+		// claude doesn't support JSON output without a schema, so we just
+		// ignore it.
+	}
+	return p
+}
+
 func (p *params) BaseURL(base string) xai.GenParams {
 	p.opts = append(p.opts, option.WithBaseURL(base))
 	return p
 }
 
+// Timeout sets the timeout of each attempt of the request, as requests are
+// retried by util.RetryMiddleware rather than the SDK.
 func (p *params) Timeout(timeout time.Duration) xai.GenParams {
-	p.opts = append(p.opts, option.WithRequestTimeout(timeout))
+	p.timeout = timeout
+	return p
+}
+
+func (p *params) Retry(policy xai.RetryPolicy) xai.GenParams {
+	p.retry = &policy
 	return p
 }
 
@@ -229,10 +335,86 @@ func (p *Service) GenParams() xai.GenParams {
 	return &params{}
 }
 
-func buildParams(in xai.GenParams) (anthropic.BetaMessageNewParams, []option.RequestOption) {
+func buildParams(ctx context.Context, in xai.GenParams) (context.Context, anthropic.BetaMessageNewParams, []option.RequestOption) {
 	p := in.(*params)
 	// TODO(xsw): check param values
-	return p.params, p.opts
+	ret := p.params
+	if usesFiles(ret.Messages) {
+		ret.Betas = append(slices.Clip(ret.Betas), anthropic.AnthropicBetaFilesAPI2025_04_14)
+	}
+	var left int
+	ret.Messages, left = limitCacheBreakpoints(ret.Messages, maxCacheBreakpoints)
+	if n := len(ret.System); n > 0 && p.sysCache != nil && left > 0 {
+		ret.System = slices.Clone(ret.System)
+		ret.System[n-1].CacheControl = *p.sysCache
+		left--
+	}
+	if n := len(ret.Tools); n > 0 && p.toolsCache != nil && left > 0 {
+		ret.Tools = slices.Clone(ret.Tools)
+		ret.Tools[n-1] = toolWithCache(ret.Tools[n-1], *p.toolsCache)
+	}
+	return util.WithTimeout(util.WithRetryPolicy(ctx, p.retry), p.timeout), ret, p.opts
+}
+
+// checkParams checks the params of a generation request, as claude requires
+// max_tokens to be greater than the thinking budget.
+func checkParams(params *anthropic.BetaMessageNewParams) error {
+	if v := params.Thinking.OfEnabled; v != nil && params.MaxTokens <= v.BudgetTokens {
+		return fmt.Errorf("claude: %w: max output tokens %d must be greater than the thinking budget %d",
+			xai.ErrInvalidRequest, params.MaxTokens, v.BudgetTokens)
+	}
+	return nil
+}
+
+// maxCacheBreakpoints is the maximum number of cache_control blocks that claude
+// allows in a request.
+const maxCacheBreakpoints = 4
+
+// limitCacheBreakpoints keeps the last n cache breakpoints of msgs and removes
+// the earlier ones, e.g. those set by CacheBreakpoint on messages of earlier
+// turns. It returns msgs, copied if changed, and the number of breakpoints left
+// for the system prompt and tools.
+func limitCacheBreakpoints(msgs []anthropic.BetaMessageParam, n int) ([]anthropic.BetaMessageParam, int) {
+	cloned := false
+	for i := len(msgs) - 1; i >= 0; i-- {
+		var content []anthropic.BetaContentBlockParamUnion
+		for j := len(msgs[i].Content) - 1; j >= 0; j-- {
+			if cc := msgs[i].Content[j].GetCacheControl(); cc == nil || cc.Type == "" {
+				continue
+			}
+			if n > 0 {
+				n--
+				continue
+			}
+			if content == nil {
+				content = slices.Clone(msgs[i].Content)
+			}
+			content[j] = blockWithCache(content[j], anthropic.BetaCacheControlEphemeralParam{})
+		}
+		if content != nil {
+			if !cloned {
+				msgs, cloned = slices.Clone(msgs), true
+			}
+			msgs[i].Content = content
+		}
+	}
+	return msgs, n
+}
+
+// usesFiles reports whether msgs reference uploaded files, which requires the
+// beta of the Files API.
+func usesFiles(msgs []anthropic.BetaMessageParam) bool {
+	for _, msg := range msgs {
+		for _, block := range msg.Content {
+			if v := block.OfImage; v != nil && v.Source.OfFile != nil {
+				return true
+			}
+			if v := block.OfDocument; v != nil && v.Source.OfFile != nil {
+				return true
+			}
+		}
+	}
+	return false
 }
 
 // -----------------------------------------------------------------------------
@@ -0,0 +1,213 @@
+/*
+ * Copyright (c) 2026 The XGo Authors (xgo.dev). All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package claude
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/anthropics/anthropic-sdk-go"
+	"github.com/goplus/xai"
+)
+
+// -----------------------------------------------------------------------------
+
+func cacheBreakpoints(msgs []anthropic.BetaMessageParam) (ret []string) {
+	for _, msg := range msgs {
+		for _, block := range msg.Content {
+			if cc := block.GetCacheControl(); cc != nil && cc.Type != "" {
+				ret = append(ret, *block.GetText())
+			}
+		}
+	}
+	return
+}
+
+func newService(t *testing.T) *Service {
+	svc, err := New(context.Background(), "claude:key=test")
+	if err != nil {
+		t.Fatal("New:", err)
+	}
+	return svc.(*Service)
+}
+
+func TestCacheBreakpoints(t *testing.T) {
+	svc := newService(t)
+	build := func(texts ...string) anthropic.BetaMessageNewParams {
+		msgs := make([]xai.MsgBuilder, len(texts))
+		for i, text := range texts {
+			msgs[i] = svc.UserMsg().Text(text).CacheBreakpoint(time.Hour)
+		}
+		params := svc.GenParams().System("sys").CacheSystem(time.Hour).
+			Tools(svc.ToolDef("t" + texts[0])).CacheTools(time.Hour).Messages(msgs...)
+		_, ret, _ := buildParams(context.Background(), params)
+		return ret
+	}
+	cached := func(ret anthropic.BetaMessageNewParams) string {
+		return fmt.Sprint(cacheBreakpoints(ret.Messages), ret.System[0].CacheControl.Type != "",
+			ret.Tools[0].OfTool.CacheControl.Type != "")
+	}
+
+	// only the last 4 breakpoints are sent
+	if got := cached(build("1", "2")); got != "[1 2] true true" {
+		t.Fatal("breakpoints:", got)
+	}
+	if got := cached(build("3", "4", "5")); got != "[3 4 5] true false" {
+		t.Fatal("breakpoints:", got)
+	}
+	ret := build("6", "7", "8", "9", "10")
+	if got := cached(ret); got != "[7 8 9 10] false false" {
+		t.Fatal("breakpoints:", got)
+	}
+	if cc := svc.UserMsg().Text("x").CacheBreakpoint(time.Hour).(*msgBuilder).content[0].GetCacheControl(); cc.Type == "" {
+		t.Fatal("CacheBreakpoint: no cache_control")
+	}
+}
+
+func TestInputSchema(t *testing.T) {
+	svc := newService(t)
+	tool := svc.ToolDef("t").InputSchema(xai.RawMessage(`{
+		"type": "object",
+		"properties": {"a": {"type": "string"}},
+		"required": ["a", 1, null]
+	}`)).(tool)
+	if got := tool.tool.InputSchema.Required; len(got) != 1 || got[0] != "a" {
+		t.Fatal("Required:", got)
+	}
+}
+
+func TestThinking(t *testing.T) {
+	svc := newService(t)
+	cases := []struct {
+		conf xai.ThinkingConfig
+		want string
+	}{
+		{xai.ThinkingBudget(2048, false), "enabled 2048"},
+		{xai.ThinkingBudget(100, true), "enabled 1024"},
+		{xai.ThinkingEffort(xai.EffortMedium, false), "enabled 8192"},
+		{xai.NoThinking(), "disabled"},
+		{xai.ThinkingConfig{}, "default"},
+	}
+	for _, c := range cases {
+		p := svc.GenParams().Thinking(c.conf).(*params)
+		got := "default"
+		if v := p.params.Thinking.OfEnabled; v != nil {
+			got = fmt.Sprint("enabled ", v.BudgetTokens)
+		} else if p.params.Thinking.OfDisabled != nil {
+			got = "disabled"
+		}
+		if got != c.want {
+			t.Errorf("Thinking(%+v): got %s, want %s", c.conf, got, c.want)
+		}
+	}
+
+	// max output tokens must be greater than the thinking budget
+	params := svc.GenParams().Model("claude-sonnet-4-5").MaxOutputTokens(2048).
+		Thinking(xai.ThinkingBudget(2048, true)).Messages(svc.UserMsg().Text("hi"))
+	if _, err := svc.Gen(context.Background(), params); !errors.Is(err, xai.ErrInvalidRequest) {
+		t.Fatal("Gen: want ErrInvalidRequest, got", err)
+	}
+	var last error
+	for _, err := range svc.GenStream(context.Background(), params) {
+		last = err
+	}
+	if !errors.Is(last, xai.ErrInvalidRequest) {
+		t.Fatal("GenStream: want ErrInvalidRequest, got", last)
+	}
+}
+
+func TestThinkingMsg(t *testing.T) {
+	svc := newService(t)
+	msg := svc.AssistantMsg().
+		Thinking(xai.Thinking{Text: "hmm", Signature: "sig"}).
+		Thinking(xai.Thinking{Signature: "data", Redacted: true}).(*msgBuilder)
+	if v := msg.content[0].OfThinking; v == nil || v.Thinking != "hmm" || v.Signature != "sig" {
+		t.Fatalf("Thinking: %+v", msg.content[0])
+	}
+	if v := msg.content[1].OfRedactedThinking; v == nil || v.Data != "data" {
+		t.Fatalf("Thinking: redacted %+v", msg.content[1])
+	}
+}
+
+func TestToolChoice(t *testing.T) {
+	svc := newService(t)
+	cases := []struct {
+		build func(xai.GenParams) xai.GenParams
+		want  string
+	}{
+		{func(p xai.GenParams) xai.GenParams { return p.ToolChoice(xai.ToolChoice{}) }, `{"type":"auto"}`},
+		{func(p xai.GenParams) xai.GenParams { return p.ToolChoice(xai.ToolChoice{Mode: xai.ToolChoiceNone}) }, `{"type":"none"}`},
+		{func(p xai.GenParams) xai.GenParams {
+			return p.ParallelToolCalls(false).ToolChoice(xai.ToolChoice{Mode: xai.ToolChoiceAny})
+		}, `{"disable_parallel_tool_use":true,"type":"any"}`},
+		{func(p xai.GenParams) xai.GenParams {
+			return p.ToolChoice(xai.UseTool("f")).ParallelToolCalls(false)
+		}, `{"name":"f","disable_parallel_tool_use":true,"type":"tool"}`},
+		{func(p xai.GenParams) xai.GenParams { return p.ParallelToolCalls(true) }, `{"disable_parallel_tool_use":false,"type":"auto"}`},
+	}
+	for i, c := range cases {
+		p := c.build(svc.GenParams()).(*params)
+		if b, _ := json.Marshal(p.params.ToolChoice); string(b) != c.want {
+			t.Errorf("case %d: got %s, want %s", i, b, c.want)
+		}
+	}
+}
+
+func TestTimeout(t *testing.T) {
+	// the first attempt times out, and the second one succeeds
+	var attempts atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.Copy(io.Discard, r.Body)
+		if attempts.Add(1) == 1 {
+			select {
+			case <-r.Context().Done():
+			case <-time.After(time.Second):
+			}
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"id":"msg_1","type":"message","role":"assistant","model":"m",`+
+			`"content":[{"type":"text","text":"hi"}],"stop_reason":"end_turn","usage":{"input_tokens":1,"output_tokens":1}}`)
+	}))
+	defer srv.Close()
+
+	// the timeout of the URI or of the call applies to each attempt
+	for _, uri := range []string{"&timeout=100ms&retries=1&retry_max_wait=1ms", ""} {
+		attempts.Store(0)
+		svc, err := New(context.Background(), "claude:key=test&base="+srv.URL+uri)
+		if err != nil {
+			t.Fatal("New:", err)
+		}
+		params := svc.GenParams().Model("m").Messages(svc.UserMsg().Text("hi"))
+		if uri == "" {
+			params.Timeout(100 * time.Millisecond).Retry(xai.RetryPolicy{MaxRetries: 1, MaxWait: time.Millisecond})
+		}
+		if _, err = svc.Gen(context.Background(), params); err != nil || attempts.Load() != 2 {
+			t.Fatal("Gen:", uri, attempts.Load(), err)
+		}
+	}
+}
+
+// -----------------------------------------------------------------------------
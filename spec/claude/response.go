@@ -131,6 +131,33 @@ func (p contentBlock) Text() string {
 	return p.content.Text
 }
 
+// Citations returns the citations of a text block, which cite the sources of
+// the whole block.
+func (p contentBlock) Citations() []xai.Citation {
+	if p.content.Type != "text" || len(p.content.Citations) == 0 {
+		return nil
+	}
+	ret := make([]xai.Citation, len(p.content.Citations))
+	for i := range p.content.Citations {
+		c := &p.content.Citations[i]
+		ret[i] = xai.Citation{
+			End:        len(p.content.Text),
+			CitedText:  c.CitedText,
+			URL:        c.URL,
+			Title:      c.Title,
+			DocIndex:   -1,
+			Underlying: c,
+		}
+		switch c.Type {
+		case "char_location", "page_location", "content_block_location":
+			ret[i].Title, ret[i].DocIndex = c.DocumentTitle, int(c.DocumentIndex)
+		case "search_result_location":
+			ret[i].URL = c.Source
+		}
+	}
+	return ret
+}
+
 func (p contentBlock) Underlying() any {
 	return p.content
 }
@@ -174,6 +201,16 @@ func (p response) At(i int) xai.Candidate {
 	return p
 }
 
+func (p response) Usage() xai.Usage {
+	u := &p.msg.Usage
+	return xai.Usage{
+		InputTokens:      u.InputTokens + u.CacheCreationInputTokens + u.CacheReadInputTokens,
+		OutputTokens:     u.OutputTokens,
+		CacheReadTokens:  u.CacheReadInputTokens,
+		CacheWriteTokens: u.CacheCreationInputTokens,
+	}
+}
+
 func (p response) ToMsg() xai.MsgBuilder {
 	content := make([]anthropic.BetaContentBlockParamUnion, len(p.msg.Content))
 	for i, c := range p.msg.Content {
@@ -184,8 +221,73 @@ func (p response) ToMsg() xai.MsgBuilder {
 
 // -----------------------------------------------------------------------------
 
-func buildRespIter(stream *ssestream.Stream[anthropic.BetaRawMessageStreamEventUnion]) iter.Seq2[xai.GenResponse, error] {
-	panic("todo")
+func buildRespIter(stream *ssestream.Stream[anthropic.BetaRawMessageStreamEventUnion]) iter.Seq2[xai.StreamEvent, error] {
+	return func(yield func(xai.StreamEvent, error) bool) {
+		defer stream.Close()
+		msg := new(anthropic.BetaMessage)
+		var blocks []xai.BlockType
+		for stream.Next() {
+			event := stream.Current()
+			if err := msg.Accumulate(event); err != nil {
+				yield(xai.StreamEvent{}, err)
+				return
+			}
+			ev := xai.StreamEvent{Index: int(event.Index), Underlying: &event}
+			switch event.Type {
+			case "message_start":
+				ev.Type = xai.EventStart
+			case "content_block_start":
+				ev.Type = xai.EventBlockStart
+				switch block := &event.ContentBlock; block.Type {
+				case "text":
+					ev.Block = xai.BlockText
+				case "thinking", "redacted_thinking":
+					ev.Block = xai.BlockThinking
+				case "tool_use":
+					ev.Block = xai.BlockToolUse
+					ev.ToolUse = xai.ToolUse{ID: block.ID, Name: block.Name}
+				}
+				for len(blocks) <= ev.Index {
+					blocks = append(blocks, xai.BlockOther)
+				}
+				blocks[ev.Index] = ev.Block
+			case "content_block_delta":
+				ev.Type = xai.EventDelta
+				ev.Block = blockType(blocks, ev.Index)
+				switch delta := &event.Delta; delta.Type {
+				case "text_delta":
+					ev.Delta = delta.Text
+				case "thinking_delta":
+					ev.Delta = delta.Thinking
+				case "input_json_delta":
+					ev.Delta = delta.PartialJSON
+				default: // signature_delta, citations_delta, etc.
+					continue
+				}
+			case "content_block_stop":
+				ev.Type = xai.EventBlockStop
+				ev.Block = blockType(blocks, ev.Index)
+			case "message_stop":
+				ev.Type = xai.EventStop
+				ev.Response = response{msg}
+			default: // message_delta, etc.
+				continue
+			}
+			if !yield(ev, nil) {
+				return
+			}
+		}
+		if err := stream.Err(); err != nil {
+			yield(xai.StreamEvent{}, translateError(err))
+		}
+	}
+}
+
+func blockType(blocks []xai.BlockType, i int) xai.BlockType {
+	if i < len(blocks) {
+		return blocks[i]
+	}
+	return xai.BlockOther
 }
 
 // -----------------------------------------------------------------------------
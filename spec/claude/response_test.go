@@ -0,0 +1,45 @@
+/*
+ * Copyright (c) 2026 The XGo Authors (xgo.dev). All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package claude
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/anthropics/anthropic-sdk-go"
+	"github.com/goplus/xai"
+)
+
+// -----------------------------------------------------------------------------
+
+func TestUsage(t *testing.T) {
+	var msg anthropic.BetaMessage
+	err := json.Unmarshal([]byte(`{"usage": {
+		"input_tokens": 10, "output_tokens": 5,
+		"cache_creation_input_tokens": 20, "cache_read_input_tokens": 30
+	}}`), &msg)
+	if err != nil {
+		t.Fatal("Unmarshal:", err)
+	}
+	// input tokens of claude exclude the tokens read from or written to the cache
+	want := xai.Usage{InputTokens: 60, OutputTokens: 5, CacheReadTokens: 30, CacheWriteTokens: 20}
+	if got := (response{&msg}).Usage(); got != want {
+		t.Fatalf("Usage: got %+v, want %+v", got, want)
+	}
+}
+
+// -----------------------------------------------------------------------------
@@ -43,8 +43,38 @@ func (p tool) Description(desc string) xai.Tool {
 	return p
 }
 
+func (p tool) InputSchema(schema any) xai.Tool {
+	m := xai.ParseSchema(schema)
+	in := anthropic.BetaToolInputSchemaParam{
+		Properties: m["properties"],
+	}
+	if required, ok := m["required"].([]any); ok {
+		in.Required = make([]string, 0, len(required))
+		for _, v := range required {
+			if name, ok := v.(string); ok { // skip invalid entries of the caller
+				in.Required = append(in.Required, name)
+			}
+		}
+	}
+	for k, v := range m {
+		switch k {
+		case "type", "properties", "required":
+		default:
+			if in.ExtraFields == nil {
+				in.ExtraFields = make(map[string]any)
+			}
+			in.ExtraFields[k] = v
+		}
+	}
+	p.tool.InputSchema = in
+	return p
+}
+
 func (p *Service) Tool(name string) xai.Tool {
-	return p.tools[name]
+	if t, ok := p.tools[name]; ok {
+		return t
+	}
+	return nil
 }
 
 func (p *Service) ToolDef(name string) xai.Tool {
@@ -64,6 +94,32 @@ func buildTools(tools []xai.ToolBase) []anthropic.BetaToolUnionParam {
 	return ret
 }
 
+// toolWithCache returns a copy of tool with the cache_control cc, as tools are
+// shared by requests.
+func toolWithCache(tool anthropic.BetaToolUnionParam, cc anthropic.BetaCacheControlEphemeralParam) anthropic.BetaToolUnionParam {
+	switch {
+	case tool.OfTool != nil:
+		v := *tool.OfTool
+		v.CacheControl = cc
+		tool.OfTool = &v
+	case tool.OfWebSearchTool20260209 != nil:
+		v := *tool.OfWebSearchTool20260209
+		v.CacheControl = cc
+		tool.OfWebSearchTool20260209 = &v
+	}
+	return tool
+}
+
+// countTokensTools converts the tools built by buildTools for CountTokens.
+func countTokensTools(tools []anthropic.BetaToolUnionParam) []anthropic.BetaMessageCountTokensParamsToolUnion {
+	ret := make([]anthropic.BetaMessageCountTokensParamsToolUnion, len(tools))
+	for i, v := range tools {
+		ret[i].OfTool = v.OfTool
+		ret[i].OfWebSearchTool20260209 = v.OfWebSearchTool20260209
+	}
+	return ret
+}
+
 // -----------------------------------------------------------------------------
 
 type webSearchTool struct {
@@ -0,0 +1,102 @@
+/*
+ * Copyright (c) 2026 The XGo Authors (xgo.dev). All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package claude
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/anthropics/anthropic-sdk-go"
+	"github.com/goplus/xai"
+)
+
+// -----------------------------------------------------------------------------
+
+// ExportMsg exports the message to the transcript format.
+func (p *msgBuilder) ExportMsg() (*xai.Message, error) {
+	ret := &xai.Message{Role: xai.RoleUser, Parts: make([]xai.MessagePart, len(p.content))}
+	if p.role == anthropic.BetaMessageParamRoleAssistant {
+		ret.Role = xai.RoleAssistant
+	}
+	for i, c := range p.content {
+		part, err := exportPart(&c)
+		if err != nil {
+			return nil, err
+		}
+		ret.Parts[i] = part
+	}
+	return ret, nil
+}
+
+func exportPart(c *anthropic.BetaContentBlockParamUnion) (ret xai.MessagePart, err error) {
+	switch {
+	case c.OfText != nil:
+		return xai.MessagePart{Type: xai.PartText, Text: c.OfText.Text}, nil
+	case c.OfImage != nil:
+		src := &c.OfImage.Source
+		switch {
+		case src.OfBase64 != nil:
+			return xai.BlobPart(xai.PartImage, string(src.OfBase64.MediaType), "", src.OfBase64.Data)
+		case src.OfURL != nil:
+			return xai.MessagePart{Type: xai.PartImage, URL: src.OfURL.URL}, nil
+		case src.OfFile != nil:
+			return xai.MessagePart{Type: xai.PartImage, FileID: src.OfFile.FileID}, nil
+		}
+	case c.OfDocument != nil:
+		src := &c.OfDocument.Source
+		switch {
+		case src.OfBase64 != nil:
+			return xai.BlobPart(xai.PartDoc, string(xai.DocPDF), "", src.OfBase64.Data)
+		case src.OfText != nil:
+			return xai.MessagePart{Type: xai.PartDoc, MIME: string(xai.DocPlainText), Data: []byte(src.OfText.Data)}, nil
+		case src.OfURL != nil:
+			return xai.MessagePart{Type: xai.PartDoc, MIME: string(xai.DocPDF), URL: src.OfURL.URL}, nil
+		case src.OfFile != nil:
+			return xai.MessagePart{Type: xai.PartDoc, FileID: src.OfFile.FileID}, nil
+		}
+	case c.OfThinking != nil:
+		return xai.MessagePart{
+			Type: xai.PartThinking, Text: c.OfThinking.Thinking, Signature: c.OfThinking.Signature, Provider: Scheme,
+		}, nil
+	case c.OfRedactedThinking != nil:
+		return xai.MessagePart{
+			Type: xai.PartThinking, Signature: c.OfRedactedThinking.Data, Redacted: true, Provider: Scheme,
+		}, nil
+	case c.OfToolUse != nil:
+		input, err := json.Marshal(c.OfToolUse.Input)
+		if err != nil {
+			return ret, err
+		}
+		return xai.MessagePart{Type: xai.PartToolUse, ID: c.OfToolUse.ID, Name: c.OfToolUse.Name, Input: input}, nil
+	case c.OfToolResult != nil:
+		var b strings.Builder
+		for _, content := range c.OfToolResult.Content {
+			if content.OfText == nil {
+				return ret, fmt.Errorf("claude: non-text tool result: %w", xai.ErrNotExportable)
+			}
+			b.WriteString(content.OfText.Text)
+		}
+		r := c.OfToolResult
+		return xai.ToolResultPart(r.ToolUseID, "", b.String(), r.IsError.Value), nil
+	case c.OfCompaction != nil:
+		return xai.MessagePart{Type: xai.PartCompaction, Text: c.OfCompaction.Content.Value, Provider: Scheme}, nil
+	}
+	return ret, fmt.Errorf("claude: content block: %w", xai.ErrNotExportable)
+}
+
+// -----------------------------------------------------------------------------
@@ -0,0 +1,49 @@
+/*
+ * Copyright (c) 2026 The XGo Authors (xgo.dev). All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package claude
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/goplus/xai"
+)
+
+// -----------------------------------------------------------------------------
+
+func TestLoadTranscript(t *testing.T) {
+	// a transcript exported from gemini, with audio and video claude doesn't take
+	const data = `{"messages": [{"role": "user", "parts": [
+		{"type": "text", "text": "listen", "signature": "sig", "provider": "gemini"},
+		{"type": "document", "mime": "audio/mp3", "name": "a.mp3", "data": "YXVkaW8="},
+		{"type": "document", "mime": "video/mp4", "url": "https://generativelanguage.googleapis.com/v1beta/files/v"},
+		{"type": "document", "mime": "text/plain", "url": "https://example.com/a.txt"},
+		{"type": "document", "mime": "application/pdf", "name": "a.pdf", "data": "JVBERg=="},
+		{"type": "document", "mime": "text/plain", "data": "bm90ZXM="}
+	]}]}`
+	var tr xai.Transcript
+	if err := json.Unmarshal([]byte(data), &tr); err != nil {
+		t.Fatal("Unmarshal:", err)
+	}
+	msg := tr.Msgs(newService(t))[0].(*msgBuilder)
+	if len(msg.content) != 3 || msg.content[0].OfText == nil || msg.content[1].OfDocument == nil ||
+		msg.content[2].OfDocument == nil || msg.content[2].OfDocument.Source.OfText == nil {
+		t.Fatal("Msgs: unexpected", msg.content)
+	}
+}
+
+// -----------------------------------------------------------------------------
@@ -0,0 +1,162 @@
+/*
+ * Copyright (c) 2026 The XGo Authors (xgo.dev). All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package gemini
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/goplus/xai"
+	"github.com/goplus/xai/util"
+	"google.golang.org/genai"
+)
+
+// -----------------------------------------------------------------------------
+
+// SubmitBatch submits reqs as a batch of inlined requests, which is unavailable
+// with Vertex AI. The batch is submitted by the context and model of the first
+// request, as a batch serves a single model. See
+// https://ai.google.dev/gemini-api/docs/batch-mode.
+func (p *Service) SubmitBatch(ctx context.Context, reqs []xai.GenParams) (xai.BatchResponse, error) {
+	if p.vertex {
+		return nil, xai.ErrNotFound
+	}
+	if len(reqs) == 0 {
+		return nil, errors.New("gemini: empty batch")
+	}
+	ctx, model, _, config := buildGenParams(ctx, reqs[0])
+	src := &genai.BatchJobSource{InlinedRequests: make([]*genai.InlinedRequest, len(reqs))}
+	for i, gp := range reqs {
+		_, _, contents, config := buildGenParams(ctx, gp)
+		conf := *config
+		conf.HTTPOptions = nil
+		src.InlinedRequests[i] = &genai.InlinedRequest{
+			Contents: contents,
+			Config:   &conf,
+			Metadata: map[string]string{batchKey: util.CustomID(i)},
+		}
+	}
+	resp, err := p.batches.Create(ctx, model, src, &genai.CreateBatchJobConfig{HTTPOptions: config.HTTPOptions})
+	if err != nil {
+		return nil, translateError(err)
+	}
+	poller := &batchPoller{p.batches, resp.Name, len(reqs), config.HTTPOptions}
+	status, _, err := poller.result(resp)
+	if err != nil {
+		return nil, err
+	}
+	return util.NewBatch(status, poller, batchInterval), nil
+}
+
+const (
+	batchInterval = 30 * time.Second
+
+	// batchKey is the key of the custom ID in the metadata of inlined requests,
+	// which is returned in the metadata of their responses.
+	batchKey = "key"
+)
+
+type batchPoller struct {
+	svc  genai.Batches
+	name string
+	n    int
+	opts *genai.HTTPOptions
+}
+
+func (p *batchPoller) Poll(ctx context.Context, wo *util.WaitOptions) (util.BatchStatus, []*xai.BatchResult, error) {
+	var opts *genai.HTTPOptions
+	if p.opts != nil || wo.BaseURL != "" {
+		opts = new(genai.HTTPOptions)
+		if p.opts != nil {
+			*opts = *p.opts
+		}
+		if wo.BaseURL != "" {
+			opts.BaseURL = wo.BaseURL
+		}
+	}
+	resp, err := p.svc.Get(ctx, p.name, &genai.GetBatchJobConfig{HTTPOptions: opts})
+	if err != nil {
+		return util.BatchStatus{}, nil, translateError(err)
+	}
+	return p.result(resp)
+}
+
+// result returns the status of the batch, and the results of the requests once
+// the batch ended.
+func (p *batchPoller) result(resp *genai.BatchJob) (util.BatchStatus, []*xai.BatchResult, error) {
+	status := util.BatchStatus{ID: resp.Name, Requests: p.n}
+	switch resp.State {
+	case genai.JobStateSucceeded, genai.JobStatePartiallySucceeded, genai.JobStateFailed,
+		genai.JobStateCancelled, genai.JobStateExpired:
+		status.Ended = true
+	default:
+		return status, nil, nil
+	}
+	var results []*xai.BatchResult
+	if resp.Dest != nil {
+		for i, r := range resp.Dest.InlinedResponses {
+			ret := &xai.BatchResult{CustomID: r.Metadata[batchKey]}
+			if ret.CustomID == "" {
+				ret.CustomID = util.CustomID(i) // responses are in the order of requests
+			}
+			switch {
+			case r.Error != nil:
+				ret.Err = jobError(r.Error)
+			case r.Response != nil:
+				if ret.Err = checkBlocked(r.Response); ret.Err == nil {
+					ret.Response = response{r.Response}
+				}
+			default:
+				ret.Err = errors.New("gemini: no response of the request in the batch")
+			}
+			results = append(results, ret)
+		}
+	}
+	status.EndedRequests = len(results)
+	return status, util.BatchResults(results, p.n, errors.New("gemini: batch "+string(resp.State))), nil
+}
+
+// rpcStatuses maps google.rpc.Code to its name, i.e. the status of errors.
+var rpcStatuses = map[int32]string{
+	3:  "INVALID_ARGUMENT",
+	4:  "DEADLINE_EXCEEDED",
+	5:  "NOT_FOUND",
+	7:  "PERMISSION_DENIED",
+	8:  "RESOURCE_EXHAUSTED",
+	9:  "FAILED_PRECONDITION",
+	11: "OUT_OF_RANGE",
+	13: "INTERNAL",
+	14: "UNAVAILABLE",
+	16: "UNAUTHENTICATED",
+}
+
+func jobError(e *genai.JobError) error {
+	var kind error
+	status := "UNKNOWN"
+	if e.Code != nil {
+		if s, ok := rpcStatuses[*e.Code]; ok {
+			status, kind = s, errorKinds[s]
+		} else {
+			status = strconv.Itoa(int(*e.Code))
+		}
+	}
+	return util.NewError(kind, 0, 0, errors.New(status+": "+e.Message))
+}
+
+// -----------------------------------------------------------------------------
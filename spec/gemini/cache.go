@@ -0,0 +1,61 @@
+/*
+ * Copyright (c) 2026 The XGo Authors (xgo.dev). All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package gemini
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/goplus/xai"
+	"google.golang.org/genai"
+)
+
+// -----------------------------------------------------------------------------
+
+// MarshalGenParams implements xai.GenCodec.
+func (p *Service) MarshalGenParams(gp xai.GenParams) ([]byte, error) {
+	_, model, contents, config := buildGenParams(context.Background(), gp)
+	conf := *config
+	conf.HTTPOptions = nil // BaseURL, Timeout, etc. don't change the response
+	return json.Marshal(struct {
+		Model    string                       `json:"model"`
+		Contents []*genai.Content             `json:"contents"`
+		Config   *genai.GenerateContentConfig `json:"config"`
+	}{model, contents, &conf})
+}
+
+// MarshalGenResponse implements xai.GenCodec.
+func (p *Service) MarshalGenResponse(resp xai.GenResponse) ([]byte, error) {
+	r, ok := resp.(response)
+	if !ok {
+		return nil, fmt.Errorf("gemini: unexpected response type %T", resp)
+	}
+	ret := *r.GenerateContentResponse
+	ret.SDKHTTPResponse = nil
+	return json.Marshal(&ret)
+}
+
+// UnmarshalGenResponse implements xai.GenCodec.
+func (p *Service) UnmarshalGenResponse(data []byte) (xai.GenResponse, error) {
+	ret := new(genai.GenerateContentResponse)
+	if err := json.Unmarshal(data, ret); err != nil {
+		return nil, err
+	}
+	return response{ret}, nil
+}
+
+// -----------------------------------------------------------------------------
@@ -0,0 +1,62 @@
+/*
+ * Copyright (c) 2026 The XGo Authors (xgo.dev). All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package gemini
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"google.golang.org/genai"
+)
+
+// -----------------------------------------------------------------------------
+
+func TestGenCodec(t *testing.T) {
+	svc := &Service{tools: make(tools)}
+	newParams := func() *genParams {
+		return svc.GenParams().Model("gemini-pro").Temperature(0.5).
+			Messages(svc.UserMsg().Text("hi")).(*genParams)
+	}
+	k1, err := svc.MarshalGenParams(newParams())
+	if err != nil {
+		t.Fatal("MarshalGenParams:", err)
+	}
+	k2, _ := svc.MarshalGenParams(newParams().Timeout(time.Second))
+	k3, _ := svc.MarshalGenParams(newParams().Temperature(0.6))
+	if !bytes.Equal(k1, k2) || bytes.Equal(k1, k3) {
+		t.Fatalf("MarshalGenParams: %s, %s, %s", k1, k2, k3)
+	}
+
+	resp := chunkOf(genai.NewPartFromText("hello"), &genai.Part{FunctionCall: &genai.FunctionCall{ID: "1", Name: "f"}})
+	data, err := svc.MarshalGenResponse(response{resp})
+	if err != nil {
+		t.Fatal("MarshalGenResponse:", err)
+	}
+	ret, err := svc.UnmarshalGenResponse(data)
+	if err != nil {
+		t.Fatal("UnmarshalGenResponse:", err)
+	}
+	c := ret.At(0)
+	if use, ok := c.Part(1).AsToolUse(); c.Parts() != 2 || c.Part(0).Text() != "hello" || !ok || use.Name != "f" {
+		t.Fatal("UnmarshalGenResponse: unexpected response", string(data))
+	}
+	if c.ToMsg() == nil {
+		t.Fatal("ToMsg: nil")
+	}
+}
+
+// -----------------------------------------------------------------------------
@@ -35,6 +35,7 @@ func (p *imageData) ImageType() xai.ImageType {
 }
 
 type imageBuilder struct {
+	vertex bool
 }
 
 func (p imageBuilder) From(mime xai.ImageType, displayName string, src io.Reader) (xai.ImageData, error) {
@@ -54,6 +55,9 @@ func (p imageBuilder) FromLocal(mime xai.ImageType, fileName string) (xai.ImageD
 }
 
 func (p imageBuilder) FromBytes(mime xai.ImageType, displayName string, data []byte) xai.ImageData {
+	if !p.vertex {
+		displayName = ""
+	}
 	return &imageData{
 		Data:        data,
 		DisplayName: displayName,
@@ -66,15 +70,11 @@ func (p imageBuilder) FromBase64(mime xai.ImageType, displayName string, data st
 	if err != nil {
 		return nil, err
 	}
-	return &imageData{
-		Data:        b,
-		DisplayName: displayName,
-		MIMEType:    string(mime),
-	}, nil
+	return p.FromBytes(mime, displayName, b), nil
 }
 
 func (p *Service) Images() xai.ImageBuilder {
-	return imageBuilder{}
+	return imageBuilder{p.vertex}
 }
 
 // -----------------------------------------------------------------------------
@@ -89,6 +89,7 @@ func (p *docData) DocumentType() xai.DocumentType {
 }
 
 type docBuilder struct {
+	vertex bool
 }
 
 func (p docBuilder) From(mime xai.DocumentType, displayName string, src io.Reader) (xai.DocumentData, error) {
@@ -116,6 +117,9 @@ func (p docBuilder) FromBase64(mime xai.DocumentType, displayName string, data s
 }
 
 func (p docBuilder) FromBytes(mime xai.DocumentType, displayName string, data []byte) xai.DocumentData {
+	if !p.vertex {
+		displayName = ""
+	}
 	return &docData{
 		InlineData: &genai.Blob{
 			Data:        data,
@@ -130,7 +134,7 @@ func (p docBuilder) PlainText(text string) xai.DocumentData {
 }
 
 func (p *Service) Docs() xai.DocumentBuilder {
-	return docBuilder{}
+	return docBuilder{p.vertex}
 }
 
 // -----------------------------------------------------------------------------
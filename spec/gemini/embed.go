@@ -0,0 +1,57 @@
+/*
+ * Copyright (c) 2026 The XGo Authors (xgo.dev). All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package gemini
+
+import (
+	"context"
+
+	"github.com/goplus/xai"
+	"google.golang.org/genai"
+)
+
+// -----------------------------------------------------------------------------
+
+func (p *Service) Embed(ctx context.Context, params xai.EmbedParams) (*xai.Embeddings, error) {
+	contents := make([]*genai.Content, 0, len(params.Texts)+len(params.Images))
+	for _, text := range params.Texts {
+		contents = append(contents, genai.NewContentFromText(text, genai.RoleUser))
+	}
+	for _, img := range params.Images {
+		contents = append(contents, &genai.Content{
+			Parts: []*genai.Part{{InlineData: (*genai.Blob)(img.(*imageData))}},
+			Role:  genai.RoleUser,
+		})
+	}
+	config := &genai.EmbedContentConfig{TaskType: string(params.Task)}
+	if params.Dimensions > 0 {
+		config.OutputDimensionality = genai.Ptr(int32(params.Dimensions))
+	}
+	resp, err := p.models.EmbedContent(ctx, string(params.Model), contents, config)
+	if err != nil {
+		return nil, translateError(err)
+	}
+	ret := &xai.Embeddings{Vectors: make([][]float32, len(resp.Embeddings))}
+	for i, e := range resp.Embeddings {
+		ret.Vectors[i] = e.Values
+		if e.Statistics != nil {
+			ret.Usage.InputTokens += int64(e.Statistics.TokenCount)
+		}
+	}
+	return ret, nil
+}
+
+// -----------------------------------------------------------------------------
@@ -0,0 +1,104 @@
+/*
+ * Copyright (c) 2026 The XGo Authors (xgo.dev). All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package gemini
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/goplus/xai"
+	"github.com/goplus/xai/util"
+	"google.golang.org/genai"
+)
+
+// -----------------------------------------------------------------------------
+
+// errorKinds maps the `error.status` (google.rpc.Code) of a gemini error to xai
+// error kinds. Statuses that are not listed here are classified by the HTTP
+// status code.
+var errorKinds = map[string]error{
+	"INVALID_ARGUMENT":    xai.ErrInvalidRequest,
+	"FAILED_PRECONDITION": xai.ErrInvalidRequest,
+	"OUT_OF_RANGE":        xai.ErrInvalidRequest,
+	"UNAUTHENTICATED":     xai.ErrAuth,
+	"PERMISSION_DENIED":   xai.ErrAuth,
+	"NOT_FOUND":           xai.ErrNotFound,
+	"RESOURCE_EXHAUSTED":  xai.ErrRateLimited,
+	"UNAVAILABLE":         xai.ErrOverloaded,
+	"INTERNAL":            xai.ErrServer,
+	"DEADLINE_EXCEEDED":   xai.ErrServer,
+}
+
+// translateError converts a genai error into an *xai.Error. Errors that don't
+// come from the API (e.g. context canceled) are returned unchanged.
+func translateError(err error) error {
+	var e genai.APIError
+	if !errors.As(err, &e) {
+		return err
+	}
+	kind := errorKinds[e.Status]
+	if kind == xai.ErrInvalidRequest && isContextWindowExceeded(e.Message) {
+		kind = xai.ErrContextWindowExceeded
+	}
+	return util.NewError(kind, e.Code, retryDelay(e.Details), err)
+}
+
+func isContextWindowExceeded(msg string) bool {
+	return strings.Contains(msg, "exceeds the maximum number of tokens") ||
+		strings.Contains(msg, "input token count")
+}
+
+// retryDelay returns the delay specified by a google.rpc.RetryInfo detail.
+func retryDelay(details []map[string]any) time.Duration {
+	for _, detail := range details {
+		if typ, _ := detail["@type"].(string); strings.HasSuffix(typ, "google.rpc.RetryInfo") {
+			if v, ok := detail["retryDelay"].(string); ok {
+				if d, err := time.ParseDuration(v); err == nil {
+					return d
+				}
+			}
+		}
+	}
+	return 0
+}
+
+// -----------------------------------------------------------------------------
+
+// blockedError is the error of a response whose prompt is blocked. Such a
+// response is returned with HTTP 200 and has no candidates.
+type blockedError struct {
+	*genai.GenerateContentResponsePromptFeedback
+}
+
+func (e blockedError) Error() string {
+	if msg := e.BlockReasonMessage; msg != "" {
+		return "prompt blocked: " + msg
+	}
+	return "prompt blocked: " + string(e.BlockReason)
+}
+
+// checkBlocked returns an ErrContentFiltered error if the prompt of resp is
+// blocked by the safety filters.
+func checkBlocked(resp *genai.GenerateContentResponse) error {
+	if fb := resp.PromptFeedback; fb != nil && fb.BlockReason != "" && len(resp.Candidates) == 0 {
+		return util.NewError(xai.ErrContentFiltered, 0, 0, blockedError{fb})
+	}
+	return nil
+}
+
+// -----------------------------------------------------------------------------
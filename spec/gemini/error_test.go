@@ -0,0 +1,90 @@
+/*
+ * Copyright (c) 2026 The XGo Authors (xgo.dev). All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package gemini
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/goplus/xai"
+)
+
+// -----------------------------------------------------------------------------
+
+// genWith sends a request to a service of the URI parameters query, whose server
+// is served by handler.
+func genWith(t *testing.T, query string, handler http.HandlerFunc) error {
+	srv := httptest.NewServer(handler)
+	defer srv.Close()
+	svc, err := New(context.Background(), "gemini:key=test&base="+srv.URL+"/&"+query)
+	if err != nil {
+		t.Fatal("New:", err)
+	}
+	_, err = svc.Gen(context.Background(), svc.GenParams().Model("m").Messages(svc.UserMsg().Text("hi")))
+	return err
+}
+
+func TestTranslateError(t *testing.T) {
+	const retryInfo = `[{"@type":"type.googleapis.com/google.rpc.RetryInfo","retryDelay":"37s"}]`
+	cases := []struct {
+		code        int
+		status, msg string
+		details     string
+		kind        error
+		retryAfter  time.Duration
+	}{
+		{400, "INVALID_ARGUMENT", "* GenerateContentRequest.contents: contents is not specified", "[]", xai.ErrInvalidRequest, 0},
+		{400, "INVALID_ARGUMENT", "The input token count (1200000) exceeds the maximum number of tokens allowed (1048576).",
+			"[]", xai.ErrContextWindowExceeded, 0},
+		{400, "FAILED_PRECONDITION", "User location is not supported for the API use.", "[]", xai.ErrInvalidRequest, 0},
+		{403, "PERMISSION_DENIED", "Method doesn't allow unregistered callers.", "[]", xai.ErrAuth, 0},
+		{404, "NOT_FOUND", "models/m is not found for API version v1beta.", "[]", xai.ErrNotFound, 0},
+		{429, "RESOURCE_EXHAUSTED", "You exceeded your current quota.", retryInfo, xai.ErrRateLimited, 37 * time.Second},
+		{500, "INTERNAL", "An internal error has occurred.", "[]", xai.ErrServer, 0},
+		{503, "UNAVAILABLE", "The model is overloaded. Please try again later.", "[]", xai.ErrOverloaded, 0},
+		{504, "DEADLINE_EXCEEDED", "Deadline expired before operation could complete.", "[]", xai.ErrServer, 0},
+	}
+	for _, c := range cases {
+		err := genWith(t, "retries=0", func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(c.code)
+			fmt.Fprintf(w, `{"error":{"code":%d,"message":%q,"status":%q,"details":%s}}`, c.code, c.msg, c.status, c.details)
+		})
+		var e *xai.Error
+		if !errors.As(err, &e) || !errors.Is(err, c.kind) || e.StatusCode != c.code || e.RetryAfter != c.retryAfter {
+			t.Errorf("%s: unexpected %v", c.status, err)
+		}
+	}
+}
+
+func TestBlocked(t *testing.T) {
+	// blocked prompts are returned with HTTP 200 and no candidates
+	err := genWith(t, "retries=0", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"promptFeedback":{"blockReason":"SAFETY"},"usageMetadata":{"promptTokenCount":2}}`)
+	})
+	if !errors.Is(err, xai.ErrContentFiltered) || err.Error() != "content filtered: prompt blocked: SAFETY" {
+		t.Fatal("Gen: unexpected", err)
+	}
+}
+
+// -----------------------------------------------------------------------------
@@ -0,0 +1,99 @@
+/*
+ * Copyright (c) 2026 The XGo Authors (xgo.dev). All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package gemini
+
+import (
+	"context"
+	"io"
+	"iter"
+
+	"github.com/goplus/xai"
+	"google.golang.org/genai"
+)
+
+// -----------------------------------------------------------------------------
+
+// files implements xai.Files by the Files API of Gemini, which is unavailable
+// with Vertex AI. Files expire after 48 hours, so ExpiresIn of UploadParams is
+// ignored.
+//
+// IDs of files are their URIs, which are passed to `MsgBuilder.ImageFile` and
+// `MsgBuilder.DocFile` as is.
+type files struct {
+	svc genai.Files
+}
+
+func (p files) Upload(ctx context.Context, src io.Reader, params xai.UploadParams) (*xai.File, error) {
+	ret, err := p.svc.Upload(ctx, src, &genai.UploadFileConfig{
+		MIMEType:    params.MIME,
+		DisplayName: params.Name,
+	})
+	if err != nil {
+		return nil, translateError(err)
+	}
+	return fileOf(ret), nil
+}
+
+func (p files) Get(ctx context.Context, id string) (*xai.File, error) {
+	ret, err := p.svc.Get(ctx, id, nil)
+	if err != nil {
+		return nil, translateError(err)
+	}
+	return fileOf(ret), nil
+}
+
+func (p files) List(ctx context.Context) iter.Seq2[*xai.File, error] {
+	return func(yield func(*xai.File, error) bool) {
+		for f, err := range p.svc.All(ctx) {
+			if err != nil {
+				yield(nil, translateError(err))
+				return
+			}
+			if !yield(fileOf(f), nil) {
+				return
+			}
+		}
+	}
+}
+
+func (p files) Delete(ctx context.Context, id string) error {
+	_, err := p.svc.Delete(ctx, id, nil)
+	return translateError(err)
+}
+
+func fileOf(f *genai.File) *xai.File {
+	ret := &xai.File{
+		ID:        f.URI,
+		Name:      f.DisplayName,
+		MIME:      f.MIMEType,
+		CreatedAt: f.CreateTime,
+		ExpiresAt: f.ExpirationTime,
+	}
+	if f.SizeBytes != nil {
+		ret.Size = *f.SizeBytes
+	}
+	return ret
+}
+
+func (p *Service) Files() xai.Files {
+	if p.vertex {
+		return nil
+	}
+	return files{p.files}
+}
+
+// -----------------------------------------------------------------------------
@@ -18,6 +18,7 @@ package gemini
 
 import (
 	"context"
+	"encoding/json"
 	"iter"
 	"net/url"
 	"reflect"
@@ -25,38 +26,85 @@ import (
 	"time"
 
 	"github.com/goplus/xai"
+	"github.com/goplus/xai/util"
 	"google.golang.org/genai"
 )
 
 // -----------------------------------------------------------------------------
 
 type Service struct {
-	models genai.Models
-	ops    genai.Operations
-	tools  tools
+	models  genai.Models
+	ops     genai.Operations
+	files   genai.Files
+	batches genai.Batches
+	caches  genai.Caches
+	cache   promptCache
+	tools   tools
+	vertex  bool // the Gemini API backend doesn't support display names of blobs
+}
+
+// Provider returns the provider of the service, i.e. Scheme.
+func (p *Service) Provider() string {
+	return Scheme
 }
 
 func (p *Service) Features() xai.Feature {
-	return xai.FeatureGen | xai.FeatureGenStream | xai.FeatureOperation
+	ret := xai.FeatureGen | xai.FeatureGenStream | xai.FeatureOperation | xai.FeatureCountTokens |
+		xai.FeatureEmbed
+	if !p.vertex {
+		// the Files API and inlined batches are only available with the Gemini API
+		ret |= xai.FeatureFiles | xai.FeatureBatch
+	}
+	return ret
 }
 
 func (p *Service) Gen(ctx context.Context, params xai.GenParams) (xai.GenResponse, error) {
-	model, contents, config := buildGenParams(params)
+	ctx, model, contents, config := buildGenParams(ctx, params)
+	contents, config = p.useCache(ctx, params, model, contents, config)
 	resp, err := p.models.GenerateContent(ctx, model, contents, config)
 	if err != nil {
-		return nil, err // TODO(xsw): translate error
+		return nil, translateError(err)
+	}
+	if err = checkBlocked(resp); err != nil {
+		return nil, err
 	}
 	return response{resp}, nil
 }
 
-func (p *Service) GenStream(ctx context.Context, params xai.GenParams) iter.Seq2[xai.GenResponse, error] {
-	model, contents, config := buildGenParams(params)
-	iter := p.models.GenerateContentStream(ctx, model, contents, config)
-	return func(yield func(xai.GenResponse, error) bool) {
-		iter(func(resp *genai.GenerateContentResponse, err error) bool {
-			return yield(response{resp}, err)
-		})
+func (p *Service) GenStream(ctx context.Context, params xai.GenParams) iter.Seq2[xai.StreamEvent, error] {
+	ctx, model, contents, config := buildGenParams(ctx, params)
+	contents, config = p.useCache(ctx, params, model, contents, config)
+	return buildRespIter(p.models.GenerateContentStream(ctx, model, contents, config))
+}
+
+// CountTokens counts the input tokens of the request. As the Gemini API doesn't
+// count the system prompt and tools, they are folded into the counted contents
+// on its backend: the system prompt as a user message, and the tools as a user
+// message of their JSON, so that the count of the tools is an estimate.
+func (p *Service) CountTokens(ctx context.Context, params xai.GenParams) (int64, error) {
+	ctx, model, contents, config := buildGenParams(ctx, params)
+	conf := &genai.CountTokensConfig{HTTPOptions: config.HTTPOptions}
+	if p.vertex {
+		conf.SystemInstruction, conf.Tools = config.SystemInstruction, config.Tools
+	} else {
+		var prefix []*genai.Content
+		if sys := config.SystemInstruction; sys != nil {
+			prefix = append(prefix, &genai.Content{Role: genai.RoleUser, Parts: sys.Parts})
+		}
+		if len(config.Tools) > 0 {
+			b, err := json.Marshal(config.Tools)
+			if err != nil {
+				return 0, err
+			}
+			prefix = append(prefix, genai.NewContentFromText(string(b), genai.RoleUser))
+		}
+		contents = append(prefix, contents...)
+	}
+	resp, err := p.models.CountTokens(ctx, model, contents, conf)
+	if err != nil {
+		return 0, translateError(err)
 	}
+	return int64(resp.TotalTokens), nil
 }
 
 // -----------------------------------------------------------------------------
@@ -69,7 +117,9 @@ const (
 // uri should be in the format of "gemini:base=service_base_url&key=api_key".
 //
 // `base` is the base URL of the API endpoint.
-// `timeout` is the request timeout duration (e.g., "30s").
+// `timeout` is the timeout of each attempt of requests (e.g., "30s").
+// `retries` is the max number of retries on transient errors (default 2).
+// `retry_max_wait` is the max wait duration before a retry (e.g., "30s").
 // `key` is the API key for authentication for Gemini backend.
 // `project` is the project ID for Vertex AI backend.
 // `location` is the location for Vertex AI backend.
@@ -88,12 +138,19 @@ func New(ctx context.Context, uri string) (xai.Service, error) {
 	if key := params["key"]; len(key) > 0 {
 		conf.APIKey = key[0]
 	}
-	if timeout := params["timeout"]; len(timeout) > 0 {
-		d, err := time.ParseDuration(timeout[0])
-		if err != nil {
+	var timeout time.Duration
+	if v := params["timeout"]; len(v) > 0 {
+		if timeout, err = time.ParseDuration(v[0]); err != nil {
 			return nil, err
 		}
-		conf.HTTPOptions.Timeout = &d
+	}
+	retry, err := util.ParseRetryPolicy(params)
+	if err != nil {
+		return nil, err
+	}
+	if client := xai.HTTPClient(ctx); client != nil {
+		c := *client // don't modify the client, see the retry transport below
+		conf.HTTPClient = &c
 	}
 	if project := params["project"]; len(project) > 0 {
 		conf.Project = project[0]
@@ -106,10 +163,18 @@ func New(ctx context.Context, uri string) (xai.Service, error) {
 	if err != nil {
 		return nil, err
 	}
+	// genai doesn't retry requests, so we do it in the transport of its client,
+	// which also applies the timeout to each attempt.
+	hc := cli.ClientConfig().HTTPClient
+	hc.Transport = &util.RetryTransport{Base: hc.Transport, Policy: retry, Timeout: timeout}
 	return &Service{
-		models: *cli.Models,
-		ops:    *cli.Operations,
-		tools:  make(tools),
+		models:  *cli.Models,
+		ops:     *cli.Operations,
+		files:   *cli.Files,
+		batches: *cli.Batches,
+		caches:  *cli.Caches,
+		tools:   make(tools),
+		vertex:  conf.Backend == genai.BackendVertexAI,
 	}, nil
 }
 
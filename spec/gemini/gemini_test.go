@@ -1,6 +1,3 @@
-//go:build xaitest
-// +build xaitest
-
 /*
  * Copyright (c) 2026 The XGo Authors (xgo.dev). All rights reserved.
  *
@@ -21,12 +18,46 @@ package gemini
 
 import (
 	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"github.com/goplus/xai/xaitest"
 )
 
 func TestGemini(t *testing.T) {
-	// TODO(xsw): implement test cases for gemini.
-	xaitest.Do(t, context.Background(), "gemini:xxx")
+	srv := xaitest.NewGeminiServer()
+	defer srv.Close()
+	xaitest.Do(t, context.Background(), "gemini:base="+srv.URL+"&key=test", "gemini-2.5-flash",
+		"imagen-4.0-generate-001")
+}
+
+func TestCountTokens(t *testing.T) {
+	var body string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		body = string(b)
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"totalTokens":42}`)
+	}))
+	defer srv.Close()
+
+	ctx := context.Background()
+	svc, err := New(ctx, "gemini:key=test&retries=0&base="+srv.URL+"/")
+	if err != nil {
+		t.Fatal("New:", err)
+	}
+	tool := svc.ToolDef("get_weather").Description("gets the weather")
+	params := svc.GenParams().Model("m").System("be brief").Tools(tool).Messages(svc.UserMsg().Text("hi"))
+	n, err := svc.CountTokens(ctx, params)
+	if err != nil || n != 42 {
+		t.Fatal("CountTokens:", n, err)
+	}
+	// the Gemini API counts the system prompt and tools as contents
+	if !strings.Contains(body, "be brief") || !strings.Contains(body, "get_weather") || strings.Contains(body, "systemInstruction") {
+		t.Fatal("CountTokens: unexpected request", body)
+	}
 }
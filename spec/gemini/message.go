@@ -17,6 +17,7 @@
 package gemini
 
 import (
+	"time"
 	"unsafe"
 
 	"github.com/goplus/xai"
@@ -26,8 +27,10 @@ import (
 // -----------------------------------------------------------------------------
 
 type msgBuilder struct {
-	content []*genai.Part
-	role    string
+	content  []*genai.Part
+	role     string
+	cached   bool // the message has a cache breakpoint
+	cacheTTL time.Duration
 }
 
 func buildMessages(msgs []xai.MsgBuilder) []*genai.Content {
@@ -109,8 +112,21 @@ func (p *msgBuilder) Thinking(v xai.Thinking) xai.MsgBuilder {
 	return p
 }
 
+// DocCitations is a no-op, as gemini doesn't cite documents. Responses grounded
+// by tools, e.g. Google Search, are cited by their grounding metadata instead.
+func (p *msgBuilder) DocCitations() xai.MsgBuilder {
+	return p
+}
+
 func (p *msgBuilder) Compaction(data string) xai.MsgBuilder {
 	panic("gemini does not support compaction")
 }
 
+// CacheBreakpoint caches the prompt up to the whole message, as cached content
+// of gemini consists of whole messages.
+func (p *msgBuilder) CacheBreakpoint(ttl time.Duration) xai.MsgBuilder {
+	p.cached, p.cacheTTL = true, max(p.cacheTTL, ttl)
+	return p
+}
+
 // -----------------------------------------------------------------------------
@@ -0,0 +1,72 @@
+/*
+ * Copyright (c) 2026 The XGo Authors (xgo.dev). All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package gemini
+
+import (
+	"context"
+	"strings"
+
+	"github.com/goplus/xai"
+	"github.com/goplus/xai/util"
+)
+
+// -----------------------------------------------------------------------------
+
+var (
+	inputsText   = []xai.Modality{xai.ModalityText}
+	inputsImage  = []xai.Modality{xai.ModalityText, xai.ModalityImage}
+	inputsAll    = []xai.Modality{xai.ModalityText, xai.ModalityImage, xai.ModalityDocument, xai.ModalityAudio, xai.ModalityVideo}
+	outputsText  = []xai.Modality{xai.ModalityText}
+	outputsImage = []xai.Modality{xai.ModalityImage}
+)
+
+// knownModels completes the models listed by the API, which provides their token
+// limits and whether they support thinking.
+var knownModels = util.ModelTable{
+	{ID: "gemini-*-image*", InputModalities: inputsImage, OutputModalities: []xai.Modality{xai.ModalityText, xai.ModalityImage}},
+	{ID: "gemini-embedding-*", InputModalities: inputsText},
+	{ID: "gemini-2.5-*", ContextWindow: 1048576, MaxOutputTokens: 65536, InputModalities: inputsAll, OutputModalities: outputsText, Tools: true, Thinking: true, StructuredOutput: true},
+	{ID: "gemini-*", InputModalities: inputsAll, OutputModalities: outputsText, Tools: true, StructuredOutput: true},
+	{ID: "text-embedding-*", InputModalities: inputsText},
+	{ID: "imagen-*-capability-*", InputModalities: inputsImage, OutputModalities: outputsImage, Actions: []xai.Action{xai.EditImage}},
+	{ID: "imagen-*-upscale-*", InputModalities: inputsImage, OutputModalities: outputsImage, Actions: []xai.Action{xai.UpscaleImage}},
+	{ID: "imagen-product-recontext-*", InputModalities: inputsImage, OutputModalities: outputsImage, Actions: []xai.Action{xai.RecontextImage}},
+	{ID: "imagen-*", InputModalities: inputsText, OutputModalities: outputsImage, Actions: []xai.Action{xai.GenImage, xai.UpscaleImage}},
+	{ID: "image-segmentation-*", InputModalities: inputsImage, OutputModalities: outputsImage, Actions: []xai.Action{xai.SegmentImage}},
+	{ID: "veo-*", InputModalities: inputsImage, OutputModalities: []xai.Modality{xai.ModalityVideo}, Actions: []xai.Action{xai.GenVideo}},
+}
+
+func (p *Service) Models(ctx context.Context) (ret []*xai.ModelInfo, err error) {
+	for m, err := range p.models.All(ctx) {
+		if err != nil {
+			return nil, translateError(err)
+		}
+		// names are "models/{model}" with the Gemini API, and
+		// "publishers/{publisher}/models/{model}" with Vertex AI.
+		name := m.Name[strings.LastIndexByte(m.Name, '/')+1:]
+		ret = append(ret, knownModels.Complete(&xai.ModelInfo{
+			ID:              xai.Model(name),
+			DisplayName:     m.DisplayName,
+			ContextWindow:   int64(m.InputTokenLimit),
+			MaxOutputTokens: int64(m.OutputTokenLimit),
+			Thinking:        m.Thinking,
+		}))
+	}
+	return
+}
+
+// -----------------------------------------------------------------------------
@@ -75,7 +75,7 @@ func (p *genVideoResp) Results() xai.Results {
 }
 
 func (p *genVideoResp) WaitParams() xai.WaitParams {
-	return newWaitParams(p.gen.opts)
+	return newWaitParams(p.gen.opts, p.gen.timeout)
 }
 
 func (p *genVideoResp) Sleep() {
@@ -91,9 +91,11 @@ func (p *genVideoResp) Retry(ctx context.Context, wp xai.WaitParams) (*genVideoR
 			HTTPOptions: params.opts,
 		}
 	}
+	// polls are retried by the retry policy of the call
+	ctx = util.WithTimeout(util.WithRetryPolicy(ctx, gen.retry), params.timeout)
 	op, err := gen.svc.ops.GetVideosOperation(ctx, p.op, conf)
 	if err != nil {
-		return nil, err
+		return nil, translateError(err)
 	}
 	return &genVideoResp{op: op, gen: p.gen}, nil
 }
@@ -135,12 +137,13 @@ func (p *genVideo) CallParams() xai.CallParams {
 
 func (p *genVideo) Call(ctx context.Context, cp xai.CallParams) (resp xai.OperationResponse, err error) {
 	params := cp.(*callParams)
+	ctx = util.WithTimeout(util.WithRetryPolicy(ctx, params.retry), params.timeout)
 	if params.opts != nil {
 		p.HTTPOptions = params.opts
 	}
 	op, err := p.svc.models.GenerateVideosFromSource(ctx, p.model, &p.GenerateVideosSource, &p.GenerateVideosConfig)
 	if err != nil {
-		return
+		return nil, translateError(err)
 	}
 	return &genVideoResp{op: op, gen: p}, nil
 }
@@ -166,12 +169,13 @@ func (p *genImage) CallParams() xai.CallParams {
 
 func (p *genImage) Call(ctx context.Context, params xai.CallParams) (resp xai.OperationResponse, err error) {
 	cp := params.(*callParams)
+	ctx = util.WithTimeout(util.WithRetryPolicy(ctx, cp.retry), cp.timeout)
 	if cp.opts != nil {
 		p.HTTPOptions = cp.opts
 	}
 	op, err := p.svc.models.GenerateImages(ctx, p.model, p.Prompt, &p.GenerateImagesConfig)
 	if err != nil {
-		return
+		return nil, translateError(err)
 	}
 	return util.NewImageResultsResp[*genai.GeneratedImage, adapter](op, op.GeneratedImages), nil
 }
@@ -198,12 +202,13 @@ func (p *editImage) CallParams() xai.CallParams {
 
 func (p *editImage) Call(ctx context.Context, cp xai.CallParams) (resp xai.OperationResponse, err error) {
 	params := cp.(*callParams)
+	ctx = util.WithTimeout(util.WithRetryPolicy(ctx, params.retry), params.timeout)
 	if params.opts != nil {
 		p.HTTPOptions = params.opts
 	}
 	op, err := p.svc.models.EditImage(ctx, p.model, p.Prompt, p.References, &p.EditImageConfig)
 	if err != nil {
-		return
+		return nil, translateError(err)
 	}
 	return util.NewImageResultsResp[*genai.GeneratedImage, adapter](op, op.GeneratedImages), nil
 }
@@ -229,12 +234,13 @@ func (p *recontextImage) CallParams() xai.CallParams {
 
 func (p *recontextImage) Call(ctx context.Context, cp xai.CallParams) (resp xai.OperationResponse, err error) {
 	params := cp.(*callParams)
+	ctx = util.WithTimeout(util.WithRetryPolicy(ctx, params.retry), params.timeout)
 	if params.opts != nil {
 		p.HTTPOptions = params.opts
 	}
 	op, err := p.svc.models.RecontextImage(ctx, p.model, &p.RecontextImageSource, &p.RecontextImageConfig)
 	if err != nil {
-		return
+		return nil, translateError(err)
 	}
 	return util.NewImageResultsResp[*genai.GeneratedImage, adapter](op, op.GeneratedImages), nil
 }
@@ -261,12 +267,13 @@ func (p *upscaleImage) CallParams() xai.CallParams {
 
 func (p *upscaleImage) Call(ctx context.Context, cp xai.CallParams) (resp xai.OperationResponse, err error) {
 	params := cp.(*callParams)
+	ctx = util.WithTimeout(util.WithRetryPolicy(ctx, params.retry), params.timeout)
 	if params.opts != nil {
 		p.HTTPOptions = params.opts
 	}
 	op, err := p.svc.models.UpscaleImage(ctx, p.model, p.Image, p.Factor, &p.UpscaleImageConfig)
 	if err != nil {
-		return
+		return nil, translateError(err)
 	}
 	return util.NewImageResultsResp[*genai.GeneratedImage, adapter](op, op.GeneratedImages), nil
 }
@@ -292,12 +299,13 @@ func (p *segmentImage) CallParams() xai.CallParams {
 
 func (p *segmentImage) Call(ctx context.Context, cp xai.CallParams) (resp xai.OperationResponse, err error) {
 	params := cp.(*callParams)
+	ctx = util.WithTimeout(util.WithRetryPolicy(ctx, params.retry), params.timeout)
 	if params.opts != nil {
 		p.HTTPOptions = params.opts
 	}
 	op, err := p.svc.models.SegmentImage(ctx, p.model, &p.SegmentImageSource, &p.SegmentImageConfig)
 	if err != nil {
-		return
+		return nil, translateError(err)
 	}
 	return util.NewImageMaskResultsResp[*genai.GeneratedImageMask, adapter](op, op.GeneratedMasks), nil
 }
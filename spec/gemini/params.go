@@ -17,6 +17,8 @@
 package gemini
 
 import (
+	"context"
+	"strings"
 	"time"
 
 	"github.com/goplus/xai"
@@ -31,6 +33,16 @@ type genParams struct {
 	contents []*genai.Content
 	config   genai.GenerateContentConfig
 	pconfig  *util.Params[adapter]
+	retry    *xai.RetryPolicy
+	timeout  time.Duration // timeout of each attempt
+	thinking *xai.ThinkingConfig
+
+	// the prompt up to contents[:cacheMsgs] is cached if cacheSys is set or
+	// cacheMsgs > 0, see cachedContent.
+	cacheSys  bool
+	sysTTL    time.Duration
+	cacheMsgs int
+	msgsTTL   time.Duration
 }
 
 /*
@@ -141,6 +153,12 @@ func (p *genParams) System(texts ...string) xai.GenParams {
 
 func (p *genParams) Messages(msgs ...xai.MsgBuilder) xai.GenParams {
 	p.contents = buildMessages(msgs)
+	p.cacheMsgs, p.msgsTTL = 0, 0
+	for i, msg := range msgs {
+		if m := msg.(*msgBuilder); m.cached {
+			p.cacheMsgs, p.msgsTTL = i+1, max(p.msgsTTL, m.cacheTTL)
+		}
+	}
 	return p
 }
 
@@ -149,6 +167,32 @@ func (p *genParams) Tools(tools ...xai.ToolBase) xai.GenParams {
 	return p
 }
 
+func (p *genParams) ToolChoice(choice xai.ToolChoice) xai.GenParams {
+	conf := &genai.FunctionCallingConfig{}
+	switch choice.Mode {
+	case xai.ToolChoiceAny:
+		conf.Mode = genai.FunctionCallingConfigModeAny
+	case xai.ToolChoiceNone:
+		conf.Mode = genai.FunctionCallingConfigModeNone
+	case xai.ToolChoiceTool:
+		conf.Mode = genai.FunctionCallingConfigModeAny
+		conf.AllowedFunctionNames = []string{choice.Name}
+	default:
+		conf.Mode = genai.FunctionCallingConfigModeAuto
+	}
+	if p.config.ToolConfig == nil {
+		p.config.ToolConfig = &genai.ToolConfig{}
+	}
+	p.config.ToolConfig.FunctionCallingConfig = conf
+	return p
+}
+
+func (p *genParams) ParallelToolCalls(enabled bool) xai.GenParams {
+	// gemini does not support disabling parallel function calls, so we just
+	// ignore this parameter for now.
+	return p
+}
+
 func (p *genParams) Model(model xai.Model) xai.GenParams {
 	p.model = string(model) // TODO(xsw): validate model
 	return p
@@ -164,6 +208,20 @@ func (p *genParams) Compact(maxInputTokens int64) xai.GenParams {
 	return p
 }
 
+// CacheSystem caches the system prompt along with the tools, as cached content
+// of gemini includes both of them.
+func (p *genParams) CacheSystem(ttl time.Duration) xai.GenParams {
+	p.cacheSys, p.sysTTL = true, max(p.sysTTL, ttl)
+	return p
+}
+
+// CacheTools caches the tools along with the system prompt, as cached content
+// of gemini includes both of them.
+func (p *genParams) CacheTools(ttl time.Duration) xai.GenParams {
+	p.cacheSys, p.sysTTL = true, max(p.sysTTL, ttl)
+	return p
+}
+
 func (p *genParams) Temperature(v float64) xai.GenParams {
 	p.config.Temperature = genai.Ptr(float32(v))
 	return p
@@ -174,6 +232,71 @@ func (p *genParams) TopP(v float64) xai.GenParams {
 	return p
 }
 
+// Thinking sets the thinking config, which is resolved by the model when the
+// request is sent, see thinkingConfig.
+func (p *genParams) Thinking(conf xai.ThinkingConfig) xai.GenParams {
+	p.thinking = &conf
+	return p
+}
+
+// thinkingConfig returns the thinking config of conf for model:
+//   - Gemini 3 and later models take thinking levels. Efforts map to levels, and
+//     disabling thinking maps to the lowest level, as they can't disable it, i.e.
+//     LOW for pro models and MINIMAL for others.
+//   - Gemini 2.5 and older models only take thinking budgets. Efforts map to
+//     budgets by `ThinkingConfig.BudgetTokens`, and disabling thinking maps to a
+//     zero budget, or the min budget 128 of pro models, which can't disable it.
+//
+// Budgets are passed as is to both of them.
+func thinkingConfig(model string, conf xai.ThinkingConfig) *genai.ThinkingConfig {
+	ret := &genai.ThinkingConfig{IncludeThoughts: conf.IncludeSummary}
+	name := strings.TrimPrefix(model, "models/")
+	pro := strings.Contains(name, "-pro")
+	if strings.HasPrefix(name, "gemini-1") || strings.HasPrefix(name, "gemini-2") {
+		switch {
+		case conf.Disabled() && pro:
+			ret.ThinkingBudget = genai.Ptr[int32](128)
+		case conf.Disabled():
+			ret.ThinkingBudget = genai.Ptr[int32](0)
+		case conf.Effort != "" || conf.Budget > 0:
+			ret.ThinkingBudget = genai.Ptr(int32(conf.BudgetTokens()))
+		}
+		return ret
+	}
+	switch {
+	case conf.Disabled() && pro:
+		ret.ThinkingLevel = genai.ThinkingLevelLow
+	case conf.Disabled():
+		ret.ThinkingLevel = genai.ThinkingLevelMinimal
+	case conf.Effort != "":
+		ret.ThinkingLevel = thinkingLevels[conf.Effort]
+	case conf.Budget > 0:
+		ret.ThinkingBudget = genai.Ptr(int32(conf.Budget))
+	}
+	return ret
+}
+
+var thinkingLevels = map[xai.ReasoningEffort]genai.ThinkingLevel{
+	xai.EffortLow:    genai.ThinkingLevelLow,
+	xai.EffortMedium: genai.ThinkingLevelMedium,
+	xai.EffortHigh:   genai.ThinkingLevelHigh,
+}
+
+func (p *genParams) ResponseFormat(format xai.ResponseFormat) xai.GenParams {
+	switch format.Type {
+	case xai.FormatJSONSchema:
+		p.config.ResponseMIMEType = "application/json"
+		p.config.ResponseJsonSchema = format.Schema
+	case xai.FormatJSON:
+		p.config.ResponseMIMEType = "application/json"
+		p.config.ResponseJsonSchema = nil
+	default:
+		p.config.ResponseMIMEType = ""
+		p.config.ResponseJsonSchema = nil
+	}
+	return p
+}
+
 func (p *genParams) BaseURL(base string) xai.GenParams {
 	if p.config.HTTPOptions == nil {
 		p.config.HTTPOptions = &genai.HTTPOptions{}
@@ -182,11 +305,15 @@ func (p *genParams) BaseURL(base string) xai.GenParams {
 	return p
 }
 
+// Timeout sets the timeout of each attempt of the request, as requests are
+// retried by util.RetryTransport rather than genai.
 func (p *genParams) Timeout(timeout time.Duration) xai.GenParams {
-	if p.config.HTTPOptions == nil {
-		p.config.HTTPOptions = &genai.HTTPOptions{}
-	}
-	p.config.HTTPOptions.Timeout = &timeout
+	p.timeout = timeout
+	return p
+}
+
+func (p *genParams) Retry(policy xai.RetryPolicy) xai.GenParams {
+	p.retry = &policy
 	return p
 }
 
@@ -194,9 +321,15 @@ func (p *Service) GenParams() xai.GenParams {
 	return &genParams{}
 }
 
-func buildGenParams(in xai.GenParams) (string, []*genai.Content, *genai.GenerateContentConfig) {
+func buildGenParams(ctx context.Context, in xai.GenParams) (context.Context, string, []*genai.Content, *genai.GenerateContentConfig) {
 	p := in.(*genParams)
-	return p.model, p.contents, &p.config
+	config := &p.config
+	if p.thinking != nil {
+		conf := *config
+		conf.ThinkingConfig = thinkingConfig(p.model, *p.thinking)
+		config = &conf
+	}
+	return util.WithTimeout(util.WithRetryPolicy(ctx, p.retry), p.timeout), p.model, p.contents, config
 }
 
 // -----------------------------------------------------------------------------
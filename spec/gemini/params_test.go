@@ -0,0 +1,110 @@
+/*
+ * Copyright (c) 2026 The XGo Authors (xgo.dev). All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package gemini
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/goplus/xai"
+)
+
+// -----------------------------------------------------------------------------
+
+func TestThinking(t *testing.T) {
+	svc := &Service{}
+	cases := []struct {
+		model string
+		conf  xai.ThinkingConfig
+		want  string
+	}{
+		{"gemini-3-pro-preview", xai.ThinkingBudget(2048, false), "budget 2048, level , thoughts false"},
+		{"gemini-3-pro-preview", xai.ThinkingEffort(xai.EffortMedium, true), "budget <nil>, level MEDIUM, thoughts true"},
+		{"gemini-3-pro-preview", xai.NoThinking(), "budget <nil>, level LOW, thoughts false"},
+		{"gemini-3-flash-preview", xai.NoThinking(), "budget <nil>, level MINIMAL, thoughts false"},
+		{"gemini-3-flash-preview", xai.ThinkingConfig{IncludeSummary: true}, "budget <nil>, level , thoughts true"},
+		{"gemini-2.5-flash", xai.ThinkingBudget(2048, false), "budget 2048, level , thoughts false"},
+		{"gemini-2.5-flash", xai.ThinkingEffort(xai.EffortMedium, true), "budget 8192, level , thoughts true"},
+		{"gemini-2.5-flash", xai.NoThinking(), "budget 0, level , thoughts false"},
+		{"models/gemini-2.5-pro", xai.NoThinking(), "budget 128, level , thoughts false"},
+		{"gemini-2.5-pro", xai.ThinkingConfig{IncludeSummary: true}, "budget <nil>, level , thoughts true"},
+	}
+	for _, c := range cases {
+		params := svc.GenParams().Model(xai.Model(c.model)).Thinking(c.conf)
+		_, _, _, config := buildGenParams(context.Background(), params)
+		conf := config.ThinkingConfig
+		var budget any
+		if conf.ThinkingBudget != nil {
+			budget = *conf.ThinkingBudget
+		}
+		got := fmt.Sprintf("budget %v, level %s, thoughts %v", budget, conf.ThinkingLevel, conf.IncludeThoughts)
+		if got != c.want {
+			t.Errorf("Thinking(%s, %+v): got %s, want %s", c.model, c.conf, got, c.want)
+		}
+	}
+}
+
+func TestToolChoice(t *testing.T) {
+	svc := &Service{}
+	cases := []struct {
+		choice xai.ToolChoice
+		want   string
+	}{
+		{xai.ToolChoice{}, "AUTO []"},
+		{xai.ToolChoice{Mode: xai.ToolChoiceAny}, "ANY []"},
+		{xai.ToolChoice{Mode: xai.ToolChoiceNone}, "NONE []"},
+		{xai.UseTool("f"), "ANY [f]"},
+	}
+	for _, c := range cases {
+		conf := svc.GenParams().ToolChoice(c.choice).(*genParams).config.ToolConfig.FunctionCallingConfig
+		if got := fmt.Sprint(conf.Mode, " ", conf.AllowedFunctionNames); got != c.want {
+			t.Errorf("ToolChoice(%+v): got %s, want %s", c.choice, got, c.want)
+		}
+	}
+
+	// gemini can't disable parallel function calls
+	if p := svc.GenParams().ParallelToolCalls(false).(*genParams); p.config.ToolConfig != nil {
+		t.Fatal("ParallelToolCalls: unexpected tool config", p.config.ToolConfig)
+	}
+}
+
+func TestTimeout(t *testing.T) {
+	// the first attempt times out, and the second one succeeds
+	var attempts atomic.Int32
+	err := genWith(t, "timeout=100ms&retries=1&retry_max_wait=1ms", func(w http.ResponseWriter, r *http.Request) {
+		io.Copy(io.Discard, r.Body)
+		if attempts.Add(1) == 1 {
+			select {
+			case <-r.Context().Done():
+			case <-time.After(time.Second):
+			}
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"candidates":[{"content":{"role":"model","parts":[{"text":"hi"}]},"finishReason":"STOP"}]}`)
+	})
+	if err != nil || attempts.Load() != 2 {
+		t.Fatal("Gen:", attempts.Load(), err)
+	}
+}
+
+// -----------------------------------------------------------------------------
@@ -0,0 +1,145 @@
+/*
+ * Copyright (c) 2026 The XGo Authors (xgo.dev). All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package gemini
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/goplus/xai"
+	"google.golang.org/genai"
+)
+
+// -----------------------------------------------------------------------------
+
+// promptCache maps prompt prefixes to their cached contents.
+type promptCache struct {
+	mu    sync.Mutex
+	items map[[sha256.Size]byte]cachedItem
+}
+
+// cachedItem is a cached content, or a failure to create it if name is "".
+type cachedItem struct {
+	name   string
+	expire time.Time
+}
+
+const (
+	// cacheMargin is the least remaining lifetime of a cached content to be
+	// reused, so that it doesn't expire while a request is in flight.
+	cacheMargin = 30 * time.Second
+
+	// cacheBackoff is the max time before retrying to create a cached content
+	// that failed to be created.
+	cacheBackoff = 5 * time.Minute
+)
+
+// get returns the name of the cached content of key. It returns "" and true if
+// the cached content failed to be created and shouldn't be retried yet.
+func (p *promptCache) get(key [sha256.Size]byte) (string, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	item, ok := p.items[key]
+	if !ok {
+		return "", false
+	}
+	if item.name == "" {
+		return "", time.Now().Before(item.expire)
+	}
+	if time.Until(item.expire) < cacheMargin {
+		return "", false
+	}
+	return item.name, true
+}
+
+func (p *promptCache) put(key [sha256.Size]byte, item cachedItem) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.items == nil {
+		p.items = make(map[[sha256.Size]byte]cachedItem)
+	}
+	now := time.Now()
+	for k, v := range p.items {
+		if v.expire.Before(now) {
+			delete(p.items, k)
+		}
+	}
+	p.items[key] = item
+}
+
+// useCache returns the contents and config of a request that uses the cached
+// content of the prompt prefix marked by cache breakpoints, i.e. the system
+// prompt, the tools and the messages up to the last breakpoint. The cached
+// content is created on first use and reused until it expires. contents and
+// config are returned as is if there are no breakpoints or the cached content
+// can't be created, e.g. the prefix is shorter than the minimum of the model.
+// Such failures are remembered for the TTL or cacheBackoff, whichever is less,
+// so that later requests of the prefix don't retry to create it in the meantime.
+func (p *Service) useCache(ctx context.Context, params xai.GenParams, model string, contents []*genai.Content, config *genai.GenerateContentConfig) ([]*genai.Content, *genai.GenerateContentConfig) {
+	gp := params.(*genParams)
+	// a request needs some contents besides the cached content, so the last
+	// message is never cached.
+	n := min(gp.cacheMsgs, len(contents)-1)
+	if len(contents) == 0 || !gp.cacheSys && n <= 0 {
+		return contents, config
+	}
+	prefix := contents[:n]
+	b, err := json.Marshal([]any{model, config.SystemInstruction, config.Tools, config.ToolConfig, prefix})
+	if err != nil {
+		return contents, config
+	}
+	key := sha256.Sum256(b)
+	name, ok := p.cache.get(key)
+	if ok && name == "" {
+		return contents, config
+	}
+	if !ok {
+		ttl := max(gp.sysTTL, gp.msgsTTL)
+		resp, err := p.caches.Create(ctx, model, &genai.CreateCachedContentConfig{
+			HTTPOptions:       config.HTTPOptions,
+			TTL:               ttl,
+			Contents:          prefix,
+			SystemInstruction: config.SystemInstruction,
+			Tools:             config.Tools,
+			ToolConfig:        config.ToolConfig,
+		})
+		if err != nil {
+			// failures of the request itself, e.g. canceled, aren't remembered
+			if ctx.Err() == nil {
+				backoff := cacheBackoff
+				if ttl > 0 {
+					backoff = min(backoff, ttl)
+				}
+				p.cache.put(key, cachedItem{expire: time.Now().Add(backoff)})
+			}
+			return contents, config
+		}
+		name = resp.Name
+		p.cache.put(key, cachedItem{name, resp.ExpireTime})
+	}
+	// the system prompt, tools and tool config are taken from the cached content,
+	// and they can't be specified along with it.
+	conf := *config
+	conf.CachedContent = name
+	conf.SystemInstruction, conf.Tools, conf.ToolConfig = nil, nil, nil
+	return contents[n:], &conf
+}
+
+// -----------------------------------------------------------------------------
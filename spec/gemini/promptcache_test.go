@@ -0,0 +1,68 @@
+/*
+ * Copyright (c) 2026 The XGo Authors (xgo.dev). All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package gemini
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// -----------------------------------------------------------------------------
+
+func TestCacheCreateFailed(t *testing.T) {
+	var creates, gens int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "application/json")
+		if strings.Contains(r.URL.Path, "cachedContents") {
+			creates++
+			w.WriteHeader(400)
+			fmt.Fprint(w, `{"error":{"code":400,"status":"INVALID_ARGUMENT","message":"Cached content is too small. total_token_count=10, min_total_token_count=1024"}}`)
+			return
+		}
+		// requests fall back to sending the whole prompt
+		if gens++; strings.Contains(string(body), "cachedContent") || !strings.Contains(string(body), "be brief") {
+			t.Errorf("request %d: %s", gens, body)
+		}
+		fmt.Fprint(w, `{"candidates":[{"content":{"role":"model","parts":[{"text":"hi"}]},"finishReason":"STOP"}]}`)
+	}))
+	defer srv.Close()
+
+	ctx := context.Background()
+	svc, err := New(ctx, "gemini:key=test&retries=0&base="+srv.URL+"/")
+	if err != nil {
+		t.Fatal("New:", err)
+	}
+	for range 3 {
+		params := svc.GenParams().Model("m").System("be brief").CacheSystem(time.Hour).
+			Messages(svc.UserMsg().Text("hi"))
+		if _, err = svc.Gen(ctx, params); err != nil {
+			t.Fatal("Gen:", err)
+		}
+	}
+	if creates != 1 || gens != 3 {
+		t.Fatal("creates:", creates, "gens:", gens)
+	}
+}
+
+// -----------------------------------------------------------------------------
@@ -17,6 +17,8 @@
 package gemini
 
 import (
+	"encoding/json"
+	"iter"
 	"unsafe"
 
 	"github.com/goplus/xai"
@@ -37,6 +39,19 @@ func (p response) At(i int) xai.Candidate {
 	return candidate{p.Candidates[i]}
 }
 
+func (p response) Usage() xai.Usage {
+	u := p.UsageMetadata
+	if u == nil {
+		return xai.Usage{}
+	}
+	return xai.Usage{
+		InputTokens:     int64(u.PromptTokenCount + u.ToolUsePromptTokenCount),
+		OutputTokens:    int64(u.CandidatesTokenCount + u.ThoughtsTokenCount),
+		CacheReadTokens: int64(u.CachedContentTokenCount),
+		ReasoningTokens: int64(u.ThoughtsTokenCount),
+	}
+}
+
 // -----------------------------------------------------------------------------
 
 type candidate struct {
@@ -86,7 +101,7 @@ func (p candidate) Parts() int {
 }
 
 func (p candidate) Part(i int) xai.Part {
-	return contentBlock{p.c.Content.Parts[i]}
+	return contentBlock{p.c.Content.Parts[i], p.c, i}
 }
 
 func buildPart(part xai.Part) *genai.Part {
@@ -105,6 +120,8 @@ func (p candidate) ToMsg() xai.MsgBuilder {
 
 type contentBlock struct {
 	content *genai.Part
+	c       *genai.Candidate // the candidate of the part, nil for parts of messages
+	i       int              // index of the part in the candidate
 }
 
 func (p contentBlock) AsThinking() (ret xai.Thinking, ok bool) {
@@ -129,7 +146,14 @@ func (p contentBlock) AsToolUse() (ret xai.ToolUse, ok bool) {
 }
 
 func (p contentBlock) AsToolResult() (ret xai.ToolResult, ok bool) {
-	panic("todo")
+	fn := p.content.FunctionResponse
+	if ok = fn != nil; ok {
+		ret.ID = fn.ID
+		ret.Name = fn.Name
+		ret.Result = fn.Response
+		ret.Underlying = fn
+	}
+	return
 }
 
 func (p contentBlock) AsBlob() (ret xai.Blob, ok bool) {
@@ -154,8 +178,175 @@ func (p contentBlock) Text() string {
 	return p.content.Text
 }
 
+// Citations returns the grounding supports of the grounding metadata of the
+// candidate that are segments of the part, whose indices are byte offsets.
+func (p contentBlock) Citations() (ret []xai.Citation) {
+	if p.c == nil || p.c.GroundingMetadata == nil {
+		return
+	}
+	meta := p.c.GroundingMetadata
+	for _, support := range meta.GroundingSupports {
+		seg := support.Segment
+		if seg == nil || int(seg.PartIndex) != p.i {
+			continue
+		}
+		for _, idx := range support.GroundingChunkIndices {
+			if int(idx) >= len(meta.GroundingChunks) {
+				continue
+			}
+			chunk := meta.GroundingChunks[idx]
+			c := xai.Citation{
+				Start: int(seg.StartIndex), End: int(seg.EndIndex), DocIndex: -1, Underlying: chunk,
+			}
+			switch {
+			case chunk.Web != nil:
+				c.URL, c.Title = chunk.Web.URI, chunk.Web.Title
+			case chunk.RetrievedContext != nil:
+				rc := chunk.RetrievedContext
+				c.URL, c.Title, c.CitedText = rc.URI, rc.Title, rc.Text
+			}
+			ret = append(ret, c)
+		}
+	}
+	return
+}
+
 func (p contentBlock) Underlying() any {
 	return p.content
 }
 
 // -----------------------------------------------------------------------------
+
+// buildRespIter converts a gemini stream into stream events. Gemini streams
+// partial responses rather than deltas, so consecutive text (or thought) parts are
+// merged into one part, and block events are synthesized when the kind of parts
+// changes. Block events are reported for the first candidate only.
+func buildRespIter(stream iter.Seq2[*genai.GenerateContentResponse, error]) iter.Seq2[xai.StreamEvent, error] {
+	return func(yield func(xai.StreamEvent, error) bool) {
+		var (
+			ret  *genai.GenerateContentResponse
+			open = -1 // index of the open block
+			typ  xai.BlockType
+		)
+		for chunk, err := range stream {
+			if err != nil {
+				yield(xai.StreamEvent{}, translateError(err))
+				return
+			}
+			if ret == nil {
+				ret = new(genai.GenerateContentResponse)
+				if !yield(xai.StreamEvent{Type: xai.EventStart, Underlying: chunk}, nil) {
+					return
+				}
+			}
+			candidates := ret.Candidates
+			*ret = *chunk
+			ret.Candidates = candidates
+			for i, c := range chunk.Candidates {
+				dst := mergeCandidate(ret, i, c)
+				if c.Content == nil {
+					continue
+				}
+				for _, part := range c.Content.Parts {
+					idx, kind, merged := appendPart(dst.Content, part)
+					if i != 0 {
+						continue
+					}
+					ev := xai.StreamEvent{Index: idx, Block: kind, Underlying: chunk}
+					if !merged {
+						if open >= 0 {
+							if !yield(xai.StreamEvent{Type: xai.EventBlockStop, Index: open, Block: typ, Underlying: chunk}, nil) {
+								return
+							}
+						}
+						open, typ = idx, kind
+						ev.Type = xai.EventBlockStart
+						if fn := part.FunctionCall; fn != nil {
+							ev.ToolUse = xai.ToolUse{ID: fn.ID, Name: fn.Name}
+						}
+						if !yield(ev, nil) {
+							return
+						}
+					}
+					ev.Type = xai.EventDelta
+					switch {
+					case part.FunctionCall != nil:
+						b, _ := json.Marshal(part.FunctionCall.Args)
+						ev.Delta = string(b)
+					case kind == xai.BlockOther:
+						continue
+					default:
+						ev.Delta = part.Text
+					}
+					if ev.Delta != "" && !yield(ev, nil) {
+						return
+					}
+				}
+			}
+		}
+		if ret == nil {
+			return // empty stream
+		}
+		if open >= 0 {
+			if !yield(xai.StreamEvent{Type: xai.EventBlockStop, Index: open, Block: typ}, nil) {
+				return
+			}
+		}
+		if err := checkBlocked(ret); err != nil {
+			yield(xai.StreamEvent{}, err)
+			return
+		}
+		yield(xai.StreamEvent{Type: xai.EventStop, Response: response{ret}}, nil)
+	}
+}
+
+// mergeCandidate merges the chunk candidate c into the i-th candidate of ret, and
+// returns the merged candidate. The parts of c are not merged yet.
+func mergeCandidate(ret *genai.GenerateContentResponse, i int, c *genai.Candidate) *genai.Candidate {
+	for len(ret.Candidates) <= i {
+		ret.Candidates = append(ret.Candidates, &genai.Candidate{Content: &genai.Content{Role: genai.RoleModel}})
+	}
+	dst := ret.Candidates[i]
+	content, grounding := dst.Content, dst.GroundingMetadata
+	*dst = *c
+	if dst.GroundingMetadata == nil {
+		dst.GroundingMetadata = grounding // sent by one of the chunks
+	}
+	if c.Content != nil && c.Content.Role != "" {
+		content.Role = c.Content.Role
+	}
+	dst.Content = content
+	return dst
+}
+
+// appendPart appends part to content, or merges it into the last part of content
+// if both are text or thought parts. It returns the index and the kind of the
+// part in content, and whether it was merged.
+func appendPart(content *genai.Content, part *genai.Part) (idx int, kind xai.BlockType, merged bool) {
+	kind = partBlock(part)
+	if n := len(content.Parts); n > 0 && (kind == xai.BlockText || kind == xai.BlockThinking) {
+		if last := content.Parts[n-1]; partBlock(last) == kind {
+			last.Text += part.Text
+			if len(part.ThoughtSignature) > 0 {
+				last.ThoughtSignature = part.ThoughtSignature
+			}
+			return n - 1, kind, true
+		}
+	}
+	cp := *part // don't modify the parts of chunks
+	content.Parts = append(content.Parts, &cp)
+	return len(content.Parts) - 1, kind, false
+}
+
+func partBlock(part *genai.Part) xai.BlockType {
+	switch {
+	case part.FunctionCall != nil:
+		return xai.BlockToolUse
+	case part.InlineData != nil || part.FileData != nil || part.ExecutableCode != nil ||
+		part.CodeExecutionResult != nil || part.FunctionResponse != nil:
+		return xai.BlockOther
+	case part.Thought:
+		return xai.BlockThinking
+	}
+	return xai.BlockText
+}
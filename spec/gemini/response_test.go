@@ -0,0 +1,146 @@
+/*
+ * Copyright (c) 2026 The XGo Authors (xgo.dev). All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package gemini
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/goplus/xai"
+	"google.golang.org/genai"
+)
+
+// -----------------------------------------------------------------------------
+
+func chunkOf(parts ...*genai.Part) *genai.GenerateContentResponse {
+	return &genai.GenerateContentResponse{
+		Candidates: []*genai.Candidate{{Content: &genai.Content{Role: genai.RoleModel, Parts: parts}}},
+	}
+}
+
+func TestStreamEvents(t *testing.T) {
+	last := chunkOf(&genai.Part{FunctionCall: &genai.FunctionCall{ID: "1", Name: "f", Args: map[string]any{"a": 1}}})
+	last.Candidates[0].FinishReason = genai.FinishReasonStop
+	chunks := []*genai.GenerateContentResponse{
+		chunkOf(&genai.Part{Text: "Let me ", Thought: true}),
+		chunkOf(&genai.Part{Text: "think.", Thought: true, ThoughtSignature: []byte("sig")}),
+		chunkOf(genai.NewPartFromText("Hello, "), genai.NewPartFromText("world")),
+		last,
+	}
+	stream := buildRespIter(func(yield func(*genai.GenerateContentResponse, error) bool) {
+		for _, chunk := range chunks {
+			if !yield(chunk, nil) {
+				return
+			}
+		}
+	})
+
+	var events []string
+	var acc xai.Accumulator
+	for ev, err := range stream {
+		if err != nil {
+			t.Fatal("stream:", err)
+		}
+		acc.Add(ev)
+		events = append(events, fmt.Sprintf("%d:%d:%d:%s", ev.Type, ev.Index, ev.Block, ev.Delta))
+	}
+	const want = "0:0:0: 1:0:2: 2:0:2:Let me  2:0:2:think. 3:0:2: 1:1:1: 2:1:1:Hello,  2:1:1:world " +
+		"3:1:1: 1:2:3: 2:2:3:{\"a\":1} 3:2:3: 4:0:0:"
+	if got := strings.Join(events, " "); got != want {
+		t.Fatalf("events:\ngot  %s\nwant %s", got, want)
+	}
+	if acc.Text() != "Hello, world" || acc.Thinking() != "Let me think." {
+		t.Fatalf("Accumulator: text %q, thinking %q", acc.Text(), acc.Thinking())
+	}
+
+	c := acc.Response().At(0)
+	if c.Parts() != 3 || c.StopReason() != xai.EndTurn {
+		t.Fatalf("response: %d parts, stop reason %v", c.Parts(), c.StopReason())
+	}
+	if th, ok := c.Part(0).AsThinking(); !ok || th.Text != "Let me think." || th.Signature != "sig" {
+		t.Fatalf("thinking: %+v", th)
+	}
+	if use, ok := c.Part(2).AsToolUse(); !ok || use.Name != "f" {
+		t.Fatalf("tool use: %+v", use)
+	}
+	if chunks[0].Candidates[0].Content.Parts[0].Text != "Let me " {
+		t.Fatal("chunks are modified")
+	}
+}
+
+func TestTranscriptSignatures(t *testing.T) {
+	m := &msgBuilder{role: genai.RoleModel, content: []*genai.Part{
+		{Text: "think", Thought: true, ThoughtSignature: []byte("sig1")},
+		{Text: "Hello", ThoughtSignature: []byte("sig2")},
+		{FunctionCall: &genai.FunctionCall{Name: "f", Args: map[string]any{"a": 1.0}}, ThoughtSignature: []byte("sig3")},
+	}}
+	msg, err := m.ExportMsg()
+	if err != nil {
+		t.Fatal("ExportMsg:", err)
+	}
+	b, _ := json.Marshal(msg)
+	msg = new(xai.Message)
+	if err = json.Unmarshal(b, msg); err != nil {
+		t.Fatal("Unmarshal:", err)
+	}
+	parts := msg.Build(&Service{}).(*msgBuilder).content
+	if len(parts) != 3 || parts[2].FunctionCall == nil {
+		t.Fatal("Build:", string(b))
+	}
+	for i, part := range parts {
+		if want := fmt.Sprint("sig", i+1); string(part.ThoughtSignature) != want {
+			t.Fatalf("part %d: signature %q, want %q", i, part.ThoughtSignature, want)
+		}
+	}
+}
+
+func TestDisplayNames(t *testing.T) {
+	// the Gemini API backend rejects display names of inline data
+	for _, c := range []struct {
+		vertex   bool
+		img, doc string
+	}{
+		{false, "", ""},
+		{true, "a.png", "a.pdf"},
+	} {
+		svc := &Service{vertex: c.vertex}
+		img := svc.Images().FromBytes(xai.ImagePNG, "a.png", []byte("png")).(*imageData)
+		doc := svc.Docs().FromBytes(xai.DocPDF, "a.pdf", []byte("pdf")).(*docData)
+		if img.DisplayName != c.img || doc.InlineData.DisplayName != c.doc {
+			t.Errorf("vertex %v: display names %q, %q", c.vertex, img.DisplayName, doc.InlineData.DisplayName)
+		}
+	}
+}
+
+func TestUsage(t *testing.T) {
+	resp := &genai.GenerateContentResponse{UsageMetadata: &genai.GenerateContentResponseUsageMetadata{
+		PromptTokenCount: 100, ToolUsePromptTokenCount: 10, CachedContentTokenCount: 40,
+		CandidatesTokenCount: 30, ThoughtsTokenCount: 20,
+	}}
+	// output tokens of gemini exclude the thoughts, and input tokens the tool use prompt
+	want := xai.Usage{InputTokens: 110, OutputTokens: 50, CacheReadTokens: 40, ReasoningTokens: 20}
+	if got := (response{resp}).Usage(); got != want {
+		t.Fatalf("Usage: got %+v, want %+v", got, want)
+	}
+	if got := (response{&genai.GenerateContentResponse{}}).Usage(); got != (xai.Usage{}) {
+		t.Fatalf("Usage: got %+v without metadata", got)
+	}
+}
+
+// -----------------------------------------------------------------------------
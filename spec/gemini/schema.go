@@ -377,15 +377,17 @@ func newParams(params any) *util.Params[adapter] {
 // -----------------------------------------------------------------------------
 
 type callParams struct {
-	params util.Params[adapter]
-	opts   *genai.HTTPOptions
+	params  util.Params[adapter]
+	opts    *genai.HTTPOptions
+	retry   *xai.RetryPolicy
+	timeout time.Duration // timeout of each attempt
 }
 
 func (p *callParams) getWaitParams(wp xai.WaitParams) *waitParams {
 	if params, ok := wp.(*waitParams); ok {
 		return params
 	}
-	return &waitParams{opts: p.opts}
+	return newWaitParams(p.opts, p.timeout)
 }
 
 func (p *callParams) initCallParams(params any) xai.CallParams {
@@ -401,11 +403,15 @@ func (p *callParams) BaseURL(base string) xai.CallParams {
 	return p
 }
 
+// Timeout sets the timeout of each attempt of the call, as requests are retried
+// by util.RetryTransport rather than genai.
 func (p *callParams) Timeout(timeout time.Duration) xai.CallParams {
-	if p.opts == nil {
-		p.opts = &genai.HTTPOptions{}
-	}
-	p.opts.Timeout = &timeout
+	p.timeout = timeout
+	return p
+}
+
+func (p *callParams) Retry(policy xai.RetryPolicy) xai.CallParams {
+	p.retry = &policy
 	return p
 }
 
@@ -418,12 +424,14 @@ func (p *callParams) Set(name string, val any) xai.CallParams {
 
 type waitParams struct {
 	opts     *genai.HTTPOptions
+	timeout  time.Duration // timeout of each attempt of a poll
 	progress func(xai.OperationResponse)
 }
 
-func newWaitParams(opts *genai.HTTPOptions) *waitParams {
+func newWaitParams(opts *genai.HTTPOptions, timeout time.Duration) *waitParams {
 	return &waitParams{
-		opts: opts,
+		opts:    opts,
+		timeout: timeout,
 	}
 }
 
@@ -441,10 +449,7 @@ func (p *waitParams) BaseURL(base string) xai.WaitParams {
 }
 
 func (p *waitParams) Timeout(timeout time.Duration) xai.WaitParams {
-	if p.opts == nil {
-		p.opts = &genai.HTTPOptions{}
-	}
-	p.opts.Timeout = &timeout
+	p.timeout = timeout
 	return p
 }
 
@@ -17,6 +17,7 @@
 package gemini
 
 import (
+	"bytes"
 	"encoding/json"
 	"strings"
 
@@ -41,8 +42,16 @@ func (p tool) Description(desc string) xai.Tool {
 	return p
 }
 
+func (p tool) InputSchema(schema any) xai.Tool {
+	p.tool.ParametersJsonSchema = xai.ParseSchema(schema)
+	return p
+}
+
 func (p *Service) Tool(name string) xai.Tool {
-	return p.tools[name]
+	if t, ok := p.tools[name]; ok {
+		return t
+	}
+	return nil
 }
 
 func (p *Service) ToolDef(name string) xai.Tool {
@@ -57,7 +66,8 @@ func (p *Service) ToolDef(name string) xai.Tool {
 func buildTools(tools []xai.ToolBase) []*genai.Tool {
 	ret := make([]*genai.Tool, len(tools))
 	for i, v := range tools {
-		v.UnderlyingAssignTo(&ret[i])
+		ret[i] = new(genai.Tool)
+		v.UnderlyingAssignTo(ret[i])
 	}
 	return ret
 }
@@ -127,6 +137,30 @@ func dataConv(input any, errPrompt string) map[string]any {
 	return args
 }
 
+// resultConv converts a tool result to the response of a function call. Gemini
+// expects the response to be a JSON object, so non-object results are put in
+// the "output" field, as suggested by the Gemini API.
+func resultConv(result any) map[string]any {
+	if ret, ok := result.(map[string]any); ok {
+		return ret
+	}
+	b, ok := result.(json.RawMessage)
+	if !ok {
+		var err error
+		if b, err = json.Marshal(result); err != nil {
+			panic("invalid tool result: " + err.Error())
+		}
+	}
+	if trimmed := bytes.TrimSpace(b); len(trimmed) > 0 && trimmed[0] == '{' {
+		return dataConv(json.RawMessage(trimmed), "invalid tool result: ")
+	}
+	var output any
+	if err := json.Unmarshal(b, &output); err != nil {
+		panic("invalid tool result: " + err.Error())
+	}
+	return map[string]any{"output": output}
+}
+
 // -----------------------------------------------------------------------------
 
 func (p *msgBuilder) ToolResult(v xai.ToolResult) xai.MsgBuilder {
@@ -140,7 +174,7 @@ func (p *msgBuilder) ToolResult(v xai.ToolResult) xai.MsgBuilder {
 		if v.IsError {
 			ret = map[string]any{"error": v.Result.(error).Error()}
 		} else {
-			ret = dataConv(v.Result, "invalid tool result: ")
+			ret = resultConv(v.Result)
 		}
 		content = &genai.Part{
 			FunctionResponse: &genai.FunctionResponse{
@@ -0,0 +1,102 @@
+/*
+ * Copyright (c) 2026 The XGo Authors (xgo.dev). All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package gemini
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/goplus/xai"
+	"google.golang.org/genai"
+)
+
+// -----------------------------------------------------------------------------
+
+// ExportMsg exports the message to the transcript format.
+func (p *msgBuilder) ExportMsg() (*xai.Message, error) {
+	ret := &xai.Message{Role: xai.RoleUser, Parts: make([]xai.MessagePart, len(p.content))}
+	if p.role == genai.RoleModel {
+		ret.Role = xai.RoleAssistant
+	}
+	for i, part := range p.content {
+		v, err := exportPart(part)
+		if err != nil {
+			return nil, err
+		}
+		ret.Parts[i] = v
+	}
+	return ret, nil
+}
+
+func exportPart(part *genai.Part) (ret xai.MessagePart, err error) {
+	switch {
+	case part.Thought:
+		return xai.MessagePart{
+			Type: xai.PartThinking, Text: part.Text, Signature: string(part.ThoughtSignature), Provider: Scheme,
+		}, nil
+	case part.InlineData != nil:
+		v := part.InlineData
+		return xai.MessagePart{Type: blobType(v.MIMEType), MIME: v.MIMEType, Name: v.DisplayName, Data: v.Data}, nil
+	case part.FileData != nil:
+		v := part.FileData
+		return xai.MessagePart{Type: blobType(v.MIMEType), MIME: v.MIMEType, URL: v.FileURI}, nil
+	case part.FunctionCall != nil:
+		v := part.FunctionCall
+		input, err := json.Marshal(v.Args)
+		if err != nil {
+			return ret, err
+		}
+		return signPart(xai.MessagePart{Type: xai.PartToolUse, ID: v.ID, Name: v.Name, Input: input}, part), nil
+	case part.FunctionResponse != nil:
+		v := part.FunctionResponse
+		result, err := json.Marshal(v.Response)
+		if err != nil {
+			return ret, err
+		}
+		return xai.ToolResultPart(v.ID, v.Name, string(result), false), nil
+	case part.Text != "":
+		return signPart(xai.MessagePart{Type: xai.PartText, Text: part.Text}, part), nil
+	}
+	return ret, fmt.Errorf("gemini: part: %w", xai.ErrNotExportable)
+}
+
+// signPart exports the thought signature that gemini attaches to text and
+// function call parts when thinking is on.
+func signPart(ret xai.MessagePart, part *genai.Part) xai.MessagePart {
+	if len(part.ThoughtSignature) > 0 {
+		ret.Signature, ret.Provider = string(part.ThoughtSignature), Scheme
+	}
+	return ret
+}
+
+// SignPart sets the thought signature of the last part of the message. It is
+// used to import text and function call parts of transcripts.
+func (p *msgBuilder) SignPart(sig string) {
+	if n := len(p.content); n > 0 {
+		p.content[n-1].ThoughtSignature = []byte(sig)
+	}
+}
+
+func blobType(mime string) xai.PartType {
+	if strings.HasPrefix(mime, "image/") {
+		return xai.PartImage
+	}
+	return xai.PartDoc
+}
+
+// -----------------------------------------------------------------------------
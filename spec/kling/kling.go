@@ -24,6 +24,7 @@ import (
 
 	"github.com/goplus/xai"
 	"github.com/goplus/xai/geno"
+	"github.com/goplus/xai/util"
 	"golang.org/x/oauth2"
 )
 
@@ -38,11 +39,37 @@ func newGenImageResponse(c *geno.Client, body map[string]any, opts *geno.HTTPOpt
 type adapter struct{}
 
 func (adapter) Actions(model xai.Model) []xai.Action {
-	panic("todo")
+	return []xai.Action{xai.GenImage}
+}
+
+// knownModels lists the image generation models of kling.
+var knownModels = util.ModelTable{
+	{ID: "kling-v1", DisplayName: "Kling V1"},
+	{ID: "kling-v1-5", DisplayName: "Kling V1.5"},
+	{ID: "kling-v2", DisplayName: "Kling V2"},
+	{ID: "kling-v2-new", DisplayName: "Kling V2 New"},
+	{ID: "kling-v2-1", DisplayName: "Kling V2.1"},
+}
+
+func init() {
+	for i := range knownModels {
+		knownModels[i].InputModalities = []xai.Modality{xai.ModalityText, xai.ModalityImage}
+		knownModels[i].OutputModalities = []xai.Modality{xai.ModalityImage}
+		knownModels[i].Actions = []xai.Action{xai.GenImage}
+	}
+}
+
+func (adapter) Models() []*xai.ModelInfo {
+	return knownModels.Models()
 }
 
 func (adapter) InputSchema(action xai.Action) xai.InputSchema {
-	panic("todo")
+	switch action {
+	case xai.GenImage:
+		return schemaGenImage
+	default:
+		panic("unexpected action: " + action)
+	}
 }
 
 func (adapter) SetParam(body map[string]any, name string, val any) {
@@ -85,7 +112,8 @@ func (adapter) BuildQuery(action xai.Action, body map[string]any) (ret geno.Quer
 }
 
 func (adapter) Results(action xai.Action, body map[string]any) xai.Results {
-	result, _ := body["task_result"].(map[string]any)
+	data, _ := body["data"].(map[string]any)
+	result, _ := data["task_result"].(map[string]any)
 	switch action {
 	case xai.GenImage:
 		return geno.NewImageResults[adapter](result, "images")
@@ -160,6 +188,8 @@ const (
 //
 // `base` is the base URL of the API endpoint.
 // `timeout` is the request timeout duration (e.g., "30s").
+// `retries` is the max number of retries on transient errors (default 2).
+// `retry_max_wait` is the max wait duration before a retry (e.g., "30s").
 // `token` is the authentication token for accessing the service.
 //
 // For example, "kling:base=https://api-singapore.klingai.com/&token=your_token".
@@ -178,6 +208,9 @@ func New(ctx context.Context, uri string) (xai.Service, error) {
 		panic("token is required")
 	}
 
+	if client := xai.HTTPClient(ctx); client != nil {
+		ctx = context.WithValue(ctx, oauth2.HTTPClient, client)
+	}
 	svc := geno.NewService[adapter](oauth2.NewClient(ctx, src))
 	c := svc.HTTPClient()
 	if base := params["base"]; len(base) > 0 {
@@ -190,6 +223,11 @@ func New(ctx context.Context, uri string) (xai.Service, error) {
 		}
 		c.Timeout(d)
 	}
+	retry, err := util.ParseRetryPolicy(params)
+	if err != nil {
+		return nil, err
+	}
+	c.Retry(retry)
 	return svc, nil
 }
 
@@ -0,0 +1,47 @@
+/*
+ * Copyright (c) 2026 The XGo Authors (xgo.dev). All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package kling
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/goplus/xai"
+	"github.com/goplus/xai/xaitest"
+)
+
+func TestKling(t *testing.T) {
+	srv := xaitest.NewKlingServer()
+	defer srv.Close()
+	xaitest.Do(t, context.Background(), "kling:base="+srv.URL+"&token=test", "kling-v2")
+}
+
+func TestResults(t *testing.T) {
+	var body map[string]any
+	json.Unmarshal([]byte(`{"code": 0, "data": {
+		"task_id": "1", "task_status": "succeed",
+		"task_result": {"images": [{"index": 0, "url": "https://example.com/1.png"}]}
+	}}`), &body)
+	results := adapter{}.Results(xai.GenImage, body)
+	if results.Len() != 1 {
+		t.Fatal("Results:", results.Len())
+	}
+	if img := results.At(0).(*xai.OutputImage); img.StgUri() != "https://example.com/1.png" {
+		t.Fatal("Results:", img.StgUri())
+	}
+}
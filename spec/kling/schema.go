@@ -0,0 +1,57 @@
+/*
+ * Copyright (c) 2026 The XGo Authors (xgo.dev). All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package kling
+
+import (
+	"github.com/goplus/xai"
+	"github.com/goplus/xai/types"
+)
+
+// -----------------------------------------------------------------------------
+
+type inputSchema struct {
+	fields      []xai.Field
+	restriction map[string]*xai.Restriction
+}
+
+func (p *inputSchema) Fields() []xai.Field {
+	return p.fields
+}
+
+func (p *inputSchema) Restriction(name string) *xai.Restriction {
+	return p.restriction[name]
+}
+
+// schemaGenImage is the schema of POST /v1/images/generations, see klingai.json.
+var schemaGenImage = &inputSchema{
+	fields: []xai.Field{
+		{Name: "Prompt", Kind: types.String},
+		{Name: "NegativePrompt", Kind: types.String},
+		{Name: "Resolution", Kind: types.String},
+		{Name: "N", Kind: types.Int},
+		{Name: "AspectRatio", Kind: types.String},
+	},
+	restriction: map[string]*xai.Restriction{
+		"Prompt":     {Required: true},
+		"Resolution": {Limit: &xai.StringEnum{Values: []string{"1k", "2k"}}},
+		"AspectRatio": {Limit: &xai.StringEnum{Values: []string{
+			"16:9", "9:16", "1:1", "4:3", "3:4", "3:2", "2:3", "21:9",
+		}}},
+	},
+}
+
+// -----------------------------------------------------------------------------
@@ -0,0 +1,63 @@
+/*
+ * Copyright (c) 2026 The XGo Authors (xgo.dev). All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package mock
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/goplus/xai"
+	"github.com/goplus/xai/util"
+)
+
+// -----------------------------------------------------------------------------
+
+// SubmitBatch serves each request by the next scripted reply, as Gen does. The
+// batch ends at once, and a reply with Err fails its request only.
+func (p *Service) SubmitBatch(ctx context.Context, reqs []xai.GenParams) (xai.BatchResponse, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	results := make([]*xai.BatchResult, len(reqs))
+	for i, params := range reqs {
+		ret := &xai.BatchResult{CustomID: util.CustomID(i)}
+		if reply, err := p.next(params, false); err != nil {
+			ret.Err = err
+		} else {
+			ret.Response = response{reply}
+		}
+		results[i] = ret
+	}
+	p.mu.Lock()
+	p.nbatch++
+	id := "batch-" + strconv.Itoa(p.nbatch)
+	p.mu.Unlock()
+	status := util.BatchStatus{ID: id, Ended: true, EndedRequests: len(reqs), Requests: len(reqs)}
+	return util.NewBatch(status, batchResults{status, results}, 0), nil
+}
+
+// batchResults is the poller of a batch that has ended.
+type batchResults struct {
+	status  util.BatchStatus
+	results []*xai.BatchResult
+}
+
+func (p batchResults) Poll(ctx context.Context, opts *util.WaitOptions) (util.BatchStatus, []*xai.BatchResult, error) {
+	return p.status, p.results, nil
+}
+
+// -----------------------------------------------------------------------------
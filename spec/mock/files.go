@@ -0,0 +1,110 @@
+/*
+ * Copyright (c) 2026 The XGo Authors (xgo.dev). All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package mock
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"iter"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/goplus/xai"
+)
+
+// -----------------------------------------------------------------------------
+
+// files keeps the uploaded files in memory. Files expire by ExpiresIn of their
+// UploadParams.
+type files struct {
+	svc *Service
+}
+
+func (p files) Upload(ctx context.Context, src io.Reader, params xai.UploadParams) (*xai.File, error) {
+	data, err := io.ReadAll(src)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	f := &xai.File{Name: params.Name, MIME: params.MIME, Size: int64(len(data)), CreatedAt: now}
+	if params.ExpiresIn > 0 {
+		f.ExpiresAt = now.Add(params.ExpiresIn)
+	}
+	svc := p.svc
+	svc.mu.Lock()
+	defer svc.mu.Unlock()
+	svc.nfile++
+	f.ID = "file-" + strconv.Itoa(svc.nfile)
+	svc.files = append(svc.files, f)
+	ret := *f
+	return &ret, nil
+}
+
+// find returns the index of the unexpired file of id, or -1 if there is none.
+func (p files) find(id string) int {
+	now := time.Now()
+	return slices.IndexFunc(p.svc.files, func(f *xai.File) bool {
+		return f.ID == id && (f.ExpiresAt.IsZero() || now.Before(f.ExpiresAt))
+	})
+}
+
+func (p files) Get(ctx context.Context, id string) (*xai.File, error) {
+	p.svc.mu.Lock()
+	defer p.svc.mu.Unlock()
+	i := p.find(id)
+	if i < 0 {
+		return nil, fmt.Errorf("mock: file %q: %w", id, xai.ErrNotFound)
+	}
+	ret := *p.svc.files[i]
+	return &ret, nil
+}
+
+func (p files) List(ctx context.Context) iter.Seq2[*xai.File, error] {
+	return func(yield func(*xai.File, error) bool) {
+		p.svc.mu.Lock()
+		list := slices.Clone(p.svc.files)
+		p.svc.mu.Unlock()
+		now := time.Now()
+		for _, f := range list {
+			if f.ExpiresAt.IsZero() || now.Before(f.ExpiresAt) {
+				ret := *f
+				if !yield(&ret, nil) {
+					return
+				}
+			}
+		}
+	}
+}
+
+func (p files) Delete(ctx context.Context, id string) error {
+	p.svc.mu.Lock()
+	defer p.svc.mu.Unlock()
+	i := p.find(id)
+	if i < 0 {
+		return fmt.Errorf("mock: file %q: %w", id, xai.ErrNotFound)
+	}
+	p.svc.files = slices.Delete(p.svc.files, i, i+1)
+	return nil
+}
+
+func (p *Service) Files() xai.Files {
+	return files{p}
+}
+
+// -----------------------------------------------------------------------------
@@ -0,0 +1,395 @@
+/*
+ * Copyright (c) 2026 The XGo Authors (xgo.dev). All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package mock
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/goplus/xai"
+)
+
+// -----------------------------------------------------------------------------
+
+type partKind int
+
+const (
+	partText partKind = iota
+	partBlob
+	partRef
+	partThinking
+	partToolUse
+	partToolResult
+	partCompaction
+)
+
+// Ref is the Underlying of parts added by ImageURL, ImageFile, DocURL and DocFile
+// of a Msg.
+type Ref struct {
+	MIME   string
+	URL    string // set by ImageURL and DocURL
+	FileID string // set by ImageFile and DocFile
+}
+
+type part struct {
+	kind       partKind
+	text       string
+	blob       xai.Blob
+	ref        Ref
+	thinking   xai.Thinking
+	toolUse    xai.ToolUse
+	toolResult xai.ToolResult
+	citations  []xai.Citation
+}
+
+// Text creates a text part.
+func Text(text string) xai.Part {
+	return &part{kind: partText, text: text}
+}
+
+// CitedText creates a text part that cites the given sources.
+func CitedText(text string, citations ...xai.Citation) xai.Part {
+	return &part{kind: partText, text: text, citations: citations}
+}
+
+// Blob creates a blob part, e.g. an image or a document.
+func Blob(blob xai.Blob) xai.Part {
+	return &part{kind: partBlob, blob: blob}
+}
+
+// Thinking creates a thinking part.
+func Thinking(v xai.Thinking) xai.Part {
+	return &part{kind: partThinking, thinking: v}
+}
+
+// ToolUse creates a tool use part.
+func ToolUse(v xai.ToolUse) xai.Part {
+	return &part{kind: partToolUse, toolUse: v}
+}
+
+// ToolResult creates a tool result part.
+func ToolResult(v xai.ToolResult) xai.Part {
+	return &part{kind: partToolResult, toolResult: v}
+}
+
+// Compaction creates a compaction part.
+func Compaction(data string) xai.Part {
+	return &part{kind: partCompaction, text: data}
+}
+
+func (p *part) AsBlob() (ret xai.Blob, ok bool) {
+	return p.blob, p.kind == partBlob
+}
+
+func (p *part) AsThinking() (ret xai.Thinking, ok bool) {
+	return p.thinking, p.kind == partThinking
+}
+
+func (p *part) AsToolUse() (ret xai.ToolUse, ok bool) {
+	return p.toolUse, p.kind == partToolUse
+}
+
+func (p *part) AsToolResult() (ret xai.ToolResult, ok bool) {
+	return p.toolResult, p.kind == partToolResult
+}
+
+func (p *part) AsCompaction() (ret xai.Compaction, ok bool) {
+	if p.kind == partCompaction {
+		return xai.Compaction{Data: p.text}, true
+	}
+	return
+}
+
+func (p *part) Text() string {
+	if p.kind == partText {
+		return p.text
+	}
+	return ""
+}
+
+func (p *part) Citations() []xai.Citation {
+	return p.citations
+}
+
+func (p *part) Underlying() any {
+	if p.kind == partRef {
+		return p.ref
+	}
+	return nil
+}
+
+// -----------------------------------------------------------------------------
+
+const (
+	RoleUser      = "user"
+	RoleAssistant = "assistant"
+)
+
+// Msg is a message built by UserMsg, AssistantMsg or Candidate.ToMsg. It records
+// its content as parts, so that assertions can inspect them.
+type Msg struct {
+	Role  string
+	Parts []xai.Part
+
+	// Cache maps the number of parts before a cache breakpoint to its TTL.
+	Cache map[int]time.Duration
+
+	// Cited holds the indices of the document parts whose citations are enabled
+	// by DocCitations.
+	Cited []int
+}
+
+// Content returns the text of the text parts of the message, joined by "\n".
+func (p *Msg) Content() string {
+	var texts []string
+	for _, part := range p.Parts {
+		if text := part.Text(); text != "" {
+			texts = append(texts, text)
+		}
+	}
+	return strings.Join(texts, "\n")
+}
+
+func (p *Msg) add(part xai.Part) xai.MsgBuilder {
+	p.Parts = append(p.Parts, part)
+	return p
+}
+
+func (p *Msg) Text(text string) xai.MsgBuilder {
+	return p.add(Text(text))
+}
+
+func (p *Msg) Image(image xai.ImageData) xai.MsgBuilder {
+	return p.add(Blob(image.(*blob).Blob))
+}
+
+func (p *Msg) ImageURL(mime xai.ImageType, url string) xai.MsgBuilder {
+	return p.add(&part{kind: partRef, ref: Ref{MIME: string(mime), URL: url}})
+}
+
+func (p *Msg) ImageFile(mime xai.ImageType, fileID string) xai.MsgBuilder {
+	return p.add(&part{kind: partRef, ref: Ref{MIME: string(mime), FileID: fileID}})
+}
+
+func (p *Msg) Doc(doc xai.DocumentData) xai.MsgBuilder {
+	return p.add(Blob(doc.(*blob).Blob))
+}
+
+func (p *Msg) DocURL(mime xai.DocumentType, url string) xai.MsgBuilder {
+	return p.add(&part{kind: partRef, ref: Ref{MIME: string(mime), URL: url}})
+}
+
+func (p *Msg) DocFile(mime xai.DocumentType, fileID string) xai.MsgBuilder {
+	return p.add(&part{kind: partRef, ref: Ref{MIME: string(mime), FileID: fileID}})
+}
+
+func (p *Msg) DocCitations() xai.MsgBuilder {
+	if n := len(p.Parts); n > 0 {
+		if last, ok := p.Parts[n-1].(*part); ok && (last.kind == partBlob || last.kind == partRef) {
+			p.Cited = append(p.Cited, n-1)
+		}
+	}
+	return p
+}
+
+func (p *Msg) Part(part xai.Part) xai.MsgBuilder {
+	return p.add(part)
+}
+
+func (p *Msg) Thinking(v xai.Thinking) xai.MsgBuilder {
+	return p.add(Thinking(v))
+}
+
+func (p *Msg) ToolUse(v xai.ToolUse) xai.MsgBuilder {
+	return p.add(ToolUse(v))
+}
+
+func (p *Msg) ToolResult(v xai.ToolResult) xai.MsgBuilder {
+	return p.add(ToolResult(v))
+}
+
+func (p *Msg) Compaction(data string) xai.MsgBuilder {
+	return p.add(Compaction(data))
+}
+
+func (p *Msg) CacheBreakpoint(ttl time.Duration) xai.MsgBuilder {
+	if p.Cache == nil {
+		p.Cache = make(map[int]time.Duration)
+	}
+	p.Cache[len(p.Parts)] = ttl
+	return p
+}
+
+// ExportMsg exports the message to the transcript format.
+func (p *Msg) ExportMsg() (*xai.Message, error) {
+	ret := &xai.Message{Role: xai.Role(p.Role), Parts: make([]xai.MessagePart, len(p.Parts))}
+	for i, part := range p.Parts {
+		v, err := exportPart(part)
+		if err != nil {
+			return nil, err
+		}
+		ret.Parts[i] = v
+	}
+	return ret, nil
+}
+
+func exportPart(v xai.Part) (ret xai.MessagePart, err error) {
+	p, ok := v.(*part)
+	if !ok {
+		return ret, fmt.Errorf("mock: part of %T: %w", v, xai.ErrNotExportable)
+	}
+	switch p.kind {
+	case partText:
+		return xai.MessagePart{Type: xai.PartText, Text: p.text}, nil
+	case partBlob:
+		data, err := p.blob.Raw()
+		if err != nil {
+			return ret, err
+		}
+		typ := blobType(p.blob.MIME)
+		return xai.MessagePart{Type: typ, MIME: p.blob.MIME, Name: p.blob.DisplayName, Data: data}, nil
+	case partRef:
+		return xai.MessagePart{Type: blobType(p.ref.MIME), MIME: p.ref.MIME, URL: p.ref.URL, FileID: p.ref.FileID}, nil
+	case partThinking:
+		t := p.thinking
+		return xai.MessagePart{
+			Type: xai.PartThinking, Text: t.Text, Signature: t.Signature, Redacted: t.Redacted, Provider: Scheme,
+		}, nil
+	case partToolUse:
+		input, err := json.Marshal(p.toolUse.Input)
+		if err != nil {
+			return ret, err
+		}
+		return xai.MessagePart{Type: xai.PartToolUse, ID: p.toolUse.ID, Name: p.toolUse.Name, Input: input}, nil
+	case partToolResult:
+		r := p.toolResult
+		if e, ok := r.Result.(error); ok {
+			return xai.ToolResultPart(r.ID, r.Name, e.Error(), true), nil
+		}
+		result, err := json.Marshal(r.Result)
+		if err != nil {
+			return ret, err
+		}
+		return xai.ToolResultPart(r.ID, r.Name, string(result), r.IsError), nil
+	default: // partCompaction
+		return xai.MessagePart{Type: xai.PartCompaction, Text: p.text, Provider: Scheme}, nil
+	}
+}
+
+func blobType(mime string) xai.PartType {
+	if strings.HasPrefix(mime, "image/") {
+		return xai.PartImage
+	}
+	return xai.PartDoc
+}
+
+func (p *Service) UserMsg() xai.MsgBuilder {
+	return &Msg{Role: RoleUser}
+}
+
+func (p *Service) AssistantMsg() xai.MsgBuilder {
+	return &Msg{Role: RoleAssistant}
+}
+
+// -----------------------------------------------------------------------------
+
+// blob implements both xai.ImageData and xai.DocumentData.
+type blob struct {
+	xai.Blob
+}
+
+func (p *blob) ImageType() xai.ImageType {
+	return xai.ImageType(p.MIME)
+}
+
+func (p *blob) DocumentType() xai.DocumentType {
+	return xai.DocumentType(p.MIME)
+}
+
+func newBlob(mime, displayName string, data xai.BlobData) *blob {
+	return &blob{xai.Blob{BlobData: data, DisplayName: displayName, MIME: mime}}
+}
+
+type images struct{}
+
+func (images) From(mime xai.ImageType, displayName string, src io.Reader) (xai.ImageData, error) {
+	data, err := io.ReadAll(src)
+	if err != nil {
+		return nil, err
+	}
+	return newBlob(string(mime), displayName, xai.BlobFromRaw(data)), nil
+}
+
+func (images) FromLocal(mime xai.ImageType, fileName string) (xai.ImageData, error) {
+	data, err := os.ReadFile(fileName)
+	if err != nil {
+		return nil, err
+	}
+	return newBlob(string(mime), fileName, xai.BlobFromRaw(data)), nil
+}
+
+func (images) FromBase64(mime xai.ImageType, displayName string, base64 string) (xai.ImageData, error) {
+	return newBlob(string(mime), displayName, xai.BlobFromBase64(base64)), nil
+}
+
+func (images) FromBytes(mime xai.ImageType, displayName string, data []byte) xai.ImageData {
+	return newBlob(string(mime), displayName, xai.BlobFromRaw(data))
+}
+
+type docs struct{}
+
+func (docs) From(mime xai.DocumentType, displayName string, src io.Reader) (xai.DocumentData, error) {
+	data, err := io.ReadAll(src)
+	if err != nil {
+		return nil, err
+	}
+	return newBlob(string(mime), displayName, xai.BlobFromRaw(data)), nil
+}
+
+func (docs) FromLocal(mime xai.DocumentType, fileName string) (xai.DocumentData, error) {
+	data, err := os.ReadFile(fileName)
+	if err != nil {
+		return nil, err
+	}
+	return newBlob(string(mime), fileName, xai.BlobFromRaw(data)), nil
+}
+
+func (docs) FromBase64(mime xai.DocumentType, displayName string, base64 string) (xai.DocumentData, error) {
+	return newBlob(string(mime), displayName, xai.BlobFromBase64(base64)), nil
+}
+
+func (docs) FromBytes(mime xai.DocumentType, displayName string, data []byte) xai.DocumentData {
+	return newBlob(string(mime), displayName, xai.BlobFromRaw(data))
+}
+
+func (docs) PlainText(text string) xai.DocumentData {
+	return newBlob(string(xai.DocPlainText), "", xai.BlobFromRaw([]byte(text)))
+}
+
+func (p *Service) Images() xai.ImageBuilder {
+	return images{}
+}
+
+func (p *Service) Docs() xai.DocumentBuilder {
+	return docs{}
+}
+
+// -----------------------------------------------------------------------------
@@ -0,0 +1,413 @@
+/*
+ * Copyright (c) 2026 The XGo Authors (xgo.dev). All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package mock
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"hash/fnv"
+	"iter"
+	"strings"
+	"sync"
+
+	"github.com/goplus/xai"
+	"github.com/goplus/xai/geno"
+)
+
+// -----------------------------------------------------------------------------
+
+// ErrNoReply is returned when a Service is called without a scripted reply.
+var ErrNoReply = errors.New("mock: no scripted reply")
+
+// Candidate is a scripted candidate of a Reply.
+type Candidate struct {
+	Parts []xai.Part
+
+	// StopReason is the stop reason of the candidate. Defaults to xai.EndTurn.
+	StopReason xai.StopReason
+}
+
+// Reply is a scripted response of `Gen` and `GenStream`.
+type Reply struct {
+	Candidates []Candidate
+	Usage      xai.Usage
+
+	// Err, if not nil, is returned instead of the response.
+	Err error
+
+	// ChunkSize is the max number of runes of the deltas in GenStream. Zero
+	// means each block is streamed by a single delta.
+	ChunkSize int
+}
+
+// NewReply creates a Reply with a single candidate of the given parts.
+func NewReply(parts ...xai.Part) Reply {
+	return Reply{Candidates: []Candidate{{Parts: parts}}}
+}
+
+// Service is an in-process xai.Service that replies with scripted responses, and
+// records the requests it receives. It doesn't depend on any provider SDK, so it
+// is used to test code built on xai.
+//
+// It is safe for concurrent use.
+type Service struct {
+	geno.ServiceBase
+
+	mu       sync.Mutex
+	replies  []Reply
+	ops      map[xai.Action][]OpReply
+	tools    map[string]xai.Tool
+	requests []*Request
+	calls    []*OpRequest
+	count    func(req *Request) int64
+	embed    func(params xai.EmbedParams) (*xai.Embeddings, error)
+	models   []xai.ModelInfo
+	files    []*xai.File
+	nfile    int
+	nbatch   int
+}
+
+// NewService creates a Service without any scripted reply.
+func NewService() *Service {
+	return &Service{
+		ops:   make(map[xai.Action][]OpReply),
+		tools: make(map[string]xai.Tool),
+	}
+}
+
+// NewFunc returns a NewFunc that always returns p, to register p by xai.Register,
+// e.g. xai.Register("mock", svc.NewFunc()).
+func (p *Service) NewFunc() xai.NewFunc {
+	return func(ctx context.Context, uri string) (xai.Service, error) {
+		return p, nil
+	}
+}
+
+// Reply appends replies to the script of `Gen` and `GenStream`. Each call
+// consumes a reply in order.
+func (p *Service) Reply(replies ...Reply) *Service {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.replies = append(p.replies, replies...)
+	return p
+}
+
+// ReplyText appends replies with a single text part each.
+func (p *Service) ReplyText(texts ...string) *Service {
+	for _, text := range texts {
+		p.Reply(NewReply(Text(text)))
+	}
+	return p
+}
+
+// CountTokensBy sets the function that counts the input tokens of the requests
+// of `CountTokens`. By default, CountTokens counts the words of the system prompt
+// and text parts, and a token for each other part.
+func (p *Service) CountTokensBy(fn func(req *Request) int64) *Service {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.count = fn
+	return p
+}
+
+// EmbedBy sets the function that serves `Embed`. By default, Embed returns a
+// vector of params.Dimensions (or 8) dimensions for each input, derived from the
+// hash of the input, so that equal inputs have equal embeddings.
+func (p *Service) EmbedBy(fn func(params xai.EmbedParams) (*xai.Embeddings, error)) *Service {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.embed = fn
+	return p
+}
+
+// AddModels appends models to the list of `Models`, which is empty by default.
+func (p *Service) AddModels(models ...xai.ModelInfo) *Service {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.models = append(p.models, models...)
+	return p
+}
+
+// Requests returns the requests received by `Gen` and `GenStream`, in order.
+func (p *Service) Requests() []*Request {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]*Request(nil), p.requests...)
+}
+
+// LastRequest returns the last request received by `Gen` and `GenStream`, or nil
+// if there is none.
+func (p *Service) LastRequest() *Request {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if n := len(p.requests); n > 0 {
+		return p.requests[n-1]
+	}
+	return nil
+}
+
+// Provider returns the provider of the service, i.e. Scheme.
+func (p *Service) Provider() string {
+	return Scheme
+}
+
+func (p *Service) Features() xai.Feature {
+	return xai.FeatureGen | xai.FeatureGenStream | xai.FeatureOperation | xai.FeatureCountTokens | xai.FeatureEmbed |
+		xai.FeatureFiles | xai.FeatureBatch
+}
+
+// next records the request, and returns the next scripted reply.
+func (p *Service) next(params xai.GenParams, stream bool) (Reply, error) {
+	req := params.(*genParams).req
+	req.Messages = append([]*Msg(nil), req.Messages...)
+	req.Stream = stream
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.requests = append(p.requests, &req)
+	if len(p.replies) == 0 {
+		return Reply{}, ErrNoReply
+	}
+	ret := p.replies[0]
+	p.replies = p.replies[1:]
+	return ret, ret.Err
+}
+
+func (p *Service) Gen(ctx context.Context, params xai.GenParams) (xai.GenResponse, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	reply, err := p.next(params, false)
+	if err != nil {
+		return nil, err
+	}
+	return response{reply}, nil
+}
+
+func (p *Service) GenStream(ctx context.Context, params xai.GenParams) iter.Seq2[xai.StreamEvent, error] {
+	return func(yield func(xai.StreamEvent, error) bool) {
+		if err := ctx.Err(); err != nil {
+			yield(xai.StreamEvent{}, err)
+			return
+		}
+		reply, err := p.next(params, true)
+		if err != nil {
+			yield(xai.StreamEvent{}, err)
+			return
+		}
+		if !yield(xai.StreamEvent{Type: xai.EventStart}, nil) {
+			return
+		}
+		if len(reply.Candidates) > 0 {
+			for i, part := range reply.Candidates[0].Parts {
+				if !streamPart(yield, i, part, reply.ChunkSize) {
+					return
+				}
+			}
+		}
+		yield(xai.StreamEvent{Type: xai.EventStop, Response: response{reply}}, nil)
+	}
+}
+
+func (p *Service) CountTokens(ctx context.Context, params xai.GenParams) (int64, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	req := params.(*genParams).req
+	p.mu.Lock()
+	count := p.count
+	p.mu.Unlock()
+	if count == nil {
+		count = countTokens
+	}
+	return count(&req), nil
+}
+
+func countTokens(req *Request) (n int64) {
+	for _, s := range req.System {
+		n += int64(len(strings.Fields(s)))
+	}
+	for _, msg := range req.Messages {
+		for _, v := range msg.Parts {
+			if p, ok := v.(*part); ok && p.kind == partText {
+				n += int64(len(strings.Fields(p.text)))
+			} else {
+				n++
+			}
+		}
+	}
+	return
+}
+
+func (p *Service) Embed(ctx context.Context, params xai.EmbedParams) (*xai.Embeddings, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	p.mu.Lock()
+	embed := p.embed
+	p.mu.Unlock()
+	if embed != nil {
+		return embed(params)
+	}
+	dims := params.Dimensions
+	if dims <= 0 {
+		dims = 8
+	}
+	ret := new(xai.Embeddings)
+	for _, text := range params.Texts {
+		ret.Vectors = append(ret.Vectors, hashVector([]byte(text), dims))
+		ret.Usage.InputTokens += int64(len(strings.Fields(text)))
+	}
+	for _, img := range params.Images {
+		data, err := img.(*blob).Raw()
+		if err != nil {
+			return nil, err
+		}
+		ret.Vectors = append(ret.Vectors, hashVector(data, dims))
+		ret.Usage.InputTokens++
+	}
+	return ret, nil
+}
+
+func (p *Service) Models(ctx context.Context) ([]*xai.ModelInfo, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	ret := make([]*xai.ModelInfo, len(p.models))
+	for i := range p.models {
+		v := p.models[i]
+		ret[i] = &v
+	}
+	return ret, nil
+}
+
+// hashVector returns a vector of values in [-1, 1), derived from the hash of data.
+func hashVector(data []byte, dims int64) []float32 {
+	ret := make([]float32, dims)
+	h := fnv.New64a()
+	h.Write(data)
+	seed := h.Sum64()
+	for i := range ret {
+		seed = seed*6364136223846793005 + 1442695040888963407
+		ret[i] = float32(int32(seed>>32)) / (1 << 31)
+	}
+	return ret
+}
+
+func streamPart(yield func(xai.StreamEvent, error) bool, i int, part xai.Part, chunkSize int) bool {
+	ev := xai.StreamEvent{Type: xai.EventBlockStart, Index: i, Block: xai.BlockOther}
+	var content string
+	if use, ok := part.AsToolUse(); ok {
+		b, err := json.Marshal(use.Input)
+		if err != nil {
+			yield(xai.StreamEvent{}, err)
+			return false
+		}
+		ev.Block, ev.ToolUse, content = xai.BlockToolUse, xai.ToolUse{ID: use.ID, Name: use.Name}, string(b)
+	} else if th, ok := part.AsThinking(); ok {
+		ev.Block, content = xai.BlockThinking, th.Text
+	} else if text := part.Text(); text != "" {
+		ev.Block, content = xai.BlockText, text
+	}
+	if !yield(ev, nil) {
+		return false
+	}
+	for _, chunk := range chunksOf(content, chunkSize) {
+		delta := xai.StreamEvent{Type: xai.EventDelta, Index: i, Block: ev.Block, Delta: chunk}
+		if !yield(delta, nil) {
+			return false
+		}
+	}
+	return yield(xai.StreamEvent{Type: xai.EventBlockStop, Index: i, Block: ev.Block}, nil)
+}
+
+func chunksOf(s string, size int) (ret []string) {
+	if s == "" {
+		return nil
+	}
+	if size <= 0 {
+		return []string{s}
+	}
+	runes := []rune(s)
+	for len(runes) > size {
+		ret = append(ret, string(runes[:size]))
+		runes = runes[size:]
+	}
+	return append(ret, string(runes))
+}
+
+// -----------------------------------------------------------------------------
+
+type response struct {
+	reply Reply
+}
+
+func (p response) Len() int {
+	return len(p.reply.Candidates)
+}
+
+func (p response) At(i int) xai.Candidate {
+	return candidate{&p.reply.Candidates[i]}
+}
+
+func (p response) Usage() xai.Usage {
+	return p.reply.Usage
+}
+
+type candidate struct {
+	c *Candidate
+}
+
+func (p candidate) Parts() int {
+	return len(p.c.Parts)
+}
+
+func (p candidate) Part(i int) xai.Part {
+	return p.c.Parts[i]
+}
+
+func (p candidate) StopReason() xai.StopReason {
+	if p.c.StopReason == "" {
+		return xai.EndTurn
+	}
+	return p.c.StopReason
+}
+
+func (p candidate) ToMsg() xai.MsgBuilder {
+	return &Msg{Role: RoleAssistant, Parts: append([]xai.Part(nil), p.c.Parts...)}
+}
+
+// -----------------------------------------------------------------------------
+
+const (
+	Scheme = "mock"
+)
+
+// New creates a new Service instance without any scripted reply. uri should be
+// "mock:". To script the replies, create a Service by NewService and register
+// it by its NewFunc instead.
+func New(ctx context.Context, uri string) (xai.Service, error) {
+	return NewService(), nil
+}
+
+func init() {
+	xai.Register(Scheme, New)
+}
+
+// -----------------------------------------------------------------------------
@@ -0,0 +1,251 @@
+/*
+ * Copyright (c) 2026 The XGo Authors (xgo.dev). All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package mock
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/goplus/xai"
+)
+
+// -----------------------------------------------------------------------------
+
+func TestGen(t *testing.T) {
+	svc := NewService()
+	svc.Reply(Reply{
+		Candidates: []Candidate{{Parts: []xai.Part{
+			Thinking(xai.Thinking{Text: "hmm", Signature: "sig"}),
+			ToolUse(xai.ToolUse{ID: "1", Name: "add", Input: map[string]int{"a": 1}}),
+		}}},
+		Usage: xai.Usage{InputTokens: 10, OutputTokens: 5},
+	}).Reply(Reply{Candidates: []Candidate{{Parts: []xai.Part{Text("hello, world")}, StopReason: xai.StopMaxTokens}}, ChunkSize: 5})
+	xai.Register("mock-test", svc.NewFunc())
+
+	ctx := context.Background()
+	ai, err := xai.New(ctx, "mock-test:")
+	if err != nil {
+		t.Fatal("New:", err)
+	}
+	c := xai.NewConversation(ai).System("be nice")
+	c.Tools(ai.ToolDef("add").Description("add numbers"))
+	if _, err = c.SendText(ctx, "1+?"); err != nil {
+		t.Fatal("Send:", err)
+	}
+	if uses := c.ToolUses(); len(uses) != 1 || uses[0].Name != "add" {
+		t.Fatal("ToolUses:", uses)
+	}
+	c.ToolResult(xai.ToolResult{ID: "1", Name: "add", Result: 2})
+
+	var deltas []string
+	for ev, err := range c.Stream(ctx) {
+		if err != nil {
+			t.Fatal("Stream:", err)
+		}
+		if ev.Type == xai.EventDelta {
+			deltas = append(deltas, ev.Delta)
+		}
+	}
+	if len(deltas) != 3 || c.LastResponse().At(0).StopReason() != xai.StopMaxTokens {
+		t.Fatal("Stream:", deltas)
+	}
+
+	reqs := svc.Requests()
+	if len(reqs) != 2 || !reqs[1].Stream || reqs[0].System[0] != "be nice" || reqs[0].Tools[0].Description != "add numbers" {
+		t.Fatal("Requests:", reqs)
+	}
+	// user, assistant (thinking + tool use), user (tool result)
+	msgs := reqs[1].Messages
+	if len(msgs) != 3 || len(msgs[1].Parts) != 2 || msgs[0].Content() != "1+?" {
+		t.Fatal("Messages:", msgs)
+	}
+	if r, ok := msgs[2].Parts[0].AsToolResult(); !ok || r.Result != 2 {
+		t.Fatal("ToolResult:", r)
+	}
+	if _, err = ai.Gen(ctx, ai.GenParams()); err != ErrNoReply {
+		t.Fatal("Gen: expected ErrNoReply, got", err)
+	}
+	emb, err := ai.Embed(ctx, xai.EmbedParams{Texts: []string{"a", "b", "a"}, Dimensions: 4})
+	if err != nil || len(emb.Vectors) != 3 || len(emb.Vectors[0]) != 4 ||
+		emb.Vectors[0][0] != emb.Vectors[2][0] || emb.Vectors[0][0] == emb.Vectors[1][0] {
+		t.Fatal("Embed:", emb, err)
+	}
+	if n, err := ai.CountTokens(ctx, ai.GenParams().System("be nice").Messages(c.Messages()...)); err != nil || n != 8 {
+		t.Fatal("CountTokens:", n, err)
+	}
+}
+
+func TestTranscript(t *testing.T) {
+	svc := NewService().Reply(NewReply(
+		Thinking(xai.Thinking{Text: "hmm", Signature: "sig"}),
+		ToolUse(xai.ToolUse{ID: "1", Name: "add", Input: map[string]int{"a": 1}}),
+	)).ReplyText("done", "again")
+	ctx := context.Background()
+	c := xai.NewConversation(svc)
+	doc := svc.Docs().PlainText("notes")
+	if _, err := c.Send(ctx, svc.UserMsg().Text("1+?").Doc(doc)); err != nil {
+		t.Fatal("Send:", err)
+	}
+	c.ToolResult(xai.ToolResult{ID: "1", Name: "add", Result: errors.New("overflow"), IsError: true})
+	if _, err := c.Send(ctx); err != nil {
+		t.Fatal("Send:", err)
+	}
+	tr, err := c.Transcript()
+	if err != nil {
+		t.Fatal("Transcript:", err)
+	}
+	b, _ := json.Marshal(tr)
+	tr = new(xai.Transcript)
+	if err = json.Unmarshal(b, tr); err != nil || len(tr.Messages) != 4 {
+		t.Fatal("Unmarshal:", err, string(b))
+	}
+
+	c = xai.NewConversation(svc).Load(tr)
+	if _, err = c.SendText(ctx, "more"); err != nil {
+		t.Fatal("SendText:", err)
+	}
+	msgs := svc.LastRequest().Messages
+	if len(msgs) != 5 || msgs[0].Content() != "1+?" || msgs[4].Content() != "more" {
+		t.Fatal("Messages:", msgs)
+	}
+	if th, ok := msgs[1].Parts[0].AsThinking(); !ok || th.Signature != "sig" {
+		t.Fatal("Thinking:", th)
+	}
+	r, ok := msgs[2].Parts[0].AsToolResult()
+	if !ok || r.Name != "add" || !r.IsError || r.Result.(error).Error() != "overflow" {
+		t.Fatal("ToolResult:", r)
+	}
+}
+
+func TestOperation(t *testing.T) {
+	img := &xai.OutputImage{Image: NewService().ImageFromBytes(xai.ImagePNG, []byte("png"))}
+	svc := NewService().ReplyOp(xai.GenImage, OpReply{Results: &Results{Items: []xai.Generated{img}}, Polls: 2})
+	if actions := svc.Actions("m"); len(actions) != 1 || actions[0] != xai.GenImage {
+		t.Fatal("Actions:", actions)
+	}
+	op, err := svc.Operation("m", xai.GenImage)
+	if err != nil {
+		t.Fatal("Operation:", err)
+	}
+	ctx := context.Background()
+	resp, err := op.Call(ctx, op.CallParams().Set("Prompt", "a cat"))
+	if err != nil || resp.Done() {
+		t.Fatal("Call:", err)
+	}
+	var polls int
+	results, err := resp.Wait(ctx, resp.WaitParams().Progress(func(xai.OperationResponse) { polls++ }))
+	if err != nil || polls != 2 || results.Len() != 1 || results.At(0) != img {
+		t.Fatal("Wait:", polls, err)
+	}
+	if reqs := svc.OpRequests(); len(reqs) != 1 || reqs[0].Params["Prompt"] != "a cat" {
+		t.Fatal("OpRequests:", reqs)
+	}
+}
+
+func TestFiles(t *testing.T) {
+	ctx := context.Background()
+	files := NewService().Files()
+	f, err := files.Upload(ctx, strings.NewReader("png"), xai.UploadParams{Name: "a.png", MIME: "image/png"})
+	if err != nil || f.Size != 3 {
+		t.Fatal("Upload:", f, err)
+	}
+	expired, err := files.Upload(ctx, strings.NewReader("pdf"), xai.UploadParams{MIME: "application/pdf", ExpiresIn: time.Nanosecond})
+	if err != nil || expired.ExpiresAt.IsZero() {
+		t.Fatal("Upload:", expired, err)
+	}
+	time.Sleep(time.Millisecond)
+	var ids []string
+	for f, err := range files.List(ctx) {
+		if err != nil {
+			t.Fatal("List:", err)
+		}
+		ids = append(ids, f.ID)
+	}
+	if len(ids) != 1 || ids[0] != f.ID {
+		t.Fatal("List:", ids)
+	}
+	if err = files.Delete(ctx, f.ID); err != nil {
+		t.Fatal("Delete:", err)
+	}
+	if _, err = files.Get(ctx, f.ID); !errors.Is(err, xai.ErrNotFound) {
+		t.Fatal("Get:", err)
+	}
+}
+
+func TestCache(t *testing.T) {
+	svc := NewService().ReplyText("ok")
+	msg := svc.UserMsg().Text("a").CacheBreakpoint(time.Hour).Text("b")
+	params := svc.GenParams().Model("m").Messages(msg).System("s").CacheSystem(0)
+	if _, err := svc.Gen(context.Background(), params); err != nil {
+		t.Fatal("Gen:", err)
+	}
+	req := svc.Requests()[0]
+	if req.CacheSystem == nil || *req.CacheSystem != 0 || req.CacheTools != nil {
+		t.Fatal("CacheSystem:", req.CacheSystem, req.CacheTools)
+	}
+	if cache := req.Messages[0].Cache; len(cache) != 1 || cache[1] != time.Hour {
+		t.Fatal("CacheBreakpoint:", cache)
+	}
+}
+
+func TestCitations(t *testing.T) {
+	cite := xai.Citation{End: 5, URL: "https://example.com", DocIndex: -1}
+	svc := NewService().Reply(Reply{Candidates: []Candidate{{Parts: []xai.Part{CitedText("hello", cite)}}}})
+	msg := svc.UserMsg().Text("a").Doc(svc.Docs().PlainText("b")).DocCitations()
+	resp, err := svc.Gen(context.Background(), svc.GenParams().Model("m").Messages(msg))
+	if err != nil {
+		t.Fatal("Gen:", err)
+	}
+	if cites := resp.At(0).Part(0).Citations(); len(cites) != 1 || cites[0] != cite {
+		t.Fatal("Citations:", cites)
+	}
+	if cited := svc.Requests()[0].Messages[0].Cited; len(cited) != 1 || cited[0] != 1 {
+		t.Fatal("Cited:", cited)
+	}
+}
+
+func TestBatch(t *testing.T) {
+	ctx := context.Background()
+	svc := NewService().ReplyText("ok").Reply(Reply{Err: errors.New("bad")})
+	reqs := []xai.GenParams{
+		svc.GenParams().Model("m").Messages(svc.UserMsg().Text("a")),
+		svc.GenParams().Model("m").Messages(svc.UserMsg().Text("b")),
+		svc.GenParams().Model("m").Messages(svc.UserMsg().Text("c")),
+	}
+	resp, err := svc.SubmitBatch(ctx, reqs)
+	if err != nil {
+		t.Fatal("SubmitBatch:", err)
+	}
+	results, err := resp.Wait(ctx, resp.WaitParams())
+	if err != nil || results.Len() != 3 {
+		t.Fatal("Wait:", err)
+	}
+	if r := results.At(0).(*xai.BatchResult); r.CustomID != "0" || r.Response.At(0).Part(0).Text() != "ok" {
+		t.Fatal("At(0):", r)
+	}
+	if r := results.At(1).(*xai.BatchResult); r.Err == nil || r.Err.Error() != "bad" {
+		t.Fatal("At(1):", r.Err)
+	}
+	if r := results.At(2).(*xai.BatchResult); !errors.Is(r.Err, ErrNoReply) {
+		t.Fatal("At(2):", r.Err)
+	}
+}
+
+// -----------------------------------------------------------------------------
@@ -0,0 +1,289 @@
+/*
+ * Copyright (c) 2026 The XGo Authors (xgo.dev). All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package mock
+
+import (
+	"context"
+	"maps"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/goplus/xai"
+)
+
+// -----------------------------------------------------------------------------
+
+// OpReply is a scripted response of `Operation.Call`.
+type OpReply struct {
+	// Results is the results of the operation when it is done.
+	Results xai.Results
+
+	// Polls is the number of status checks by Wait before the operation is done.
+	// Zero means the operation is done when Call returns.
+	Polls int
+
+	// Err, if not nil, is returned by Call instead of the response.
+	Err error
+}
+
+// OpRequest is a request received by `Operation.Call`.
+type OpRequest struct {
+	Model  xai.Model
+	Action xai.Action
+
+	// Params holds the parameters set by CallParams.Set.
+	Params map[string]any
+
+	BaseURL string
+	Timeout time.Duration
+	Retry   *xai.RetryPolicy
+}
+
+// Results is an xai.Results of generated images or videos.
+type Results struct {
+	Items []xai.Generated
+	Attrs map[string]any // returned by XGo_Attr
+}
+
+func (p *Results) XGo_Attr(name string) any {
+	return p.Attrs[name]
+}
+
+func (p *Results) Len() int {
+	return len(p.Items)
+}
+
+func (p *Results) At(i int) xai.Generated {
+	return p.Items[i]
+}
+
+// ReplyOp appends replies to the script of `Operation.Call` of the action. Each
+// call consumes a reply in order. Actions with replies are returned by Actions.
+func (p *Service) ReplyOp(action xai.Action, replies ...OpReply) *Service {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.ops[action] = append(p.ops[action], replies...)
+	return p
+}
+
+// OpRequests returns the requests received by `Operation.Call`, in order.
+func (p *Service) OpRequests() []*OpRequest {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]*OpRequest(nil), p.calls...)
+}
+
+func (p *Service) Actions(model xai.Model) []xai.Action {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	ret := make([]xai.Action, 0, len(p.ops))
+	for action := range p.ops {
+		ret = append(ret, action)
+	}
+	slices.Sort(ret)
+	return ret
+}
+
+func (p *Service) Operation(model xai.Model, action xai.Action) (xai.Operation, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if _, ok := p.ops[action]; !ok {
+		return nil, xai.ErrNotFound
+	}
+	return &operation{svc: p, model: model, action: action}, nil
+}
+
+// -----------------------------------------------------------------------------
+
+type operation struct {
+	svc    *Service
+	model  xai.Model
+	action xai.Action
+}
+
+type inputSchema struct{}
+
+func (inputSchema) Fields() []xai.Field                      { return nil }
+func (inputSchema) Restriction(name string) *xai.Restriction { return nil }
+
+func (p *operation) InputSchema() xai.InputSchema {
+	return inputSchema{}
+}
+
+func (p *operation) CallParams() xai.CallParams {
+	return &callParams{req: OpRequest{Model: p.model, Action: p.action, Params: make(map[string]any)}}
+}
+
+func (p *operation) Call(ctx context.Context, params xai.CallParams) (xai.OperationResponse, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	req := params.(*callParams).req
+	req.Params = maps.Clone(req.Params)
+	svc := p.svc
+	svc.mu.Lock()
+	defer svc.mu.Unlock()
+	svc.calls = append(svc.calls, &req)
+	replies := svc.ops[p.action]
+	if len(replies) == 0 {
+		return nil, ErrNoReply
+	}
+	reply := replies[0]
+	svc.ops[p.action] = replies[1:]
+	if reply.Err != nil {
+		return nil, reply.Err
+	}
+	return &opResponse{reply: reply, polls: reply.Polls}, nil
+}
+
+type callParams struct {
+	req OpRequest
+}
+
+func (p *callParams) Set(name string, val any) xai.CallParams {
+	p.req.Params[name] = val
+	return p
+}
+
+func (p *callParams) BaseURL(base string) xai.CallParams {
+	p.req.BaseURL = base
+	return p
+}
+
+func (p *callParams) Timeout(timeout time.Duration) xai.CallParams {
+	p.req.Timeout = timeout
+	return p
+}
+
+func (p *callParams) Retry(policy xai.RetryPolicy) xai.CallParams {
+	p.req.Retry = &policy
+	return p
+}
+
+// -----------------------------------------------------------------------------
+
+type opResponse struct {
+	reply OpReply
+
+	mu    sync.Mutex
+	polls int // remaining polls before done
+}
+
+func (p *opResponse) Done() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.polls <= 0
+}
+
+func (p *opResponse) Results() xai.Results {
+	if p.Done() {
+		return p.reply.Results
+	}
+	return nil
+}
+
+func (p *opResponse) WaitParams() xai.WaitParams {
+	return &waitParams{}
+}
+
+func (p *opResponse) Wait(ctx context.Context, wp xai.WaitParams) (xai.Results, error) {
+	var progress func(xai.OperationResponse)
+	if params, ok := wp.(*waitParams); ok {
+		progress = params.progress
+	}
+	for !p.Done() {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		p.mu.Lock()
+		p.polls--
+		p.mu.Unlock()
+		if progress != nil {
+			progress(p)
+		}
+	}
+	return p.reply.Results, nil
+}
+
+type waitParams struct {
+	progress func(xai.OperationResponse)
+}
+
+func (p *waitParams) BaseURL(base string) xai.WaitParams {
+	return p
+}
+
+func (p *waitParams) Timeout(timeout time.Duration) xai.WaitParams {
+	return p
+}
+
+func (p *waitParams) Progress(fn func(xai.OperationResponse)) xai.WaitParams {
+	p.progress = fn
+	return p
+}
+
+// -----------------------------------------------------------------------------
+
+// ReferenceImage is the xai.ReferenceImage created by Service.ReferenceImage.
+type ReferenceImage struct {
+	Image xai.Image
+	ID    int32
+	Type  xai.ReferenceImageType
+
+	// Values holds the parameters set by its Configurable.
+	Values map[string]any
+}
+
+func (p *ReferenceImage) Schema() xai.InputSchema {
+	return inputSchema{}
+}
+
+func (p *ReferenceImage) Params() xai.Params {
+	return refParams{p}
+}
+
+type refParams struct {
+	ref *ReferenceImage
+}
+
+func (p refParams) Set(name string, val any) xai.Params {
+	p.ref.Values[name] = val
+	return p
+}
+
+// GenVideoMask is the xai.GenVideoMask created by Service.GenVideoMask.
+type GenVideoMask struct {
+	Image    xai.Image
+	MaskMode string
+}
+
+func (p *Service) ReferenceImage(img xai.Image, id int32, typ xai.ReferenceImageType) (xai.ReferenceImage, xai.Configurable) {
+	ret := &ReferenceImage{Image: img, ID: id, Type: typ, Values: make(map[string]any)}
+	return ret, ret
+}
+
+// GenVideoReferenceImages returns imgs.
+func (p *Service) GenVideoReferenceImages(imgs ...xai.GenVideoReferenceImage) xai.GenVideoReferenceImages {
+	return imgs
+}
+
+func (p *Service) GenVideoMask(img xai.Image, maskMode string) xai.GenVideoMask {
+	return &GenVideoMask{Image: img, MaskMode: maskMode}
+}
+
+// -----------------------------------------------------------------------------
@@ -0,0 +1,232 @@
+/*
+ * Copyright (c) 2026 The XGo Authors (xgo.dev). All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package mock
+
+import (
+	"time"
+
+	"github.com/goplus/xai"
+)
+
+// -----------------------------------------------------------------------------
+
+// Request is a request received by `Gen` or `GenStream`, as set by its GenParams.
+type Request struct {
+	Model    xai.Model
+	System   []string
+	Messages []*Msg
+	Tools    []ToolSpec
+
+	ToolChoice        *xai.ToolChoice
+	ParallelToolCalls *bool
+	MaxOutputTokens   int64
+	CompactTokens     int64 // set by Compact
+	CacheSystem       *time.Duration
+	CacheTools        *time.Duration
+	Temperature       *float64
+	TopP              *float64
+	Thinking          *xai.ThinkingConfig
+	ResponseFormat    *xai.ResponseFormat
+
+	BaseURL string
+	Timeout time.Duration
+	Retry   *xai.RetryPolicy
+
+	// Values holds the parameters set by GenParams.Set.
+	Values map[string]any
+
+	// Stream is true if the request is received by GenStream.
+	Stream bool
+}
+
+type genParams struct {
+	req Request
+}
+
+func (p *genParams) Set(name string, val any) xai.GenParams {
+	if p.req.Values == nil {
+		p.req.Values = make(map[string]any)
+	}
+	p.req.Values[name] = val
+	return p
+}
+
+func (p *genParams) System(prompt ...string) xai.GenParams {
+	p.req.System = prompt
+	return p
+}
+
+func (p *genParams) Messages(msgs ...xai.MsgBuilder) xai.GenParams {
+	p.req.Messages = make([]*Msg, len(msgs))
+	for i, msg := range msgs {
+		p.req.Messages[i] = msg.(*Msg)
+	}
+	return p
+}
+
+func (p *genParams) Tools(tools ...xai.ToolBase) xai.GenParams {
+	p.req.Tools = make([]ToolSpec, len(tools))
+	for i, tool := range tools {
+		tool.UnderlyingAssignTo(&p.req.Tools[i])
+	}
+	return p
+}
+
+func (p *genParams) ToolChoice(choice xai.ToolChoice) xai.GenParams {
+	p.req.ToolChoice = &choice
+	return p
+}
+
+func (p *genParams) ParallelToolCalls(v bool) xai.GenParams {
+	p.req.ParallelToolCalls = &v
+	return p
+}
+
+func (p *genParams) Model(model xai.Model) xai.GenParams {
+	p.req.Model = model
+	return p
+}
+
+func (p *genParams) MaxOutputTokens(v int64) xai.GenParams {
+	p.req.MaxOutputTokens = v
+	return p
+}
+
+func (p *genParams) Compact(maxInputTokens int64) xai.GenParams {
+	p.req.CompactTokens = maxInputTokens
+	return p
+}
+
+func (p *genParams) CacheSystem(ttl time.Duration) xai.GenParams {
+	p.req.CacheSystem = &ttl
+	return p
+}
+
+func (p *genParams) CacheTools(ttl time.Duration) xai.GenParams {
+	p.req.CacheTools = &ttl
+	return p
+}
+
+func (p *genParams) Temperature(v float64) xai.GenParams {
+	p.req.Temperature = &v
+	return p
+}
+
+func (p *genParams) TopP(v float64) xai.GenParams {
+	p.req.TopP = &v
+	return p
+}
+
+func (p *genParams) Thinking(v xai.ThinkingConfig) xai.GenParams {
+	p.req.Thinking = &v
+	return p
+}
+
+func (p *genParams) ResponseFormat(v xai.ResponseFormat) xai.GenParams {
+	p.req.ResponseFormat = &v
+	return p
+}
+
+func (p *genParams) BaseURL(base string) xai.GenParams {
+	p.req.BaseURL = base
+	return p
+}
+
+func (p *genParams) Timeout(timeout time.Duration) xai.GenParams {
+	p.req.Timeout = timeout
+	return p
+}
+
+func (p *genParams) Retry(policy xai.RetryPolicy) xai.GenParams {
+	p.req.Retry = &policy
+	return p
+}
+
+func (p *Service) GenParams() xai.GenParams {
+	return &genParams{}
+}
+
+// -----------------------------------------------------------------------------
+
+// ToolSpec is a tool of a Request, defined by ToolDef or WebSearchTool.
+type ToolSpec struct {
+	Name        string
+	Description string
+	InputSchema any
+
+	// MaxUses, AllowedDomains and BlockedDomains are set for the web search tool,
+	// whose Name is "std/web_search".
+	MaxUses        int64
+	AllowedDomains []string
+	BlockedDomains []string
+}
+
+type tool struct {
+	spec *ToolSpec
+}
+
+func (p tool) UnderlyingAssignTo(ret any) {
+	*ret.(*ToolSpec) = *p.spec
+}
+
+func (p tool) Description(desc string) xai.Tool {
+	p.spec.Description = desc
+	return p
+}
+
+func (p tool) InputSchema(schema any) xai.Tool {
+	p.spec.InputSchema = schema
+	return p
+}
+
+func (p tool) MaxUses(v int64) xai.WebSearchTool {
+	p.spec.MaxUses = v
+	return p
+}
+
+func (p tool) AllowedDomains(v ...string) xai.WebSearchTool {
+	p.spec.AllowedDomains = v
+	return p
+}
+
+func (p tool) BlockedDomains(v ...string) xai.WebSearchTool {
+	p.spec.BlockedDomains = v
+	return p
+}
+
+func (p *Service) WebSearchTool() xai.WebSearchTool {
+	return tool{&ToolSpec{Name: "std/web_search"}}
+}
+
+func (p *Service) ToolDef(name string) xai.Tool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if _, ok := p.tools[name]; ok {
+		panic("tool already defined: " + name)
+	}
+	ret := tool{&ToolSpec{Name: name}}
+	p.tools[name] = ret
+	return ret
+}
+
+func (p *Service) Tool(name string) xai.Tool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.tools[name]
+}
+
+// -----------------------------------------------------------------------------
@@ -0,0 +1,201 @@
+/*
+ * Copyright (c) 2026 The XGo Authors (xgo.dev). All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package openai
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/goplus/xai"
+	"github.com/goplus/xai/util"
+	"github.com/openai/openai-go/v3"
+	"github.com/openai/openai-go/v3/option"
+	"github.com/openai/openai-go/v3/responses"
+)
+
+// -----------------------------------------------------------------------------
+
+// batchRequest is a line of the input file of a batch.
+type batchRequest struct {
+	CustomID string                      `json:"custom_id"`
+	Method   string                      `json:"method"`
+	URL      string                      `json:"url"`
+	Body     responses.ResponseNewParams `json:"body"`
+}
+
+// batchOutput is a line of the output or error file of a batch.
+type batchOutput struct {
+	CustomID string `json:"custom_id"`
+	Response *struct {
+		StatusCode int             `json:"status_code"`
+		Body       json.RawMessage `json:"body"`
+	} `json:"response"`
+	Error *openai.BatchError `json:"error"`
+}
+
+// SubmitBatch uploads reqs as a JSONL file, and creates a batch of it for the
+// Responses API. The batch is submitted by the context and request options of
+// the first request. See https://platform.openai.com/docs/guides/batch.
+func (p *Service) SubmitBatch(ctx context.Context, reqs []xai.GenParams) (xai.BatchResponse, error) {
+	if len(reqs) == 0 {
+		return nil, errors.New("openai: empty batch")
+	}
+	ctx, _, opts := buildParams(ctx, reqs[0])
+	var input bytes.Buffer
+	enc := json.NewEncoder(&input)
+	for i, gp := range reqs {
+		_, params, _ := buildParams(ctx, gp)
+		req := batchRequest{CustomID: util.CustomID(i), Method: http.MethodPost, URL: "/v1/responses", Body: params}
+		if err := enc.Encode(req); err != nil {
+			return nil, err
+		}
+	}
+	f, err := p.files.New(ctx, openai.FileNewParams{
+		File:    openai.File(&input, "batch.jsonl", "application/jsonl"),
+		Purpose: openai.FilePurposeBatch,
+	}, opts...)
+	if err != nil {
+		return nil, translateError(err)
+	}
+	resp, err := p.batches.New(ctx, openai.BatchNewParams{
+		CompletionWindow: openai.BatchNewParamsCompletionWindow24h,
+		Endpoint:         openai.BatchNewParamsEndpointV1Responses,
+		InputFileID:      f.ID,
+	}, opts...)
+	if err != nil {
+		return nil, translateError(err)
+	}
+	poller := &batchPoller{p.batches, p.files, resp.ID, len(reqs), opts}
+	return util.NewBatch(poller.status(resp), poller, batchInterval), nil
+}
+
+const batchInterval = 30 * time.Second
+
+type batchPoller struct {
+	svc   openai.BatchService
+	files openai.FileService
+	id    string
+	n     int
+	opts  []option.RequestOption
+}
+
+func (p *batchPoller) status(resp *openai.Batch) util.BatchStatus {
+	var ended bool
+	switch resp.Status {
+	case openai.BatchStatusCompleted, openai.BatchStatusFailed, openai.BatchStatusExpired, openai.BatchStatusCancelled:
+		ended = true
+	}
+	counts := resp.RequestCounts
+	return util.BatchStatus{
+		ID:            resp.ID,
+		Ended:         ended,
+		EndedRequests: int(counts.Completed + counts.Failed),
+		Requests:      p.n,
+	}
+}
+
+func (p *batchPoller) Poll(ctx context.Context, wo *util.WaitOptions) (util.BatchStatus, []*xai.BatchResult, error) {
+	opts := p.opts
+	if wo.BaseURL != "" {
+		opts = append(slices.Clip(opts), option.WithBaseURL(wo.BaseURL))
+	}
+	resp, err := p.svc.Get(ctx, p.id, opts...)
+	if err != nil {
+		return util.BatchStatus{}, nil, translateError(err)
+	}
+	status := p.status(resp)
+	if !status.Ended {
+		return status, nil, nil
+	}
+	var results []*xai.BatchResult
+	for _, id := range []string{resp.OutputFileID, resp.ErrorFileID} {
+		if id == "" {
+			continue
+		}
+		if results, err = p.results(ctx, id, results, opts); err != nil {
+			return util.BatchStatus{}, nil, err
+		}
+	}
+	return status, util.BatchResults(results, p.n, batchError(resp)), nil
+}
+
+// results appends the results of the output or error file of id.
+func (p *batchPoller) results(ctx context.Context, id string, ret []*xai.BatchResult, opts []option.RequestOption) ([]*xai.BatchResult, error) {
+	content, err := p.files.Content(ctx, id, opts...)
+	if err != nil {
+		return nil, translateError(err)
+	}
+	defer content.Body.Close()
+	s := bufio.NewScanner(content.Body)
+	s.Buffer(nil, 64<<20)
+	for s.Scan() {
+		var out batchOutput
+		if len(bytes.TrimSpace(s.Bytes())) == 0 {
+			continue
+		}
+		if err = json.Unmarshal(s.Bytes(), &out); err != nil {
+			return nil, fmt.Errorf("openai: invalid batch output: %w", err)
+		}
+		ret = append(ret, batchResult(&out))
+	}
+	return ret, s.Err()
+}
+
+func batchResult(out *batchOutput) *xai.BatchResult {
+	ret := &xai.BatchResult{CustomID: out.CustomID}
+	switch {
+	case out.Response != nil && out.Response.StatusCode == http.StatusOK:
+		resp := new(responses.Response)
+		if ret.Err = json.Unmarshal(out.Response.Body, resp); ret.Err != nil {
+			break
+		}
+		if resp.Status == responses.ResponseStatusFailed {
+			ret.Err = failedError(resp)
+		} else {
+			ret.Response = response{resp}
+		}
+	case out.Response != nil:
+		var body struct {
+			Error openai.BatchError `json:"error"`
+		}
+		json.Unmarshal(out.Response.Body, &body)
+		e := body.Error
+		ret.Err = util.NewError(errorKinds[e.Code], out.Response.StatusCode, 0, errors.New(e.Code+": "+e.Message))
+	case out.Error != nil:
+		ret.Err = util.NewError(errorKinds[out.Error.Code], 0, 0, errors.New(out.Error.Code+": "+out.Error.Message))
+	default:
+		ret.Err = errors.New("openai: no response of the request in the batch")
+	}
+	return ret
+}
+
+// batchError returns the error of the requests without results in the batch.
+func batchError(resp *openai.Batch) error {
+	if errs := resp.Errors.Data; len(errs) > 0 {
+		return util.NewError(errorKinds[errs[0].Code], 0, 0, errors.New(errs[0].Code+": "+errs[0].Message))
+	}
+	return errors.New("openai: batch " + string(resp.Status))
+}
+
+// -----------------------------------------------------------------------------
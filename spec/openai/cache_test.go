@@ -0,0 +1,76 @@
+/*
+ * Copyright (c) 2026 The XGo Authors (xgo.dev). All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package openai
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/goplus/xai"
+)
+
+// -----------------------------------------------------------------------------
+
+func TestGenCodec(t *testing.T) {
+	svc := newService(t)
+	params := svc.GenParams().Model("gpt-5").Temperature(0.5).
+		Messages(svc.UserMsg().Text("hi").CacheBreakpoint(24 * time.Hour))
+	k1, err := svc.MarshalGenParams(params)
+	if err != nil {
+		t.Fatal("MarshalGenParams:", err)
+	}
+	k2, _ := svc.MarshalGenParams(params)
+	if !bytes.Equal(k1, k2) || !strings.Contains(string(k1), `"prompt_cache_retention":"24h"`) {
+		t.Fatalf("MarshalGenParams: %s, %s", k1, k2)
+	}
+	// the cache retention of the last build doesn't stick to the params
+	k3, _ := svc.MarshalGenParams(params.Messages(svc.UserMsg().Text("hi")))
+	if strings.Contains(string(k3), "prompt_cache_retention") {
+		t.Fatal("MarshalGenParams: unexpected prompt_cache_retention", string(k3))
+	}
+
+	resp, err := svc.UnmarshalGenResponse([]byte(`{"id":"resp_1","object":"response","status":"completed","output":[
+		{"type":"message","id":"msg_1","role":"assistant","status":"completed",
+			"content":[{"type":"output_text","text":"hello","annotations":[]}]},
+		{"type":"function_call","id":"fc_1","call_id":"call_1","name":"f","arguments":"{}","status":"completed"}
+	],"usage":{"input_tokens":10,"output_tokens":5}}`))
+	if err != nil {
+		t.Fatal("UnmarshalGenResponse:", err)
+	}
+	data, err := svc.MarshalGenResponse(resp)
+	if err != nil {
+		t.Fatal("MarshalGenResponse:", err)
+	}
+	ret, err := svc.UnmarshalGenResponse(data)
+	if err != nil {
+		t.Fatal("UnmarshalGenResponse:", err)
+	}
+	c := ret.At(0)
+	if use, ok := c.Part(1).AsToolUse(); c.Parts() != 2 || c.Part(0).Text() != "hello" || !ok || use.ID != "call_1" || use.Name != "f" {
+		t.Fatal("UnmarshalGenResponse: unexpected response", string(data))
+	}
+	if ret.Usage() != (xai.Usage{InputTokens: 10, OutputTokens: 5}) {
+		t.Fatal("Usage:", ret.Usage())
+	}
+	if msg := c.ToMsg().(*msgBuilder); len(msg.content) != 2 || msg.content[1].OfFunctionCall == nil {
+		t.Fatalf("ToMsg: %+v", msg.content)
+	}
+}
+
+// -----------------------------------------------------------------------------
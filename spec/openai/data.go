@@ -20,6 +20,7 @@ import (
 	"encoding/base64"
 	"io"
 	"os"
+	"path/filepath"
 	"strings"
 	"unsafe"
 
@@ -37,7 +38,7 @@ type inputData struct {
 
 type base64Data string
 
-func makeInputData(mime string, src any) (*inputData, error) {
+func makeInputData(mime, displayName string, src any) (*inputData, error) {
 	var b strings.Builder
 	b.WriteString("data:")
 	b.WriteString(mime)
@@ -56,12 +57,16 @@ func makeInputData(mime string, src any) (*inputData, error) {
 		}
 		encoder.Close()
 	}
-	return &inputData{
+	ret := &inputData{
 		data: responses.ResponseInputFileParam{
 			FileData: param.NewOpt(b.String()),
 		},
 		mime: mime,
-	}, nil
+	}
+	if displayName != "" {
+		ret.data.Filename = param.NewOpt(displayName)
+	}
+	return ret, nil
 }
 
 func (p *inputData) ImageType() xai.ImageType {
@@ -78,7 +83,7 @@ type imageBuilder struct {
 }
 
 func (p imageBuilder) From(mime xai.ImageType, displayName string, src io.Reader) (xai.ImageData, error) {
-	return makeInputData(string(mime), src)
+	return makeInputData(string(mime), displayName, src)
 }
 
 func (p imageBuilder) FromLocal(mime xai.ImageType, fileName string) (xai.ImageData, error) {
@@ -87,16 +92,16 @@ func (p imageBuilder) FromLocal(mime xai.ImageType, fileName string) (xai.ImageD
 		return nil, err
 	}
 	defer f.Close()
-	return makeInputData(string(mime), f)
+	return makeInputData(string(mime), filepath.Base(fileName), f)
 }
 
 func (p imageBuilder) FromBytes(mime xai.ImageType, displayName string, data []byte) xai.ImageData {
-	ret, _ := makeInputData(string(mime), data)
+	ret, _ := makeInputData(string(mime), displayName, data)
 	return ret
 }
 
 func (p imageBuilder) FromBase64(mime xai.ImageType, displayName string, data string) (xai.ImageData, error) {
-	return makeInputData(string(mime), base64Data(data))
+	return makeInputData(string(mime), displayName, base64Data(data))
 }
 
 func (p *Service) Images() xai.ImageBuilder {
@@ -109,7 +114,7 @@ type docBuilder struct {
 }
 
 func (p docBuilder) From(mime xai.DocumentType, displayName string, src io.Reader) (xai.DocumentData, error) {
-	return makeInputData(string(mime), src)
+	return makeInputData(string(mime), displayName, src)
 }
 
 func (p docBuilder) FromLocal(mime xai.DocumentType, fileName string) (xai.DocumentData, error) {
@@ -118,21 +123,21 @@ func (p docBuilder) FromLocal(mime xai.DocumentType, fileName string) (xai.Docum
 		return nil, err
 	}
 	defer f.Close()
-	return makeInputData(string(mime), f)
+	return makeInputData(string(mime), filepath.Base(fileName), f)
 }
 
 func (p docBuilder) FromBase64(mime xai.DocumentType, displayName string, data string) (xai.DocumentData, error) {
-	return makeInputData(string(mime), base64Data(data))
+	return makeInputData(string(mime), displayName, base64Data(data))
 }
 
 func (p docBuilder) FromBytes(mime xai.DocumentType, displayName string, data []byte) xai.DocumentData {
-	ret, _ := makeInputData(string(mime), data)
+	ret, _ := makeInputData(string(mime), displayName, data)
 	return ret
 }
 
 func (p docBuilder) PlainText(text string) xai.DocumentData {
 	data := unsafe.Slice(unsafe.StringData(text), len(text))
-	ret, _ := makeInputData(string(xai.DocPlainText), data)
+	ret, _ := makeInputData(string(xai.DocPlainText), "", data)
 	return ret
 }
 
@@ -0,0 +1,60 @@
+/*
+ * Copyright (c) 2026 The XGo Authors (xgo.dev). All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package openai
+
+import (
+	"context"
+	"errors"
+
+	"github.com/goplus/xai"
+	"github.com/openai/openai-go/v3"
+	"github.com/openai/openai-go/v3/packages/param"
+)
+
+// -----------------------------------------------------------------------------
+
+func (p *Service) Embed(ctx context.Context, in xai.EmbedParams) (*xai.Embeddings, error) {
+	if len(in.Images) > 0 {
+		return nil, &xai.Error{Kind: xai.ErrInvalidRequest, Err: errors.New("openai: embeddings of images are not supported")}
+	}
+	params := openai.EmbeddingNewParams{
+		Input:          openai.EmbeddingNewParamsInputUnion{OfArrayOfStrings: in.Texts},
+		Model:          openai.EmbeddingModel(in.Model),
+		EncodingFormat: openai.EmbeddingNewParamsEncodingFormatFloat,
+	}
+	if in.Dimensions > 0 {
+		params.Dimensions = param.NewOpt(in.Dimensions)
+	}
+	resp, err := p.embeddings.New(ctx, params)
+	if err != nil {
+		return nil, translateError(err)
+	}
+	ret := &xai.Embeddings{
+		Vectors: make([][]float32, len(resp.Data)),
+		Usage:   xai.Usage{InputTokens: resp.Usage.PromptTokens},
+	}
+	for _, v := range resp.Data {
+		vec := make([]float32, len(v.Embedding))
+		for i, f := range v.Embedding {
+			vec[i] = float32(f)
+		}
+		ret.Vectors[v.Index] = vec
+	}
+	return ret, nil
+}
+
+// -----------------------------------------------------------------------------
@@ -0,0 +1,87 @@
+/*
+ * Copyright (c) 2026 The XGo Authors (xgo.dev). All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package openai
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/goplus/xai"
+	"github.com/goplus/xai/util"
+	"github.com/openai/openai-go/v3"
+	"github.com/openai/openai-go/v3/responses"
+)
+
+// -----------------------------------------------------------------------------
+
+// errorKinds maps the `error.code` of an openai error to xai error kinds. Codes
+// that are not listed here are classified by the HTTP status code.
+var errorKinds = map[string]error{
+	"context_length_exceeded":        xai.ErrContextWindowExceeded,
+	"string_above_max_length":        xai.ErrContextWindowExceeded,
+	"rate_limit_exceeded":            xai.ErrRateLimited,
+	"insufficient_quota":             xai.ErrRateLimited,
+	"invalid_api_key":                xai.ErrAuth,
+	"model_not_found":                xai.ErrNotFound,
+	"content_filter":                 xai.ErrContentFiltered,
+	"content_policy_violation":       xai.ErrContentFiltered,
+	"image_content_policy_violation": xai.ErrContentFiltered,
+	"invalid_prompt":                 xai.ErrInvalidRequest,
+	"server_error":                   xai.ErrServer,
+}
+
+// translateError converts an openai error into an *xai.Error. Errors that don't
+// come from the API (e.g. context canceled) are returned unchanged.
+func translateError(err error) error {
+	var e *openai.Error
+	if !errors.As(err, &e) {
+		return err
+	}
+	kind := errorKinds[e.Code]
+	if kind == nil && strings.Contains(e.Message, "maximum context length") {
+		kind = xai.ErrContextWindowExceeded
+	}
+	var retryAfter time.Duration
+	if e.Response != nil {
+		retryAfter = util.RetryAfter(e.Response.Header)
+	}
+	return util.NewError(kind, e.StatusCode, retryAfter, err)
+}
+
+// -----------------------------------------------------------------------------
+
+// responseError is the error of a response whose status is `failed`. Such a
+// response is returned with HTTP 200, so it is not reported as *openai.Error.
+type responseError struct {
+	*responses.ResponseError
+}
+
+func (e responseError) Error() string {
+	return string(e.Code) + ": " + e.Message
+}
+
+func failedError(resp *responses.Response) error {
+	err := responseError{&resp.Error}
+	kind := errorKinds[string(resp.Error.Code)]
+	if kind == nil {
+		kind = xai.ErrInvalidRequest
+	}
+	return util.NewError(kind, 0, 0, err)
+}
+
+// -----------------------------------------------------------------------------
@@ -0,0 +1,103 @@
+/*
+ * Copyright (c) 2026 The XGo Authors (xgo.dev). All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package openai
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/goplus/xai"
+)
+
+// -----------------------------------------------------------------------------
+
+func TestTranslateError(t *testing.T) {
+	cases := []struct {
+		status      int
+		header, val string // retry header
+		typ, code   string
+		msg         string
+		kind        error
+		retryAfter  time.Duration
+	}{
+		{400, "", "", "invalid_request_error", "context_length_exceeded",
+			"Your input exceeds the context window of this model.", xai.ErrContextWindowExceeded, 0},
+		{400, "", "", "invalid_request_error", "",
+			"This model's maximum context length is 128000 tokens.", xai.ErrContextWindowExceeded, 0},
+		{400, "", "", "invalid_request_error", "invalid_prompt", "Invalid prompt.", xai.ErrInvalidRequest, 0},
+		{400, "", "", "invalid_request_error", "content_policy_violation",
+			"Your request was rejected as a result of our safety system.", xai.ErrContentFiltered, 0},
+		{401, "", "", "invalid_request_error", "invalid_api_key", "Incorrect API key provided.", xai.ErrAuth, 0},
+		{404, "", "", "invalid_request_error", "model_not_found", "The model `gpt-x` does not exist.", xai.ErrNotFound, 0},
+		{429, "Retry-After-Ms", "1500", "requests", "rate_limit_exceeded",
+			"Rate limit reached for gpt-5 in organization org-x on requests per min (RPM).", xai.ErrRateLimited, 1500 * time.Millisecond},
+		{429, "Retry-After", "20", "insufficient_quota", "insufficient_quota",
+			"You exceeded your current quota.", xai.ErrRateLimited, 20 * time.Second},
+		{500, "", "", "server_error", "server_error", "The server had an error.", xai.ErrServer, 0},
+		{503, "", "", "", "", "The engine is currently overloaded.", xai.ErrOverloaded, 0}, // by the status code
+	}
+	for _, c := range cases {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if c.header != "" {
+				w.Header().Set(c.header, c.val)
+			}
+			code, _ := json.Marshal(c.code)
+			if c.code == "" {
+				code = []byte("null")
+			}
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(c.status)
+			fmt.Fprintf(w, `{"error":{"message":%q,"type":%q,"param":null,"code":%s}}`, c.msg, c.typ, code)
+		}))
+		svc, err := New(context.Background(), "openai:key=test&retries=0&base="+srv.URL)
+		if err != nil {
+			t.Fatal("New:", err)
+		}
+		_, err = svc.Gen(context.Background(), svc.GenParams().Model("m").Messages(svc.UserMsg().Text("hi")))
+		srv.Close()
+		var e *xai.Error
+		if !errors.As(err, &e) || !errors.Is(err, c.kind) || e.StatusCode != c.status || e.RetryAfter != c.retryAfter {
+			t.Errorf("%s: unexpected %v", c.msg, err)
+		}
+	}
+}
+
+func TestFailedError(t *testing.T) {
+	// failed responses are returned with HTTP 200
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"id":"resp_1","object":"response","status":"failed","output":[],`+
+			`"error":{"code":"rate_limit_exceeded","message":"Rate limit reached."}}`)
+	}))
+	defer srv.Close()
+	svc, err := New(context.Background(), "openai:key=test&retries=0&base="+srv.URL)
+	if err != nil {
+		t.Fatal("New:", err)
+	}
+	_, err = svc.Gen(context.Background(), svc.GenParams().Model("m").Messages(svc.UserMsg().Text("hi")))
+	if !errors.Is(err, xai.ErrRateLimited) || err.Error() != "rate limited: rate_limit_exceeded: Rate limit reached." {
+		t.Fatal("Gen: unexpected", err)
+	}
+}
+
+// -----------------------------------------------------------------------------
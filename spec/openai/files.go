@@ -0,0 +1,108 @@
+/*
+ * Copyright (c) 2026 The XGo Authors (xgo.dev). All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package openai
+
+import (
+	"context"
+	"io"
+	"iter"
+	"mime"
+	"path"
+	"time"
+
+	"github.com/goplus/xai"
+	"github.com/openai/openai-go/v3"
+)
+
+// -----------------------------------------------------------------------------
+
+// files implements xai.Files by the Files API of OpenAI. Files are uploaded for
+// the purpose of model inputs (user_data).
+type files struct {
+	svc openai.FileService
+}
+
+func (p files) Upload(ctx context.Context, src io.Reader, params xai.UploadParams) (*xai.File, error) {
+	name := params.Name
+	if name == "" {
+		name = "file" // required by multipart forms
+	}
+	in := openai.FileNewParams{
+		File:    openai.File(src, name, params.MIME),
+		Purpose: openai.FilePurposeUserData,
+	}
+	if params.ExpiresIn > 0 {
+		in.ExpiresAfter.Seconds = int64(params.ExpiresIn / time.Second)
+	}
+	ret, err := p.svc.New(ctx, in)
+	if err != nil {
+		return nil, translateError(err)
+	}
+	f := fileOf(ret)
+	f.MIME = params.MIME
+	return f, nil
+}
+
+func (p files) Get(ctx context.Context, id string) (*xai.File, error) {
+	ret, err := p.svc.Get(ctx, id)
+	if err != nil {
+		return nil, translateError(err)
+	}
+	return fileOf(ret), nil
+}
+
+func (p files) List(ctx context.Context) iter.Seq2[*xai.File, error] {
+	return func(yield func(*xai.File, error) bool) {
+		iter := p.svc.ListAutoPaging(ctx, openai.FileListParams{Purpose: openai.String(string(openai.FilePurposeUserData))})
+		for iter.Next() {
+			f := iter.Current()
+			if !yield(fileOf(&f), nil) {
+				return
+			}
+		}
+		if err := iter.Err(); err != nil {
+			yield(nil, translateError(err))
+		}
+	}
+}
+
+func (p files) Delete(ctx context.Context, id string) error {
+	_, err := p.svc.Delete(ctx, id)
+	return translateError(err)
+}
+
+// fileOf converts f to xai.File. OpenAI doesn't keep MIME types of files, so it
+// is guessed by the extension of the filename.
+func fileOf(f *openai.FileObject) *xai.File {
+	ret := &xai.File{
+		ID:        f.ID,
+		Name:      f.Filename,
+		MIME:      mime.TypeByExtension(path.Ext(f.Filename)),
+		Size:      f.Bytes,
+		CreatedAt: time.Unix(f.CreatedAt, 0),
+	}
+	if f.ExpiresAt > 0 {
+		ret.ExpiresAt = time.Unix(f.ExpiresAt, 0)
+	}
+	return ret
+}
+
+func (p *Service) Files() xai.Files {
+	return files{p.files}
+}
+
+// -----------------------------------------------------------------------------
@@ -17,6 +17,8 @@
 package openai
 
 import (
+	"time"
+
 	"github.com/goplus/xai"
 	"github.com/openai/openai-go/v3/packages/param"
 	"github.com/openai/openai-go/v3/responses"
@@ -28,6 +30,7 @@ type msgBuilder struct {
 	content []responses.ResponseInputItemUnionParam
 	msg     *responses.EasyInputMessageParam
 	role    responses.EasyInputMessageRole
+	cache   time.Duration // max TTL of CacheBreakpoint
 }
 
 func buildMessages(in []xai.MsgBuilder, sysPrompt responses.ResponseInputItemUnionParam) (ret responses.ResponseNewParamsInputUnion) {
@@ -41,7 +44,7 @@ func buildMessages(in []xai.MsgBuilder, sysPrompt responses.ResponseInputItemUni
 		msgs = append(msgs, sysPrompt)
 	}
 	for _, v := range in {
-		msgs = append(msgs, v.(*msgBuilder).content[0])
+		msgs = append(msgs, v.(*msgBuilder).content...)
 	}
 	ret.OfInputItemList = msgs
 	return
@@ -77,7 +80,13 @@ func (p *msgBuilder) Text(text string) xai.MsgBuilder {
 }
 
 func (p *msgBuilder) Image(image xai.ImageData) xai.MsgBuilder {
-	panic("todo")
+	data := image.(*inputData)
+	return p.addMsg(responses.ResponseInputContentUnionParam{
+		OfInputImage: &responses.ResponseInputImageParam{
+			ImageURL: data.data.FileData, // a data URL
+			Detail:   responses.ResponseInputImageDetailAuto,
+		},
+	})
 }
 
 func (p *msgBuilder) ImageURL(mime xai.ImageType, url string) xai.MsgBuilder {
@@ -97,7 +106,11 @@ func (p *msgBuilder) ImageFile(mime xai.ImageType, fileID string) xai.MsgBuilder
 }
 
 func (p *msgBuilder) Doc(doc xai.DocumentData) xai.MsgBuilder {
-	panic("todo")
+	data := doc.(*inputData).data
+	if !data.Filename.Valid() {
+		data.Filename = param.NewOpt("document") // required by file data
+	}
+	return p.addMsg(responses.ResponseInputContentUnionParam{OfInputFile: &data})
 }
 
 func (p *msgBuilder) DocURL(mime xai.DocumentType, url string) xai.MsgBuilder {
@@ -116,27 +129,43 @@ func (p *msgBuilder) DocFile(mime xai.DocumentType, fileID string) xai.MsgBuilde
 	})
 }
 
-func (p *msgBuilder) Part(part xai.Part) xai.MsgBuilder {
-	p.content = append(p.content, buildPart(part))
+// DocCitations is a no-op, as the Responses API doesn't cite input files. Files
+// found by the file search tool are cited by file_citation annotations instead.
+func (p *msgBuilder) DocCitations() xai.MsgBuilder {
 	return p
 }
 
+func (p *msgBuilder) Part(part xai.Part) xai.MsgBuilder {
+	return p.addNonMsg(buildPart(part))
+}
+
 func (p *msgBuilder) Thinking(v xai.Thinking) xai.MsgBuilder {
-	if v.Redacted {
-		panic("todo")
+	var item responses.ResponseReasoningItemParam
+	if u, ok := v.Underlying.(*responses.ResponseReasoningItem); ok {
+		item = u.ToParam()
+	} else {
+		id, encrypted := parseReasoningSignature(v.Signature)
+		item.ID = id
+		item.Summary = []responses.ResponseReasoningItemSummaryParam{}
+		if v.Text != "" {
+			item.Summary = append(item.Summary, responses.ResponseReasoningItemSummaryParam{Text: v.Text})
+		}
+		if encrypted != "" {
+			item.EncryptedContent = param.NewOpt(encrypted)
+		}
 	}
-	return p.addNonMsg(responses.ResponseInputItemUnionParam{
-		OfReasoning: &responses.ResponseReasoningItemParam{
-			ID: v.Signature,
-			Content: []responses.ResponseReasoningItemContentParam{
-				{Text: v.Text},
-			},
-		},
-	})
+	return p.addNonMsg(responses.ResponseInputItemUnionParam{OfReasoning: &item})
 }
 
 func (p *msgBuilder) Compaction(data string) xai.MsgBuilder {
 	return p.addNonMsg(responses.ResponseInputItemParamOfCompaction(data))
 }
 
+// CacheBreakpoint only affects the prompt cache retention, as prompts are
+// cached automatically.
+func (p *msgBuilder) CacheBreakpoint(ttl time.Duration) xai.MsgBuilder {
+	p.cache = max(p.cache, ttl)
+	return p
+}
+
 // -----------------------------------------------------------------------------
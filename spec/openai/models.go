@@ -0,0 +1,60 @@
+/*
+ * Copyright (c) 2026 The XGo Authors (xgo.dev). All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package openai
+
+import (
+	"context"
+
+	"github.com/goplus/xai"
+	"github.com/goplus/xai/util"
+)
+
+// -----------------------------------------------------------------------------
+
+var (
+	inputsText   = []xai.Modality{xai.ModalityText}
+	inputsAll    = []xai.Modality{xai.ModalityText, xai.ModalityImage, xai.ModalityDocument}
+	outputsText  = []xai.Modality{xai.ModalityText}
+	outputsImage = []xai.Modality{xai.ModalityImage}
+)
+
+// knownModels completes the models listed by the API, which only provides their
+// IDs.
+var knownModels = util.ModelTable{
+	{ID: "gpt-5*-chat*", ContextWindow: 128000, MaxOutputTokens: 16384, InputModalities: inputsAll, OutputModalities: outputsText, Tools: true, StructuredOutput: true},
+	{ID: "gpt-5*", ContextWindow: 400000, MaxOutputTokens: 128000, InputModalities: inputsAll, OutputModalities: outputsText, Tools: true, Thinking: true, StructuredOutput: true},
+	{ID: "gpt-4.1*", ContextWindow: 1047576, MaxOutputTokens: 32768, InputModalities: inputsAll, OutputModalities: outputsText, Tools: true, StructuredOutput: true},
+	{ID: "gpt-4o*", ContextWindow: 128000, MaxOutputTokens: 16384, InputModalities: inputsAll, OutputModalities: outputsText, Tools: true, StructuredOutput: true},
+	{ID: "o[1-9]*", ContextWindow: 200000, MaxOutputTokens: 100000, InputModalities: inputsAll, OutputModalities: outputsText, Tools: true, Thinking: true, StructuredOutput: true},
+	{ID: "gpt-image-*", InputModalities: []xai.Modality{xai.ModalityText, xai.ModalityImage}, OutputModalities: outputsImage},
+	{ID: "dall-e-*", InputModalities: inputsText, OutputModalities: outputsImage},
+	{ID: "text-embedding-*", ContextWindow: 8191, InputModalities: inputsText},
+}
+
+func (p *Service) Models(ctx context.Context) (ret []*xai.ModelInfo, err error) {
+	iter := p.models.ListAutoPaging(ctx)
+	for iter.Next() {
+		m := iter.Current()
+		ret = append(ret, knownModels.Complete(&xai.ModelInfo{ID: xai.Model(m.ID)}))
+	}
+	if err = iter.Err(); err != nil {
+		return nil, translateError(err)
+	}
+	return
+}
+
+// -----------------------------------------------------------------------------
@@ -24,36 +24,71 @@ import (
 	"time"
 
 	"github.com/goplus/xai"
+	"github.com/goplus/xai/util"
+	"github.com/openai/openai-go/v3"
 	"github.com/openai/openai-go/v3/option"
+	"github.com/openai/openai-go/v3/packages/param"
 	"github.com/openai/openai-go/v3/responses"
 )
 
 // -----------------------------------------------------------------------------
 
 type Service struct {
-	responses responses.ResponseService
-	tools     tools
+	responses  responses.ResponseService
+	embeddings openai.EmbeddingService
+	models     openai.ModelService
+	files      openai.FileService
+	batches    openai.BatchService
+	tools      tools
+}
+
+// Provider returns the provider of the service, i.e. Scheme.
+func (p *Service) Provider() string {
+	return Scheme
 }
 
 func (p *Service) Features() xai.Feature {
-	return xai.FeatureGen | xai.FeatureGenStream | xai.FeatureOperation
+	return xai.FeatureGen | xai.FeatureGenStream | xai.FeatureCountTokens | xai.FeatureEmbed |
+		xai.FeatureFiles | xai.FeatureBatch
 }
 
 func (p *Service) Gen(ctx context.Context, gp xai.GenParams) (xai.GenResponse, error) {
-	params, opts := buildParams(gp)
+	ctx, params, opts := buildParams(ctx, gp)
 	resp, err := p.responses.New(ctx, params, opts...)
 	if err != nil {
-		return nil, err // TODO(xsw): translate error
+		return nil, translateError(err)
+	}
+	if resp.Status == responses.ResponseStatusFailed {
+		return nil, failedError(resp)
 	}
 	return response{resp}, nil
 }
 
-func (p *Service) GenStream(ctx context.Context, gp xai.GenParams) iter.Seq2[xai.GenResponse, error] {
-	params, opts := buildParams(gp)
+func (p *Service) GenStream(ctx context.Context, gp xai.GenParams) iter.Seq2[xai.StreamEvent, error] {
+	ctx, params, opts := buildParams(ctx, gp)
 	resp := p.responses.NewStreaming(ctx, params, opts...)
 	return buildRespIter(resp)
 }
 
+func (p *Service) CountTokens(ctx context.Context, gp xai.GenParams) (int64, error) {
+	ctx, in, opts := buildParams(ctx, gp)
+	params := responses.InputTokenCountParams{
+		Model:             param.NewOpt(in.Model),
+		Input:             responses.InputTokenCountParamsInputUnion{OfResponseInputItemArray: in.Input.OfInputItemList},
+		Instructions:      in.Instructions,
+		ParallelToolCalls: in.ParallelToolCalls,
+		Tools:             in.Tools,
+		ToolChoice:        responses.InputTokenCountParamsToolChoiceUnion(in.ToolChoice),
+		Reasoning:         in.Reasoning,
+		Text:              responses.InputTokenCountParamsText{Verbosity: string(in.Text.Verbosity), Format: in.Text.Format},
+	}
+	resp, err := p.responses.InputTokens.Count(ctx, params, opts...)
+	if err != nil {
+		return 0, translateError(err)
+	}
+	return resp.InputTokens, nil
+}
+
 // -----------------------------------------------------------------------------
 
 const (
@@ -64,7 +99,9 @@ const (
 // uri should be in the format of "openai:base=service_base_url&key=api_key".
 //
 // `base` is the base URL of the API endpoint.
-// `timeout` is the request timeout duration (e.g., "30s").
+// `timeout` is the timeout of each attempt of requests (e.g., "30s").
+// `retries` is the max number of retries on transient errors (default 2).
+// `retry_max_wait` is the max wait duration before a retry (e.g., "30s").
 // `key` is the API key for authentication.
 // `org` is the organization ID to use for the API requests.
 // `project` is the project ID to use for the API requests.
@@ -83,12 +120,21 @@ func New(ctx context.Context, uri string) (xai.Service, error) {
 	if base := params["base"]; len(base) > 0 {
 		opts = append(opts, option.WithBaseURL(base[0]))
 	}
-	if timeout := params["timeout"]; len(timeout) > 0 {
-		d, err := time.ParseDuration(timeout[0])
-		if err != nil {
+	var timeout time.Duration
+	if v := params["timeout"]; len(v) > 0 {
+		if timeout, err = time.ParseDuration(v[0]); err != nil {
 			return nil, err
 		}
-		opts = append(opts, option.WithRequestTimeout(d))
+	}
+	retry, err := util.ParseRetryPolicy(params)
+	if err != nil {
+		return nil, err
+	}
+	// Retries are done by the middleware instead of the SDK, so that they behave
+	// the same across all services, and the timeout applies to each attempt.
+	opts = append(opts, option.WithMaxRetries(0), option.WithMiddleware(util.RetryMiddleware(retry, timeout)))
+	if client := xai.HTTPClient(ctx); client != nil {
+		opts = append(opts, option.WithHTTPClient(client))
 	}
 	if key := params["key"]; len(key) > 0 {
 		opts = append(opts, option.WithAPIKey(key[0]))
@@ -103,8 +149,12 @@ func New(ctx context.Context, uri string) (xai.Service, error) {
 		opts = append(opts, option.WithWebhookSecret(webhookSec[0]))
 	}
 	return &Service{
-		responses: responses.NewResponseService(opts...),
-		tools:     make(tools),
+		responses:  responses.NewResponseService(opts...),
+		embeddings: openai.NewEmbeddingService(opts...),
+		models:     openai.NewModelService(opts...),
+		files:      openai.NewFileService(opts...),
+		batches:    openai.NewBatchService(opts...),
+		tools:      make(tools),
 	}, nil
 }
 
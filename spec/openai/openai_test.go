@@ -1,6 +1,3 @@
-//go:build xaitest
-// +build xaitest
-
 /*
  * Copyright (c) 2026 The XGo Authors (xgo.dev). All rights reserved.
  *
@@ -27,6 +24,7 @@ import (
 )
 
 func TestOpenAI(t *testing.T) {
-	// TODO(xsw): implement test cases for openai.
-	xaitest.Do(t, context.Background(), "openai:xxx")
+	srv := xaitest.NewOpenAIServer()
+	defer srv.Close()
+	xaitest.Do(t, context.Background(), "openai:base="+srv.URL+"/v1/&key=test", "gpt-5")
 }
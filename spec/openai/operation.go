@@ -21,11 +21,12 @@ import "github.com/goplus/xai"
 // -----------------------------------------------------------------------------
 
 func (p *Service) Actions(model xai.Model) []xai.Action {
-	panic("todo")
+	// openai doesn't support any actions for now.
+	return nil
 }
 
 func (p *Service) Operation(model xai.Model, action xai.Action) (op xai.Operation, err error) {
-	panic("todo")
+	return nil, xai.ErrNotFound
 }
 
 // -----------------------------------------------------------------------------
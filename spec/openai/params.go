@@ -17,7 +17,9 @@
 package openai
 
 import (
+	"context"
 	"reflect"
+	"slices"
 	"time"
 
 	"github.com/goplus/xai"
@@ -75,6 +77,9 @@ type params struct {
 	opts    []option.RequestOption
 	sys     responses.ResponseInputMessageContentListParam
 	msgs    []xai.MsgBuilder
+	retry   *xai.RetryPolicy
+	timeout time.Duration // timeout of each attempt
+	cache   time.Duration // max TTL of CacheSystem and CacheTools
 }
 
 /*
@@ -261,6 +266,27 @@ func (p *params) Tools(tools ...xai.ToolBase) xai.GenParams {
 	return p
 }
 
+func (p *params) ToolChoice(choice xai.ToolChoice) xai.GenParams {
+	var ret responses.ResponseNewParamsToolChoiceUnion
+	switch choice.Mode {
+	case xai.ToolChoiceAny:
+		ret.OfToolChoiceMode = param.NewOpt(responses.ToolChoiceOptionsRequired)
+	case xai.ToolChoiceNone:
+		ret.OfToolChoiceMode = param.NewOpt(responses.ToolChoiceOptionsNone)
+	case xai.ToolChoiceTool:
+		ret.OfFunctionTool = &responses.ToolChoiceFunctionParam{Name: choice.Name}
+	default:
+		ret.OfToolChoiceMode = param.NewOpt(responses.ToolChoiceOptionsAuto)
+	}
+	p.params.ToolChoice = ret
+	return p
+}
+
+func (p *params) ParallelToolCalls(enabled bool) xai.GenParams {
+	p.params.ParallelToolCalls = param.NewOpt(enabled)
+	return p
+}
+
 func (p *params) Model(model xai.Model) xai.GenParams {
 	p.params.Model = shared.ResponsesModel(model) // TODO(xsw): validate model
 	return p
@@ -275,6 +301,20 @@ func (p *params) Compact(maxInputTokens int64) xai.GenParams {
 	panic("todo")
 }
 
+// CacheSystem only affects the prompt cache retention, as prompts are cached
+// automatically.
+func (p *params) CacheSystem(ttl time.Duration) xai.GenParams {
+	p.cache = max(p.cache, ttl)
+	return p
+}
+
+// CacheTools only affects the prompt cache retention, as prompts are cached
+// automatically.
+func (p *params) CacheTools(ttl time.Duration) xai.GenParams {
+	p.cache = max(p.cache, ttl)
+	return p
+}
+
 func (p *params) Temperature(v float64) xai.GenParams {
 	p.params.Temperature = param.NewOpt(v)
 	return p
@@ -285,13 +325,58 @@ func (p *params) TopP(v float64) xai.GenParams {
 	return p
 }
 
+func (p *params) Thinking(conf xai.ThinkingConfig) xai.GenParams {
+	if effort := conf.EffortLevel(); effort != "" {
+		p.params.Reasoning.Effort = shared.ReasoningEffort(effort)
+	}
+	if conf.IncludeSummary {
+		p.params.Reasoning.Summary = shared.ReasoningSummaryAuto
+	}
+	if !conf.Disabled() && !slices.Contains(p.params.Include, responses.ResponseIncludableReasoningEncryptedContent) {
+		// reasoning items are passed back with their encrypted content, so
+		// that multi-turn reasoning also works when responses are not stored.
+		p.params.Include = append(p.params.Include, responses.ResponseIncludableReasoningEncryptedContent)
+	}
+	return p
+}
+
+func (p *params) ResponseFormat(format xai.ResponseFormat) xai.GenParams {
+	var ret responses.ResponseFormatTextConfigUnionParam
+	switch format.Type {
+	case xai.FormatJSONSchema:
+		ret.OfJSONSchema = &responses.ResponseFormatTextJSONSchemaConfigParam{
+			Name:   format.Name,
+			Schema: format.Schema,
+		}
+		if format.Description != "" {
+			ret.OfJSONSchema.Description = param.NewOpt(format.Description)
+		}
+		if format.Strict {
+			ret.OfJSONSchema.Strict = param.NewOpt(true)
+		}
+	case xai.FormatJSON:
+		ret.OfJSONObject = &shared.ResponseFormatJSONObjectParam{}
+	default:
+		ret.OfText = &shared.ResponseFormatTextParam{}
+	}
+	p.params.Text.Format = ret
+	return p
+}
+
 func (p *params) BaseURL(base string) xai.GenParams {
 	p.opts = append(p.opts, option.WithBaseURL(base))
 	return p
 }
 
+// Timeout sets the timeout of each attempt of the request, as requests are
+// retried by util.RetryMiddleware rather than the SDK.
 func (p *params) Timeout(timeout time.Duration) xai.GenParams {
-	p.opts = append(p.opts, option.WithRequestTimeout(timeout))
+	p.timeout = timeout
+	return p
+}
+
+func (p *params) Retry(policy xai.RetryPolicy) xai.GenParams {
+	p.retry = &policy
 	return p
 }
 
@@ -299,7 +384,7 @@ func (p *Service) GenParams() xai.GenParams {
 	return &params{}
 }
 
-func buildParams(in xai.GenParams) (responses.ResponseNewParams, []option.RequestOption) {
+func buildParams(ctx context.Context, in xai.GenParams) (context.Context, responses.ResponseNewParams, []option.RequestOption) {
 	p := in.(*params)
 	// TODO(xsw): check param values
 	// Merge system prompt and messages into input param
@@ -307,8 +392,18 @@ func buildParams(in xai.GenParams) (responses.ResponseNewParams, []option.Reques
 	if len(p.sys) > 0 {
 		sys = responses.ResponseInputItemParamOfMessage(p.sys, responses.EasyInputMessageRoleSystem)
 	}
-	p.params.Input = buildMessages(p.msgs, sys)
-	return p.params, p.opts
+	// the input and the cache retention are set on a copy, so that p can be built
+	// again with other messages.
+	params := p.params
+	params.Input = buildMessages(p.msgs, sys)
+	ttl := p.cache
+	for _, msg := range p.msgs {
+		ttl = max(ttl, msg.(*msgBuilder).cache)
+	}
+	if ttl > time.Hour {
+		params.PromptCacheRetention = responses.ResponseNewParamsPromptCacheRetention24h
+	}
+	return util.WithTimeout(util.WithRetryPolicy(ctx, p.retry), p.timeout), params, p.opts
 }
 
 // -----------------------------------------------------------------------------
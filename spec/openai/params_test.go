@@ -0,0 +1,75 @@
+/*
+ * Copyright (c) 2026 The XGo Authors (xgo.dev). All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package openai
+
+import (
+	"encoding/json"
+	"slices"
+	"testing"
+
+	"github.com/goplus/xai"
+	"github.com/openai/openai-go/v3/responses"
+	"github.com/openai/openai-go/v3/shared"
+)
+
+// -----------------------------------------------------------------------------
+
+func TestThinking(t *testing.T) {
+	svc := newService(t)
+	cases := []struct {
+		conf      xai.ThinkingConfig
+		effort    shared.ReasoningEffort
+		summary   shared.ReasoningSummary
+		encrypted bool
+	}{
+		{xai.ThinkingBudget(2048, false), shared.ReasoningEffortLow, "", true},
+		{xai.ThinkingBudget(8192, true), shared.ReasoningEffortMedium, shared.ReasoningSummaryAuto, true},
+		{xai.ThinkingEffort(xai.EffortHigh, true), shared.ReasoningEffortHigh, shared.ReasoningSummaryAuto, true},
+		{xai.NoThinking(), shared.ReasoningEffortNone, "", false},
+		{xai.ThinkingConfig{}, "", "", true},
+	}
+	for _, c := range cases {
+		p := svc.GenParams().Thinking(c.conf).(*params).params
+		encrypted := slices.Contains(p.Include, responses.ResponseIncludableReasoningEncryptedContent)
+		if p.Reasoning.Effort != c.effort || p.Reasoning.Summary != c.summary || encrypted != c.encrypted {
+			t.Errorf("Thinking(%+v): effort %q, summary %q, encrypted %v", c.conf, p.Reasoning.Effort, p.Reasoning.Summary, encrypted)
+		}
+	}
+}
+
+func TestToolChoice(t *testing.T) {
+	svc := newService(t)
+	cases := []struct {
+		choice   xai.ToolChoice
+		parallel bool
+		want     string
+	}{
+		{xai.ToolChoice{}, true, `"auto"`},
+		{xai.ToolChoice{Mode: xai.ToolChoiceAny}, false, `"required"`},
+		{xai.ToolChoice{Mode: xai.ToolChoiceNone}, true, `"none"`},
+		{xai.UseTool("f"), false, `{"name":"f","type":"function"}`},
+	}
+	for _, c := range cases {
+		p := svc.GenParams().ToolChoice(c.choice).ParallelToolCalls(c.parallel).(*params).params
+		b, _ := json.Marshal(p.ToolChoice)
+		if string(b) != c.want || p.ParallelToolCalls.Value != c.parallel {
+			t.Errorf("ToolChoice(%+v): got %s, parallel %v", c.choice, b, p.ParallelToolCalls.Value)
+		}
+	}
+}
+
+// -----------------------------------------------------------------------------
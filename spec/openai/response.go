@@ -37,24 +37,69 @@ func (p contentBlock) AsThinking() (ret xai.Thinking, ok bool) {
 	switch p.content.Type {
 	case "reasoning":
 		u := p.content.AsReasoning()
+		ret.Text = reasoningText(&u)
+		ret.Signature = reasoningSignature(u.ID, u.EncryptedContent)
 		ret.Underlying = &u
 	default:
 		return
 	}
 	ok = true
-	panic("todo")
+	return
+}
+
+// reasoningText returns the summary of the reasoning item, or its content if
+// there is no summary.
+func reasoningText(u *responses.ResponseReasoningItem) string {
+	var b strings.Builder
+	for i, s := range u.Summary {
+		if i > 0 {
+			b.WriteString("\n\n")
+		}
+		b.WriteString(s.Text)
+	}
+	if b.Len() == 0 {
+		for _, c := range u.Content {
+			b.WriteString(c.Text)
+		}
+	}
+	return b.String()
+}
+
+// reasoningSignature encodes the ID and the encrypted content of a reasoning item
+// as the signature of xai.Thinking, in the form "id" or "id:encrypted_content".
+// Both are needed to pass the reasoning item back to the model.
+func reasoningSignature(id, encrypted string) string {
+	if encrypted == "" {
+		return id
+	}
+	return id + ":" + encrypted
+}
+
+func parseReasoningSignature(sig string) (id, encrypted string) {
+	id, encrypted, _ = strings.Cut(sig, ":")
+	return
 }
 
 func (p contentBlock) AsToolUse() (ret xai.ToolUse, ok bool) {
 	switch p.content.Type {
 	case "function_call":
 		u := p.content.AsFunctionCall()
-		ret.ID = u.ID
+		ret.ID = u.CallID // function_call_output refers to the call ID
 		ret.Name = u.Name
 		ret.Input = rawMessage(u.Arguments)
 		ret.Underlying = &u
-	case "file_search_call", "web_search_call", "computer_call", "code_interpreter_call",
-		"local_shell_call", "shell_call", "apply_patch_call", "mcp_call", "custom_tool_call":
+	case "web_search_call":
+		// web searches are done by the server, and their results are in the
+		// following message.
+		u := p.content.AsWebSearchCall()
+		ret.ID = u.ID
+		ret.Name = xai.ToolWebSearch
+		ret.Input = rawMessage(u.Action.RawJSON())
+		ret.Underlying = &u
+	case "file_search_call", "code_interpreter_call", "mcp_call":
+		// other server tools aren't standard tools yet
+		return
+	case "computer_call", "local_shell_call", "shell_call", "apply_patch_call", "custom_tool_call":
 		panic("todo")
 	default:
 		return
@@ -64,11 +109,19 @@ func (p contentBlock) AsToolUse() (ret xai.ToolUse, ok bool) {
 }
 
 func (p contentBlock) AsToolResult() (ret xai.ToolResult, ok bool) {
-	panic("todo")
+	// the results of built-in tools are parts of their calls, and the results of
+	// function calls are never in responses, so this always returns false.
+	return
 }
 
 func (p contentBlock) AsBlob() (ret xai.Blob, ok bool) {
-	panic("todo")
+	if p.content.Type == "image_generation_call" && p.content.Result != "" {
+		// the image generation tool outputs png by default
+		ret.MIME = string(xai.ImagePNG)
+		ret.BlobData = xai.BlobFromBase64(p.content.Result)
+		ok = true
+	}
+	return
 }
 
 func (p contentBlock) AsCompaction() (ret xai.Compaction, ok bool) {
@@ -96,6 +149,49 @@ func (p contentBlock) Text() string {
 	return outputText.String()
 }
 
+// Citations returns the citations of the annotations of output texts, whose
+// character indices are converted to byte offsets of Text.
+func (p contentBlock) Citations() (ret []xai.Citation) {
+	base := 0
+	for _, content := range p.content.Content {
+		if content.Type != "output_text" {
+			continue
+		}
+		for i := range content.Annotations {
+			a := &content.Annotations[i]
+			c := xai.Citation{URL: a.URL, Title: a.Title, DocIndex: -1, Underlying: a}
+			switch a.Type {
+			case "url_citation", "container_file_citation":
+				c.Start, c.End = byteOffset(content.Text, a.StartIndex), byteOffset(content.Text, a.EndIndex)
+			case "file_citation":
+				c.Start = byteOffset(content.Text, a.Index)
+				c.End = c.Start
+			default: // file_path, etc.
+				continue
+			}
+			if a.Filename != "" {
+				c.Title = a.Filename
+			}
+			c.Start += base
+			c.End += base
+			ret = append(ret, c)
+		}
+		base += len(content.Text)
+	}
+	return
+}
+
+// byteOffset converts the character index i of s to its byte offset.
+func byteOffset(s string, i int64) int {
+	for off := range s {
+		if i <= 0 {
+			return off
+		}
+		i--
+	}
+	return len(s)
+}
+
 func (p contentBlock) Underlying() any {
 	return p.content
 }
@@ -122,9 +218,6 @@ func (p response) StopReason() xai.StopReason {
 		case "content_filter":
 			return xai.Refusal
 		}
-	default:
-		// TODO(xsw): map other status to stop reason.
-		panic("todo")
 	}
 	return xai.Unspecified
 }
@@ -138,9 +231,38 @@ func (p response) Part(i int) xai.Part {
 }
 
 func buildPart(part xai.Part) responses.ResponseInputItemUnionParam {
+	if item, ok := part.Underlying().(*responses.ResponseOutputItemUnion); ok {
+		if ret, ok := outputItemParam(item); ok {
+			return ret
+		}
+	}
 	panic("todo")
 }
 
+// outputItemParam converts an output item of a response to an input item, so that
+// it can be passed back to the model in the next turn.
+func outputItemParam(item *responses.ResponseOutputItemUnion) (ret responses.ResponseInputItemUnionParam, ok bool) {
+	switch item.Type {
+	case "message":
+		v := item.AsMessage().ToParam()
+		ret.OfOutputMessage = &v
+	case "reasoning":
+		v := item.AsReasoning().ToParam()
+		ret.OfReasoning = &v
+	case "function_call":
+		v := item.AsFunctionCall().ToParam()
+		ret.OfFunctionCall = &v
+	case "web_search_call":
+		v := item.AsWebSearchCall().ToParam()
+		ret.OfWebSearchCall = &v
+	case "compaction":
+		ret = responses.ResponseInputItemParamOfCompaction(item.AsCompaction().EncryptedContent)
+	default:
+		return
+	}
+	return ret, true
+}
+
 func (p response) Len() int {
 	return 1
 }
@@ -152,14 +274,78 @@ func (p response) At(i int) xai.Candidate {
 	return p
 }
 
+func (p response) Usage() xai.Usage {
+	u := &p.msg.Usage
+	return xai.Usage{
+		InputTokens:     u.InputTokens,
+		OutputTokens:    u.OutputTokens,
+		CacheReadTokens: u.InputTokensDetails.CachedTokens,
+		ReasoningTokens: u.OutputTokensDetails.ReasoningTokens,
+	}
+}
+
 func (p response) ToMsg() xai.MsgBuilder {
-	panic("todo")
+	ret := &msgBuilder{role: responses.EasyInputMessageRoleAssistant}
+	for i := range p.msg.Output {
+		ret.Part(contentBlock{&p.msg.Output[i]})
+	}
+	return ret
 }
 
 // -----------------------------------------------------------------------------
 
-func buildRespIter(stream *ssestream.Stream[responses.ResponseStreamEventUnion]) iter.Seq2[xai.GenResponse, error] {
-	panic("todo")
+func buildRespIter(stream *ssestream.Stream[responses.ResponseStreamEventUnion]) iter.Seq2[xai.StreamEvent, error] {
+	return func(yield func(xai.StreamEvent, error) bool) {
+		defer stream.Close()
+		for stream.Next() {
+			event := stream.Current()
+			ev := xai.StreamEvent{Index: int(event.OutputIndex), Underlying: &event}
+			switch event.Type {
+			case "response.created":
+				ev.Type = xai.EventStart
+			case "response.output_item.added", "response.output_item.done":
+				ev.Type = xai.EventBlockStart
+				if event.Type == "response.output_item.done" {
+					ev.Type = xai.EventBlockStop
+				}
+				switch item := &event.Item; item.Type {
+				case "message":
+					ev.Block = xai.BlockText
+				case "reasoning":
+					ev.Block = xai.BlockThinking
+				case "function_call":
+					ev.Block = xai.BlockToolUse
+					ev.ToolUse = xai.ToolUse{ID: item.CallID, Name: item.Name}
+				}
+			case "response.output_text.delta":
+				ev.Type, ev.Block, ev.Delta = xai.EventDelta, xai.BlockText, event.Delta
+			case "response.reasoning_summary_text.delta", "response.reasoning_text.delta":
+				ev.Type, ev.Block, ev.Delta = xai.EventDelta, xai.BlockThinking, event.Delta
+			case "response.reasoning_summary_part.added":
+				if event.SummaryIndex == 0 {
+					continue
+				}
+				// summary parts are separated by blank lines, same as reasoningText
+				ev.Type, ev.Block, ev.Delta = xai.EventDelta, xai.BlockThinking, "\n\n"
+			case "response.function_call_arguments.delta":
+				ev.Type, ev.Block, ev.Delta = xai.EventDelta, xai.BlockToolUse, event.Delta
+			case "response.completed", "response.incomplete":
+				ev.Type, ev.Index = xai.EventStop, 0
+				ev.Response = response{&event.Response}
+			case "response.failed":
+				yield(xai.StreamEvent{}, failedError(&event.Response))
+				return
+			default:
+				continue
+			}
+			if !yield(ev, nil) {
+				return
+			}
+		}
+		if err := stream.Err(); err != nil {
+			yield(xai.StreamEvent{}, translateError(err))
+		}
+	}
 }
 
 // -----------------------------------------------------------------------------
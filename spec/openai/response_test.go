@@ -0,0 +1,147 @@
+/*
+ * Copyright (c) 2026 The XGo Authors (xgo.dev). All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package openai
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/goplus/xai"
+	"github.com/openai/openai-go/v3/responses"
+)
+
+// -----------------------------------------------------------------------------
+
+func newService(t *testing.T) *Service {
+	svc, err := New(context.Background(), "openai:key=test")
+	if err != nil {
+		t.Fatal("New:", err)
+	}
+	return svc.(*Service)
+}
+
+func TestNoOperation(t *testing.T) {
+	svc := newService(t)
+	if svc.Features()&xai.FeatureOperation != 0 {
+		t.Fatal("Features: unexpected FeatureOperation")
+	}
+	if actions := svc.Actions("gpt-5"); len(actions) != 0 {
+		t.Fatal("Actions:", actions)
+	}
+	if _, err := svc.Operation("gpt-5", xai.GenImage); !errors.Is(err, xai.ErrNotFound) {
+		t.Fatal("Operation: want ErrNotFound, got", err)
+	}
+}
+
+func TestToolUseParam(t *testing.T) {
+	msg := newService(t).AssistantMsg().ToolUse(xai.ToolUse{ID: "call_1", Name: "f", Input: map[string]int{"a": 1}})
+	fn := msg.(*msgBuilder).content[0].OfFunctionCall
+	if fn == nil || fn.CallID != "call_1" || fn.Name != "f" || fn.Arguments != `{"a":1}` {
+		t.Fatalf("ToolUse: %+v", fn)
+	}
+}
+
+func TestStopReason(t *testing.T) {
+	cases := []struct {
+		status responses.ResponseStatus
+		reason string
+		want   xai.StopReason
+	}{
+		{responses.ResponseStatusCompleted, "", xai.EndTurn},
+		{responses.ResponseStatusIncomplete, "max_output_tokens", xai.StopMaxTokens},
+		{responses.ResponseStatusIncomplete, "content_filter", xai.Refusal},
+		{responses.ResponseStatusFailed, "", xai.Unspecified},
+		{responses.ResponseStatusCancelled, "", xai.Unspecified},
+		{responses.ResponseStatusInProgress, "", xai.Unspecified},
+	}
+	for _, c := range cases {
+		msg := &responses.Response{Status: c.status, IncompleteDetails: responses.ResponseIncompleteDetails{Reason: c.reason}}
+		if got := (response{msg}).StopReason(); got != c.want {
+			t.Errorf("StopReason of %s %s: got %v, want %v", c.status, c.reason, got, c.want)
+		}
+	}
+}
+
+func TestToolUseID(t *testing.T) {
+	var item responses.ResponseOutputItemUnion
+	err := json.Unmarshal([]byte(`{
+		"type": "function_call", "id": "fc_1", "call_id": "call_1", "name": "f", "arguments": "{}"
+	}`), &item)
+	if err != nil {
+		t.Fatal("Unmarshal:", err)
+	}
+	// function_call_output refers to the call ID rather than the item ID
+	if use, ok := (contentBlock{&item}).AsToolUse(); !ok || use.ID != "call_1" || use.Name != "f" {
+		t.Fatalf("AsToolUse: %+v", use)
+	}
+}
+
+func TestReasoningSignature(t *testing.T) {
+	cases := []struct {
+		id, encrypted, sig string
+	}{
+		{"rs_1", "", "rs_1"},
+		{"rs_1", "gAAA==", "rs_1:gAAA=="},
+		{"rs_1", "a:b", "rs_1:a:b"},
+	}
+	for _, c := range cases {
+		if sig := reasoningSignature(c.id, c.encrypted); sig != c.sig {
+			t.Errorf("reasoningSignature(%q, %q): got %q", c.id, c.encrypted, sig)
+		}
+		if id, encrypted := parseReasoningSignature(c.sig); id != c.id || encrypted != c.encrypted {
+			t.Errorf("parseReasoningSignature(%q): got %q, %q", c.sig, id, encrypted)
+		}
+	}
+}
+
+func TestThinkingMsg(t *testing.T) {
+	svc := newService(t)
+	msg := svc.AssistantMsg().
+		Thinking(xai.Thinking{Text: "hmm", Signature: "rs_1:enc"}).
+		ToolUse(xai.ToolUse{ID: "call_1", Name: "f", Input: map[string]int{}}).
+		Text("done")
+	items := buildMessages([]xai.MsgBuilder{svc.UserMsg().Text("hi"), msg}, responses.ResponseInputItemUnionParam{}).OfInputItemList
+	// all items of a message are sent, not only the first one
+	if len(items) != 4 || items[0].OfMessage == nil || items[2].OfFunctionCall == nil || items[3].OfMessage == nil {
+		t.Fatalf("buildMessages: %d items", len(items))
+	}
+	r := items[1].OfReasoning
+	if r == nil || r.ID != "rs_1" || r.EncryptedContent.Value != "enc" || len(r.Summary) != 1 || r.Summary[0].Text != "hmm" {
+		t.Fatalf("Thinking: %+v", r)
+	}
+}
+
+func TestUsage(t *testing.T) {
+	var msg responses.Response
+	err := json.Unmarshal([]byte(`{"usage": {
+		"input_tokens": 100, "input_tokens_details": {"cached_tokens": 40},
+		"output_tokens": 50, "output_tokens_details": {"reasoning_tokens": 20},
+		"total_tokens": 150
+	}}`), &msg)
+	if err != nil {
+		t.Fatal("Unmarshal:", err)
+	}
+	// openai doesn't report cache writes
+	want := xai.Usage{InputTokens: 100, OutputTokens: 50, CacheReadTokens: 40, ReasoningTokens: 20}
+	if got := (response{&msg}).Usage(); got != want {
+		t.Fatalf("Usage: got %+v, want %+v", got, want)
+	}
+}
+
+// -----------------------------------------------------------------------------
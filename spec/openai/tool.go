@@ -43,8 +43,19 @@ func (p tool) Description(desc string) xai.Tool {
 	return p
 }
 
+func (p tool) InputSchema(schema any) xai.Tool {
+	p.tool.Parameters = xai.ParseSchema(schema)
+	// strict mode requires all properties to be required, which is not true for
+	// arbitrary schemas, so we turn it off explicitly (it is on by default).
+	p.tool.Strict = param.NewOpt(false)
+	return p
+}
+
 func (p *Service) Tool(name string) xai.Tool {
-	return p.tools[name]
+	if t, ok := p.tools[name]; ok {
+		return t
+	}
+	return nil
 }
 
 func (p *Service) ToolDef(name string) xai.Tool {
@@ -105,15 +116,15 @@ func (p *msgBuilder) ToolUse(v xai.ToolUse) xai.MsgBuilder {
 		panic("todo")
 	} else {
 		args := jsonStringify(v.Input, "invalid tool input: ")
-		content = responses.ResponseInputItemParamOfFunctionCall(v.ID, args, v.Name)
+		content = responses.ResponseInputItemParamOfFunctionCall(args, v.ID, v.Name)
 	}
 	return p.addNonMsg(content)
 }
 
 func jsonStringify(v any, errPrompt string) string {
 	var args []byte
-	if v, ok := v.(json.RawMessage); ok {
-		args = []byte(v)
+	if raw, ok := v.(json.RawMessage); ok {
+		args = []byte(raw)
 	} else {
 		var err error
 		args, err = json.Marshal(v)
@@ -0,0 +1,134 @@
+/*
+ * Copyright (c) 2026 The XGo Authors (xgo.dev). All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package openai
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/goplus/xai"
+	"github.com/openai/openai-go/v3/responses"
+)
+
+// -----------------------------------------------------------------------------
+
+// ExportMsg exports the message to the transcript format.
+func (p *msgBuilder) ExportMsg() (*xai.Message, error) {
+	ret := &xai.Message{Role: xai.RoleUser}
+	if p.role == responses.EasyInputMessageRoleAssistant {
+		ret.Role = xai.RoleAssistant
+	}
+	for i := range p.content {
+		parts, err := exportItem(&p.content[i])
+		if err != nil {
+			return nil, err
+		}
+		ret.Parts = append(ret.Parts, parts...)
+	}
+	return ret, nil
+}
+
+func exportItem(item *responses.ResponseInputItemUnionParam) (ret []xai.MessagePart, err error) {
+	switch {
+	case item.OfMessage != nil:
+		content := &item.OfMessage.Content
+		if content.OfString.Valid() {
+			return []xai.MessagePart{{Type: xai.PartText, Text: content.OfString.Value}}, nil
+		}
+		for i := range content.OfInputItemContentList {
+			part, err := exportContent(&content.OfInputItemContentList[i])
+			if err != nil {
+				return nil, err
+			}
+			ret = append(ret, part)
+		}
+		return
+	case item.OfOutputMessage != nil, item.OfReasoning != nil, item.OfFunctionCall != nil:
+		return exportOutputItem(item)
+	case item.OfFunctionCallOutput != nil:
+		v := item.OfFunctionCallOutput
+		if !v.Output.OfString.Valid() {
+			break
+		}
+		return []xai.MessagePart{xai.ToolResultPart(v.CallID, "", v.Output.OfString.Value, false)}, nil
+	case item.OfCompaction != nil:
+		return []xai.MessagePart{{Type: xai.PartCompaction, Text: item.OfCompaction.EncryptedContent, Provider: Scheme}}, nil
+	}
+	return nil, fmt.Errorf("openai: input item: %w", xai.ErrNotExportable)
+}
+
+// exportOutputItem exports an output item passed back to the model. It is decoded
+// from JSON, as the items converted by ToParam only hold their raw JSON.
+func exportOutputItem(v *responses.ResponseInputItemUnionParam) (ret []xai.MessagePart, err error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return
+	}
+	var item responses.ResponseOutputItemUnion
+	if err = json.Unmarshal(b, &item); err != nil {
+		return
+	}
+	switch item.Type {
+	case "message":
+		for _, c := range item.AsMessage().Content {
+			switch c.Type {
+			case "output_text":
+				ret = append(ret, xai.MessagePart{Type: xai.PartText, Text: c.Text})
+			case "refusal":
+				ret = append(ret, xai.MessagePart{Type: xai.PartText, Text: c.Refusal})
+			}
+		}
+	case "reasoning":
+		u := item.AsReasoning()
+		ret = []xai.MessagePart{{
+			Type: xai.PartThinking, Text: reasoningText(&u),
+			Signature: reasoningSignature(u.ID, u.EncryptedContent), Provider: Scheme,
+		}}
+	default: // function_call
+		u := item.AsFunctionCall()
+		ret = []xai.MessagePart{{Type: xai.PartToolUse, ID: u.CallID, Name: u.Name, Input: xai.RawMessage(u.Arguments)}}
+	}
+	return
+}
+
+func exportContent(c *responses.ResponseInputContentUnionParam) (ret xai.MessagePart, err error) {
+	switch {
+	case c.OfInputText != nil:
+		return xai.MessagePart{Type: xai.PartText, Text: c.OfInputText.Text}, nil
+	case c.OfInputImage != nil:
+		v := c.OfInputImage
+		if v.FileID.Valid() {
+			return xai.MessagePart{Type: xai.PartImage, FileID: v.FileID.Value}, nil
+		}
+		if url := v.ImageURL.Value; !strings.HasPrefix(url, "data:") {
+			return xai.MessagePart{Type: xai.PartImage, URL: url}, nil
+		}
+		return xai.BlobPart(xai.PartImage, "", "", v.ImageURL.Value)
+	default: // c.OfInputFile != nil
+		v := c.OfInputFile
+		switch {
+		case v.FileID.Valid():
+			return xai.MessagePart{Type: xai.PartDoc, FileID: v.FileID.Value}, nil
+		case v.FileURL.Valid():
+			return xai.MessagePart{Type: xai.PartDoc, URL: v.FileURL.Value}, nil
+		}
+		return xai.BlobPart(xai.PartDoc, "", v.Filename.Value, v.FileData.Value)
+	}
+}
+
+// -----------------------------------------------------------------------------
@@ -0,0 +1,387 @@
+/*
+ * Copyright (c) 2026 The XGo Authors (xgo.dev). All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package router
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/goplus/xai"
+)
+
+// -----------------------------------------------------------------------------
+
+// msgBuilder records the content of a message, which is added to the MsgBuilder
+// of the backend that serves the request by buildMsg.
+type msgBuilder struct {
+	assistant bool
+	from      *candidate // set by Candidate.ToMsg
+	def       *backend   // the backend to export the message by, if from is nil
+	ops       []func(b *backend, m xai.MsgBuilder)
+}
+
+func (p *msgBuilder) add(op func(b *backend, m xai.MsgBuilder)) xai.MsgBuilder {
+	p.ops = append(p.ops, op)
+	return p
+}
+
+// buildMsg builds msg for the backend b. Messages not created by the router are
+// passed as is.
+func buildMsg(b *backend, msg xai.MsgBuilder) xai.MsgBuilder {
+	p, ok := msg.(*msgBuilder)
+	if !ok {
+		return msg
+	}
+	var ret xai.MsgBuilder
+	switch {
+	case p.from != nil && p.from.b == b:
+		ret = p.from.Candidate.ToMsg()
+	case p.assistant:
+		ret = b.svc.AssistantMsg()
+	default:
+		ret = b.svc.UserMsg()
+	}
+	if p.from != nil && p.from.b != b {
+		for i, n := 0, p.from.Parts(); i < n; i++ {
+			convertPart(ret, p.from.Candidate.Part(i))
+		}
+	}
+	for _, op := range p.ops {
+		op(b, ret)
+	}
+	return ret
+}
+
+// ExportMsg exports the message to the transcript format, as built for the
+// backend that returns it, or the first backend.
+func (p *msgBuilder) ExportMsg() (*xai.Message, error) {
+	b := p.def
+	if p.from != nil {
+		b = p.from.b
+	}
+	return xai.ExportMsg(buildMsg(b, p))
+}
+
+// convertPart adds a part returned by another backend to m. Thinking, compaction
+// and blob parts, as well as the uses and results of standard tools, are dropped
+// as they only work with the backend that returns them.
+func convertPart(m xai.MsgBuilder, part xai.Part) {
+	if use, ok := part.AsToolUse(); ok {
+		if !strings.HasPrefix(use.Name, "std/") {
+			use.Underlying = nil
+			m.ToolUse(use)
+		}
+	} else if result, ok := part.AsToolResult(); ok {
+		if !strings.HasPrefix(result.Name, "std/") {
+			result.Underlying = nil
+			m.ToolResult(result)
+		}
+	} else if text := part.Text(); text != "" {
+		if _, ok := part.AsThinking(); !ok {
+			m.Text(text)
+		}
+	}
+}
+
+func (p *msgBuilder) Text(text string) xai.MsgBuilder {
+	return p.add(func(_ *backend, m xai.MsgBuilder) {
+		m.Text(text)
+	})
+}
+
+func (p *msgBuilder) Image(image xai.ImageData) xai.MsgBuilder {
+	return p.add(func(b *backend, m xai.MsgBuilder) {
+		if v, ok := image.(*blob); ok {
+			image = v.image(b)
+		}
+		m.Image(image)
+	})
+}
+
+func (p *msgBuilder) ImageURL(mime xai.ImageType, url string) xai.MsgBuilder {
+	return p.add(func(_ *backend, m xai.MsgBuilder) {
+		m.ImageURL(mime, url)
+	})
+}
+
+func (p *msgBuilder) ImageFile(mime xai.ImageType, fileID string) xai.MsgBuilder {
+	return p.add(func(_ *backend, m xai.MsgBuilder) {
+		m.ImageFile(mime, fileID)
+	})
+}
+
+func (p *msgBuilder) Doc(doc xai.DocumentData) xai.MsgBuilder {
+	return p.add(func(b *backend, m xai.MsgBuilder) {
+		if v, ok := doc.(*blob); ok {
+			doc = v.doc(b)
+		}
+		m.Doc(doc)
+	})
+}
+
+func (p *msgBuilder) DocURL(mime xai.DocumentType, url string) xai.MsgBuilder {
+	return p.add(func(_ *backend, m xai.MsgBuilder) {
+		m.DocURL(mime, url)
+	})
+}
+
+func (p *msgBuilder) DocFile(mime xai.DocumentType, fileID string) xai.MsgBuilder {
+	return p.add(func(_ *backend, m xai.MsgBuilder) {
+		m.DocFile(mime, fileID)
+	})
+}
+
+func (p *msgBuilder) DocCitations() xai.MsgBuilder {
+	return p.add(func(_ *backend, m xai.MsgBuilder) {
+		m.DocCitations()
+	})
+}
+
+// Part adds a part of a response. It is passed as is to the backend returning it,
+// and converted for other backends, see convertPart.
+func (p *msgBuilder) Part(part xai.Part) xai.MsgBuilder {
+	return p.add(func(b *backend, m xai.MsgBuilder) {
+		if v, ok := part.(partOf); ok {
+			if v.b == b {
+				m.Part(v.Part)
+			} else {
+				convertPart(m, v.Part)
+			}
+			return
+		}
+		m.Part(part)
+	})
+}
+
+func (p *msgBuilder) Thinking(v xai.Thinking) xai.MsgBuilder {
+	return p.add(func(_ *backend, m xai.MsgBuilder) {
+		m.Thinking(v)
+	})
+}
+
+func (p *msgBuilder) ToolUse(v xai.ToolUse) xai.MsgBuilder {
+	return p.add(func(_ *backend, m xai.MsgBuilder) {
+		m.ToolUse(v)
+	})
+}
+
+func (p *msgBuilder) ToolResult(v xai.ToolResult) xai.MsgBuilder {
+	return p.add(func(_ *backend, m xai.MsgBuilder) {
+		m.ToolResult(v)
+	})
+}
+
+func (p *msgBuilder) Compaction(data string) xai.MsgBuilder {
+	return p.add(func(_ *backend, m xai.MsgBuilder) {
+		m.Compaction(data)
+	})
+}
+
+func (p *msgBuilder) CacheBreakpoint(ttl time.Duration) xai.MsgBuilder {
+	return p.add(func(_ *backend, m xai.MsgBuilder) {
+		m.CacheBreakpoint(ttl)
+	})
+}
+
+func (p *Service) UserMsg() xai.MsgBuilder {
+	return &msgBuilder{def: p.backends[0]}
+}
+
+func (p *Service) AssistantMsg() xai.MsgBuilder {
+	return &msgBuilder{assistant: true, def: p.backends[0]}
+}
+
+// -----------------------------------------------------------------------------
+
+// blob implements both xai.ImageData and xai.DocumentData. It is created by the
+// ImageBuilder or DocumentBuilder of the backend that serves the request.
+type blob struct {
+	xai.Blob
+	text bool // created by PlainText
+}
+
+func (p *blob) ImageType() xai.ImageType {
+	return xai.ImageType(p.MIME)
+}
+
+func (p *blob) DocumentType() xai.DocumentType {
+	return xai.DocumentType(p.MIME)
+}
+
+func (p *blob) image(b *backend) xai.ImageData {
+	data, _ := p.Raw()
+	return b.svc.Images().FromBytes(xai.ImageType(p.MIME), p.DisplayName, data)
+}
+
+func (p *blob) doc(b *backend) xai.DocumentData {
+	data, _ := p.Raw()
+	if p.text {
+		return b.svc.Docs().PlainText(string(data))
+	}
+	return b.svc.Docs().FromBytes(xai.DocumentType(p.MIME), p.DisplayName, data)
+}
+
+func newBlob(mime, displayName string, data xai.BlobData) *blob {
+	return &blob{Blob: xai.Blob{BlobData: data, DisplayName: displayName, MIME: mime}}
+}
+
+type images struct{}
+
+func (images) From(mime xai.ImageType, displayName string, src io.Reader) (xai.ImageData, error) {
+	data, err := io.ReadAll(src)
+	if err != nil {
+		return nil, err
+	}
+	return newBlob(string(mime), displayName, xai.BlobFromRaw(data)), nil
+}
+
+func (images) FromLocal(mime xai.ImageType, fileName string) (xai.ImageData, error) {
+	data, err := os.ReadFile(fileName)
+	if err != nil {
+		return nil, err
+	}
+	return newBlob(string(mime), filepath.Base(fileName), xai.BlobFromRaw(data)), nil
+}
+
+func (images) FromBase64(mime xai.ImageType, displayName string, base64 string) (xai.ImageData, error) {
+	data := xai.BlobFromBase64(base64)
+	if _, err := data.Raw(); err != nil {
+		return nil, err
+	}
+	return newBlob(string(mime), displayName, data), nil
+}
+
+func (images) FromBytes(mime xai.ImageType, displayName string, data []byte) xai.ImageData {
+	return newBlob(string(mime), displayName, xai.BlobFromRaw(data))
+}
+
+type docs struct{}
+
+func (docs) From(mime xai.DocumentType, displayName string, src io.Reader) (xai.DocumentData, error) {
+	data, err := io.ReadAll(src)
+	if err != nil {
+		return nil, err
+	}
+	return newBlob(string(mime), displayName, xai.BlobFromRaw(data)), nil
+}
+
+func (docs) FromLocal(mime xai.DocumentType, fileName string) (xai.DocumentData, error) {
+	data, err := os.ReadFile(fileName)
+	if err != nil {
+		return nil, err
+	}
+	return newBlob(string(mime), filepath.Base(fileName), xai.BlobFromRaw(data)), nil
+}
+
+func (docs) FromBase64(mime xai.DocumentType, displayName string, base64 string) (xai.DocumentData, error) {
+	data := xai.BlobFromBase64(base64)
+	if _, err := data.Raw(); err != nil {
+		return nil, err
+	}
+	return newBlob(string(mime), displayName, data), nil
+}
+
+func (docs) FromBytes(mime xai.DocumentType, displayName string, data []byte) xai.DocumentData {
+	return newBlob(string(mime), displayName, xai.BlobFromRaw(data))
+}
+
+func (docs) PlainText(text string) xai.DocumentData {
+	ret := newBlob(string(xai.DocPlainText), "", xai.BlobFromRaw([]byte(text)))
+	ret.text = true
+	return ret
+}
+
+func (p *Service) Images() xai.ImageBuilder {
+	return images{}
+}
+
+func (p *Service) Docs() xai.DocumentBuilder {
+	return docs{}
+}
+
+// -----------------------------------------------------------------------------
+
+// response is a response of the backend b. Its parts remember b, so that they
+// are passed back as is to b.
+type response struct {
+	xai.GenResponse
+	b *backend
+}
+
+func (p response) At(i int) xai.Candidate {
+	return &candidate{p.GenResponse.At(i), p.b}
+}
+
+type candidate struct {
+	xai.Candidate
+	b *backend
+}
+
+func (p *candidate) Part(i int) xai.Part {
+	return partOf{p.Candidate.Part(i), p.b}
+}
+
+func (p *candidate) ToMsg() xai.MsgBuilder {
+	return &msgBuilder{assistant: true, from: p}
+}
+
+type partOf struct {
+	xai.Part
+	b *backend
+}
+
+// -----------------------------------------------------------------------------
+
+// batch is a batch of the backend b, whose responses remember b as responses of
+// Gen do.
+type batch struct {
+	xai.BatchResponse
+	b *backend
+}
+
+func (p batch) Results() xai.Results {
+	return batchResults{p.BatchResponse.Results(), p.b}
+}
+
+func (p batch) Wait(ctx context.Context, params xai.WaitParams) (xai.Results, error) {
+	results, err := p.BatchResponse.Wait(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+	return batchResults{results, p.b}, nil
+}
+
+type batchResults struct {
+	xai.Results
+	b *backend
+}
+
+func (p batchResults) At(i int) xai.Generated {
+	r, ok := p.Results.At(i).(*xai.BatchResult)
+	if !ok || r.Response == nil {
+		return p.Results.At(i)
+	}
+	ret := *r
+	ret.Response = response{r.Response, p.b}
+	return &ret
+}
+
+// -----------------------------------------------------------------------------
@@ -0,0 +1,166 @@
+/*
+ * Copyright (c) 2026 The XGo Authors (xgo.dev). All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package router
+
+import (
+	"time"
+
+	"github.com/goplus/xai"
+)
+
+// -----------------------------------------------------------------------------
+
+// genParams records the parameters, which are set to the GenParams of the backend
+// that serves the request by build.
+type genParams struct {
+	model xai.Model
+	ops   []func(b *backend, params xai.GenParams)
+}
+
+func (p *genParams) add(op func(b *backend, params xai.GenParams)) xai.GenParams {
+	p.ops = append(p.ops, op)
+	return p
+}
+
+func (p *genParams) build(t target) xai.GenParams {
+	ret := t.b.svc.GenParams()
+	for _, op := range p.ops {
+		op(t.b, ret)
+	}
+	return ret.Model(t.model)
+}
+
+func (p *genParams) Set(name string, val any) xai.GenParams {
+	return p.add(func(_ *backend, params xai.GenParams) {
+		params.Set(name, val)
+	})
+}
+
+func (p *genParams) System(prompt ...string) xai.GenParams {
+	return p.add(func(_ *backend, params xai.GenParams) {
+		params.System(prompt...)
+	})
+}
+
+func (p *genParams) Messages(msgs ...xai.MsgBuilder) xai.GenParams {
+	return p.add(func(b *backend, params xai.GenParams) {
+		built := make([]xai.MsgBuilder, len(msgs))
+		for i, msg := range msgs {
+			built[i] = buildMsg(b, msg)
+		}
+		params.Messages(built...)
+	})
+}
+
+func (p *genParams) Tools(tools ...xai.ToolBase) xai.GenParams {
+	return p.add(func(b *backend, params xai.GenParams) {
+		built := make([]xai.ToolBase, len(tools))
+		for i, tool := range tools {
+			built[i] = buildTool(b, tool)
+		}
+		params.Tools(built...)
+	})
+}
+
+func (p *genParams) ToolChoice(choice xai.ToolChoice) xai.GenParams {
+	return p.add(func(_ *backend, params xai.GenParams) {
+		params.ToolChoice(choice)
+	})
+}
+
+func (p *genParams) ParallelToolCalls(v bool) xai.GenParams {
+	return p.add(func(_ *backend, params xai.GenParams) {
+		params.ParallelToolCalls(v)
+	})
+}
+
+func (p *genParams) Model(model xai.Model) xai.GenParams {
+	p.model = model
+	return p
+}
+
+func (p *genParams) MaxOutputTokens(v int64) xai.GenParams {
+	return p.add(func(_ *backend, params xai.GenParams) {
+		params.MaxOutputTokens(v)
+	})
+}
+
+func (p *genParams) Compact(maxInputTokens int64) xai.GenParams {
+	return p.add(func(_ *backend, params xai.GenParams) {
+		params.Compact(maxInputTokens)
+	})
+}
+
+func (p *genParams) CacheSystem(ttl time.Duration) xai.GenParams {
+	return p.add(func(_ *backend, params xai.GenParams) {
+		params.CacheSystem(ttl)
+	})
+}
+
+func (p *genParams) CacheTools(ttl time.Duration) xai.GenParams {
+	return p.add(func(_ *backend, params xai.GenParams) {
+		params.CacheTools(ttl)
+	})
+}
+
+func (p *genParams) Temperature(v float64) xai.GenParams {
+	return p.add(func(_ *backend, params xai.GenParams) {
+		params.Temperature(v)
+	})
+}
+
+func (p *genParams) TopP(v float64) xai.GenParams {
+	return p.add(func(_ *backend, params xai.GenParams) {
+		params.TopP(v)
+	})
+}
+
+func (p *genParams) Thinking(v xai.ThinkingConfig) xai.GenParams {
+	return p.add(func(_ *backend, params xai.GenParams) {
+		params.Thinking(v)
+	})
+}
+
+func (p *genParams) ResponseFormat(v xai.ResponseFormat) xai.GenParams {
+	return p.add(func(_ *backend, params xai.GenParams) {
+		params.ResponseFormat(v)
+	})
+}
+
+func (p *genParams) BaseURL(base string) xai.GenParams {
+	return p.add(func(_ *backend, params xai.GenParams) {
+		params.BaseURL(base)
+	})
+}
+
+func (p *genParams) Timeout(timeout time.Duration) xai.GenParams {
+	return p.add(func(_ *backend, params xai.GenParams) {
+		params.Timeout(timeout)
+	})
+}
+
+func (p *genParams) Retry(policy xai.RetryPolicy) xai.GenParams {
+	return p.add(func(_ *backend, params xai.GenParams) {
+		params.Retry(policy)
+	})
+}
+
+func (p *Service) GenParams() xai.GenParams {
+	return &genParams{}
+}
+
+// -----------------------------------------------------------------------------
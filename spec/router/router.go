@@ -0,0 +1,488 @@
+/*
+ * Copyright (c) 2026 The XGo Authors (xgo.dev). All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package router
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"iter"
+	"net"
+	"net/url"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/goplus/xai"
+	"github.com/goplus/xai/geno"
+)
+
+// -----------------------------------------------------------------------------
+
+// Route routes the requests of models matching Model to the service of URI.
+type Route struct {
+	// Model is a pattern of model names, as of path.Match, e.g. "claude-*".
+	Model string `json:"model"`
+
+	// URI is the URI of the service, e.g. "claude:key=...".
+	URI string `json:"uri"`
+}
+
+// Fallback is a service that requests fail over to.
+type Fallback struct {
+	// URI is the URI of the service.
+	URI string `json:"uri"`
+
+	// Model is the model used instead of the one of the request, if it isn't
+	// empty.
+	Model xai.Model `json:"model,omitempty"`
+}
+
+// Config configures the routes and fallbacks of a Service.
+type Config struct {
+	// Routes are matched in order, and the first matching one serves the request.
+	Routes []Route `json:"routes"`
+
+	// Fallbacks are tried in order if the request fails with a retryable error,
+	// i.e. rate limits, overloads, server errors and network errors.
+	Fallbacks []Fallback `json:"fallbacks"`
+}
+
+type backend struct {
+	uri string
+	svc xai.Service
+}
+
+type fallback struct {
+	b     *backend
+	model xai.Model
+}
+
+// target is a backend to serve a request with the model.
+type target struct {
+	b     *backend
+	model xai.Model
+}
+
+// Service routes requests to the services of its routes by model names, and
+// fails over to its fallbacks on retryable errors.
+//
+// Messages, images, documents and generation parameters are recorded, and built
+// by the service that serves the request, so they work with all services, e.g.
+// the messages of a conversation continue to work after a failover. Messages of
+// `Candidate.ToMsg` are passed back as is to the service that returns them, and
+// converted for other services, without thinking and compaction parts as they
+// only work with the service that returns them.
+//
+// Tools defined by ToolDef are defined in all services. Operations are served by
+// the first service that supports them, without failover.
+type Service struct {
+	geno.ServiceBase
+
+	backends  []*backend
+	routes    []Route
+	targets   []*backend // backends of routes
+	fallbacks []fallback
+	tools     map[string]*tool
+}
+
+// NewService creates a Service by conf. The services of the URIs are created by
+// xai.New, and a service is shared by routes and fallbacks of the same URI.
+func NewService(ctx context.Context, conf *Config) (*Service, error) {
+	ret := &Service{routes: conf.Routes, tools: make(map[string]*tool)}
+	for _, r := range conf.Routes {
+		if _, err := path.Match(r.Model, ""); err != nil {
+			return nil, fmt.Errorf("router: invalid model pattern %q: %w", r.Model, err)
+		}
+		b, err := ret.backend(ctx, r.URI)
+		if err != nil {
+			return nil, err
+		}
+		ret.targets = append(ret.targets, b)
+	}
+	for _, fb := range conf.Fallbacks {
+		b, err := ret.backend(ctx, fb.URI)
+		if err != nil {
+			return nil, err
+		}
+		ret.fallbacks = append(ret.fallbacks, fallback{b, fb.Model})
+	}
+	if len(ret.backends) == 0 {
+		return nil, errors.New("router: no routes or fallbacks")
+	}
+	return ret, nil
+}
+
+func (p *Service) backend(ctx context.Context, uri string) (*backend, error) {
+	for _, b := range p.backends {
+		if b.uri == uri {
+			return b, nil
+		}
+	}
+	svc, err := xai.New(ctx, uri)
+	if err != nil {
+		return nil, fmt.Errorf("router: %s: %w", schemeOf(uri), err)
+	}
+	b := &backend{uri, svc}
+	p.backends = append(p.backends, b)
+	return b, nil
+}
+
+// schemeOf returns the scheme of uri, which is used in errors instead of uri as
+// it may contain keys.
+func schemeOf(uri string) string {
+	scheme, _, _ := strings.Cut(uri, ":")
+	return scheme
+}
+
+// Services returns the services of the routes and fallbacks, in the order they
+// first appear in the config.
+func (p *Service) Services() []xai.Service {
+	ret := make([]xai.Service, len(p.backends))
+	for i, b := range p.backends {
+		ret[i] = b.svc
+	}
+	return ret
+}
+
+// route returns the backends to serve the requests of model in order: the one
+// of the first matching route, followed by the fallbacks. Backends without the
+// feature are skipped.
+func (p *Service) route(model xai.Model, feature xai.Feature) (ret []target, err error) {
+	add := func(b *backend, model xai.Model) {
+		if b.svc.Features()&feature == 0 {
+			return
+		}
+		for _, t := range ret {
+			if t.b == b && t.model == model {
+				return
+			}
+		}
+		ret = append(ret, target{b, model})
+	}
+	if i := p.routeOf(model); i >= 0 {
+		add(p.targets[i], model)
+	}
+	for _, fb := range p.fallbacks {
+		if fb.model != "" {
+			add(fb.b, fb.model)
+		} else {
+			add(fb.b, model)
+		}
+	}
+	if len(ret) == 0 {
+		err = fmt.Errorf("router: no service for model %q: %w", model, xai.ErrNotFound)
+	}
+	return
+}
+
+// shouldFailover reports whether a request failed with err should fail over to
+// the next backend, i.e. err is a retryable xai.Error or a network error. Other
+// errors, e.g. the ones of validating the request, don't fail over.
+func shouldFailover(ctx context.Context, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	var e *xai.Error
+	if errors.As(err, &e) {
+		return errors.Is(e.Kind, xai.ErrRateLimited) || errors.Is(e.Kind, xai.ErrOverloaded) ||
+			errors.Is(e.Kind, xai.ErrServer)
+	}
+	var ne net.Error
+	return errors.As(err, &ne) && !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded)
+}
+
+// -----------------------------------------------------------------------------
+
+func (p *Service) Features() (ret xai.Feature) {
+	for _, b := range p.backends {
+		ret |= b.svc.Features()
+	}
+	return
+}
+
+func (p *Service) Gen(ctx context.Context, params xai.GenParams) (xai.GenResponse, error) {
+	gp := params.(*genParams)
+	targets, err := p.route(gp.model, xai.FeatureGen)
+	if err != nil {
+		return nil, err
+	}
+	for i, t := range targets {
+		var resp xai.GenResponse
+		resp, err = t.b.svc.Gen(ctx, gp.build(t))
+		if err == nil {
+			return response{resp, t.b}, nil
+		}
+		if i < len(targets)-1 && !shouldFailover(ctx, err) {
+			break
+		}
+	}
+	return nil, err
+}
+
+func (p *Service) CountTokens(ctx context.Context, params xai.GenParams) (n int64, err error) {
+	gp := params.(*genParams)
+	targets, err := p.route(gp.model, xai.FeatureCountTokens)
+	if err != nil {
+		return
+	}
+	for i, t := range targets {
+		n, err = t.b.svc.CountTokens(ctx, gp.build(t))
+		if err == nil || i < len(targets)-1 && !shouldFailover(ctx, err) {
+			break
+		}
+	}
+	return
+}
+
+func (p *Service) Embed(ctx context.Context, params xai.EmbedParams) (ret *xai.Embeddings, err error) {
+	targets, err := p.route(params.Model, xai.FeatureEmbed)
+	if err != nil {
+		return
+	}
+	for i, t := range targets {
+		in := params
+		in.Model = t.model
+		in.Images = make([]xai.ImageData, len(params.Images))
+		for j, img := range params.Images {
+			if v, ok := img.(*blob); ok {
+				img = v.image(t.b)
+			}
+			in.Images[j] = img
+		}
+		ret, err = t.b.svc.Embed(ctx, in)
+		if err == nil || i < len(targets)-1 && !shouldFailover(ctx, err) {
+			break
+		}
+	}
+	return
+}
+
+// Models lists the models routed to the services of the routes, i.e. the models
+// of a service that match the pattern of its first matching route, followed by
+// other models of the fallbacks that don't replace the model of requests.
+func (p *Service) Models(ctx context.Context) (ret []*xai.ModelInfo, err error) {
+	listed := make(map[*backend][]*xai.ModelInfo)
+	list := func(b *backend) ([]*xai.ModelInfo, error) {
+		if models, ok := listed[b]; ok {
+			return models, nil
+		}
+		models, err := b.svc.Models(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("router: %s: %w", schemeOf(b.uri), err)
+		}
+		listed[b] = models
+		return models, nil
+	}
+	seen := make(map[xai.Model]bool)
+	for i, b := range p.targets {
+		models, err := list(b)
+		if err != nil {
+			return nil, err
+		}
+		for _, m := range models {
+			if !seen[m.ID] && p.routeOf(m.ID) == i {
+				seen[m.ID] = true
+				ret = append(ret, m)
+			}
+		}
+	}
+	for _, fb := range p.fallbacks {
+		if fb.model != "" {
+			continue
+		}
+		models, err := list(fb.b)
+		if err != nil {
+			return nil, err
+		}
+		for _, m := range models {
+			if !seen[m.ID] {
+				seen[m.ID] = true
+				ret = append(ret, m)
+			}
+		}
+	}
+	return
+}
+
+// SubmitBatch submits the batch to the first service that serves the model of
+// the first request. Submission fails over as Gen does, and each request keeps
+// its model unless the service is a fallback that replaces it.
+func (p *Service) SubmitBatch(ctx context.Context, reqs []xai.GenParams) (xai.BatchResponse, error) {
+	if len(reqs) == 0 {
+		return nil, errors.New("router: empty batch")
+	}
+	model := reqs[0].(*genParams).model
+	targets, err := p.route(model, xai.FeatureBatch)
+	if err != nil {
+		return nil, err
+	}
+	for i, t := range targets {
+		in := make([]xai.GenParams, len(reqs))
+		for j, params := range reqs {
+			gp := params.(*genParams)
+			if t.model == model {
+				in[j] = gp.build(target{t.b, gp.model})
+			} else {
+				in[j] = gp.build(t)
+			}
+		}
+		var resp xai.BatchResponse
+		resp, err = t.b.svc.SubmitBatch(ctx, in)
+		if err == nil {
+			return batch{resp, t.b}, nil
+		}
+		if i < len(targets)-1 && !shouldFailover(ctx, err) {
+			break
+		}
+	}
+	return nil, err
+}
+
+// Files returns the Files of the first service that supports them. The files only
+// work with the requests served by that service.
+func (p *Service) Files() xai.Files {
+	for _, b := range p.backends {
+		if b.svc.Features()&xai.FeatureFiles != 0 {
+			return b.svc.Files()
+		}
+	}
+	return nil
+}
+
+// routeOf returns the index of the first route matching model, or -1 if there is
+// none.
+func (p *Service) routeOf(model xai.Model) int {
+	for i, r := range p.routes {
+		if ok, _ := path.Match(r.Model, string(model)); ok {
+			return i
+		}
+	}
+	return -1
+}
+
+// GenStream fails over only if the stream fails before any event is yielded.
+func (p *Service) GenStream(ctx context.Context, params xai.GenParams) iter.Seq2[xai.StreamEvent, error] {
+	return func(yield func(xai.StreamEvent, error) bool) {
+		gp := params.(*genParams)
+		targets, err := p.route(gp.model, xai.FeatureGenStream)
+		if err != nil {
+			yield(xai.StreamEvent{}, err)
+			return
+		}
+		for i, t := range targets {
+			started := false
+			for ev, err := range t.b.svc.GenStream(ctx, gp.build(t)) {
+				if err != nil {
+					if !started && i < len(targets)-1 && shouldFailover(ctx, err) {
+						break
+					}
+					yield(ev, err)
+					return
+				}
+				started = true
+				if ev.Type == xai.EventStop && ev.Response != nil {
+					ev.Response = response{ev.Response, t.b}
+				}
+				if !yield(ev, nil) {
+					return
+				}
+			}
+			if started {
+				return
+			}
+		}
+	}
+}
+
+// -----------------------------------------------------------------------------
+
+func (p *Service) Actions(model xai.Model) []xai.Action {
+	targets, err := p.route(model, xai.FeatureOperation)
+	if err != nil {
+		return nil
+	}
+	t := targets[0]
+	return t.b.svc.Actions(t.model)
+}
+
+func (p *Service) Operation(model xai.Model, action xai.Action) (xai.Operation, error) {
+	targets, err := p.route(model, xai.FeatureOperation)
+	if err != nil {
+		return nil, err
+	}
+	for _, t := range targets {
+		if op, err := t.b.svc.Operation(t.model, action); err == nil {
+			return op, nil
+		}
+	}
+	return nil, xai.ErrNotFound
+}
+
+// -----------------------------------------------------------------------------
+
+const (
+	Scheme = "router"
+)
+
+// New creates a new Service instance based on the scheme in the given URI.
+// uri should be in the format of "router:config=<config>".
+//
+// `config` is the Config in JSON, or the name of a JSON file of it if it doesn't
+// start with "{". It takes the rest of the URI as is, so the JSON needs no
+// escaping of the URIs in it, while the file name may be query escaped. As the
+// URI isn't a URL query, the `rpm`, `tpm` and `max_concurrency` parameters of
+// xai.New don't apply to the router, but to the URIs of its routes. For example,
+//
+//	{
+//	  "routes": [
+//	    {"model": "claude-*", "uri": "claude:key=..."},
+//	    {"model": "gemini-*", "uri": "gemini:key=..."}
+//	  ],
+//	  "fallbacks": [{"uri": "openai:key=...", "model": "gpt-5"}]
+//	}
+func New(ctx context.Context, uri string) (xai.Service, error) {
+	config, ok := strings.CutPrefix(strings.TrimPrefix(uri, Scheme+":"), "config=")
+	if !ok || config == "" {
+		return nil, errors.New("router: config is required")
+	}
+	if config[0] != '{' {
+		name, err := url.QueryUnescape(config)
+		if err != nil {
+			return nil, err
+		}
+		if config = name; config[0] != '{' {
+			data, err := os.ReadFile(name)
+			if err != nil {
+				return nil, err
+			}
+			config = string(data)
+		}
+	}
+	var conf Config
+	if err := json.Unmarshal([]byte(config), &conf); err != nil {
+		return nil, fmt.Errorf("router: invalid config: %w", err)
+	}
+	return NewService(ctx, &conf)
+}
+
+func init() {
+	xai.RegisterOpaque(Scheme, New)
+}
+
+// -----------------------------------------------------------------------------
@@ -0,0 +1,150 @@
+/*
+ * Copyright (c) 2026 The XGo Authors (xgo.dev). All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package router
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/url"
+	"testing"
+
+	"github.com/goplus/xai"
+	"github.com/goplus/xai/spec/mock"
+)
+
+// -----------------------------------------------------------------------------
+
+func TestRouter(t *testing.T) {
+	a, b := mock.NewService(), mock.NewService()
+	xai.Register("router-a", a.NewFunc())
+	xai.Register("router-b", b.NewFunc())
+
+	const conf = `{
+		"routes": [{"model": "a-*", "uri": "router-a:"}, {"model": "b-*", "uri": "router-b:"}],
+		"fallbacks": [{"uri": "router-b:", "model": "b-fallback"}]
+	}`
+	ctx := context.Background()
+	ai, err := xai.New(ctx, "router:config="+url.QueryEscape(conf))
+	if err != nil {
+		t.Fatal("New:", err)
+	}
+	// the inline config needs no escaping of the URIs in it, and their limits
+	// apply to their services only
+	if _, err = xai.New(ctx, `router:config={"routes":[{"model":"a-*","uri":"router-a:x=1&y=2"}]}`); err != nil {
+		t.Fatal("New:", err)
+	}
+	raw, err := xai.New(ctx, `router:config={"routes":[{"model":"a-*","uri":"router-a:rpm=60&max_concurrency=1&x=100%25"}],`+
+		`"fallbacks":[{"uri":"router-b:","model":"b-100%"}]}`)
+	if err != nil {
+		t.Fatal("New:", err)
+	}
+	if _, ok := raw.(*Service); !ok {
+		t.Fatalf("New: router is wrapped, got %T", raw)
+	}
+
+	a.Reply(mock.NewReply(
+		mock.Thinking(xai.Thinking{Text: "hmm", Signature: "sig"}),
+		mock.ToolUse(xai.ToolUse{ID: "1", Name: "add", Input: map[string]int{"a": 1}}),
+	))
+	model := xai.Model("a-1")
+	c := xai.NewConversation(ai).Params(func(p xai.GenParams) { p.Model(model) })
+	c.Tools(ai.ToolDef("add").Description("add numbers"))
+	if _, err = c.SendText(ctx, "1+?"); err != nil {
+		t.Fatal("Send:", err)
+	}
+	if req := a.LastRequest(); req.Model != "a-1" || req.Tools[0].Description != "add numbers" {
+		t.Fatal("Request:", req)
+	}
+
+	// the conversation continues with another service
+	b.ReplyText("2")
+	model = "b-1"
+	if _, err = c.ToolResult(xai.ToolResult{ID: "1", Name: "add", Result: 2}).Send(ctx); err != nil {
+		t.Fatal("Send:", err)
+	}
+	req := b.LastRequest()
+	if req.Model != "b-1" || req.Tools[0].Description != "add numbers" || len(req.Messages) != 3 {
+		t.Fatal("Request:", req)
+	}
+	// the thinking of another service is dropped
+	if parts := req.Messages[1].Parts; len(parts) != 1 {
+		t.Fatal("Messages:", parts)
+	} else if use, ok := parts[0].AsToolUse(); !ok || use.ID != "1" {
+		t.Fatal("ToolUse:", use)
+	}
+
+	// failover on retryable errors
+	a.Reply(mock.Reply{Err: &xai.Error{Kind: xai.ErrOverloaded, Err: errors.New("busy")}})
+	b.ReplyText("ok")
+	resp, err := ai.Gen(ctx, ai.GenParams().Model("a-1").Messages(ai.UserMsg().Text("hi")))
+	if err != nil || resp.At(0).Part(0).Text() != "ok" || b.LastRequest().Model != "b-fallback" {
+		t.Fatal("Gen:", err)
+	}
+
+	a.Reply(mock.Reply{Err: &xai.Error{Kind: xai.ErrOverloaded, Err: errors.New("busy")}})
+	b.ReplyText("streamed")
+	resp, err = xai.Accumulate(ai.GenStream(ctx, ai.GenParams().Model("a-1").Messages(ai.UserMsg().Text("hi"))))
+	if err != nil || resp.At(0).Part(0).Text() != "streamed" {
+		t.Fatal("GenStream:", err)
+	}
+
+	// no failover on other errors
+	n := len(b.Requests())
+	a.Reply(mock.Reply{Err: &xai.Error{Kind: xai.ErrInvalidRequest, Err: errors.New("bad")}})
+	if _, err = ai.Gen(ctx, ai.GenParams().Model("a-1")); !errors.Is(err, xai.ErrInvalidRequest) || len(b.Requests()) != n {
+		t.Fatal("Gen: unexpected", err)
+	}
+
+	// no failover on local errors, but on network errors
+	a.Reply(mock.Reply{Err: errors.New("invalid params")})
+	if _, err = ai.Gen(ctx, ai.GenParams().Model("a-1")); err == nil || len(b.Requests()) != n {
+		t.Fatal("Gen: unexpected", err)
+	}
+	a.Reply(mock.Reply{Err: &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")}})
+	b.ReplyText("ok")
+	if _, err = ai.Gen(ctx, ai.GenParams().Model("a-1")); err != nil || len(b.Requests()) != n+1 {
+		t.Fatal("Gen:", err)
+	}
+
+	// models are listed by the services they are routed to
+	a.AddModels(xai.ModelInfo{ID: "a-1"}, xai.ModelInfo{ID: "b-x"})
+	b.AddModels(xai.ModelInfo{ID: "b-1"}, xai.ModelInfo{ID: "a-2"})
+	models, err := ai.Models(ctx)
+	if err != nil || len(models) != 2 || models[0].ID != "a-1" || models[1].ID != "b-1" {
+		t.Fatal("Models:", models, err)
+	}
+
+	// batches are submitted to the service of the first request
+	a.ReplyText("x", "y")
+	batch, err := ai.SubmitBatch(ctx, []xai.GenParams{
+		ai.GenParams().Model("a-1").Messages(ai.UserMsg().Text("1")),
+		ai.GenParams().Model("a-2").Messages(ai.UserMsg().Text("2")),
+	})
+	if err != nil {
+		t.Fatal("SubmitBatch:", err)
+	}
+	results, err := batch.Wait(ctx, batch.WaitParams())
+	if err != nil || results.Len() != 2 || a.LastRequest().Model != "a-2" {
+		t.Fatal("Wait:", err)
+	}
+	if r := results.At(1).(*xai.BatchResult); r.Err != nil || r.Response.At(0).Part(0).Text() != "y" {
+		t.Fatal("At:", r.Err)
+	}
+}
+
+// -----------------------------------------------------------------------------
@@ -0,0 +1,120 @@
+/*
+ * Copyright (c) 2026 The XGo Authors (xgo.dev). All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package router
+
+import (
+	"github.com/goplus/xai"
+)
+
+// -----------------------------------------------------------------------------
+
+// tool is a tool defined in all backends.
+type tool struct {
+	tools map[*backend]xai.Tool
+}
+
+func (p *tool) UnderlyingAssignTo(ret any) {
+	panic("router: tools are set to the backends by GenParams.Tools")
+}
+
+func (p *tool) Description(desc string) xai.Tool {
+	for _, t := range p.tools {
+		t.Description(desc)
+	}
+	return p
+}
+
+func (p *tool) InputSchema(schema any) xai.Tool {
+	for _, t := range p.tools {
+		t.InputSchema(schema)
+	}
+	return p
+}
+
+// ToolDef defines the tool in all services that support Gen or GenStream.
+func (p *Service) ToolDef(name string) xai.Tool {
+	if _, ok := p.tools[name]; ok {
+		panic("tool already defined: " + name)
+	}
+	ret := &tool{tools: make(map[*backend]xai.Tool, len(p.backends))}
+	for _, b := range p.backends {
+		if b.svc.Features()&(xai.FeatureGen|xai.FeatureGenStream) != 0 {
+			ret.tools[b] = b.svc.ToolDef(name)
+		}
+	}
+	p.tools[name] = ret
+	return ret
+}
+
+func (p *Service) Tool(name string) xai.Tool {
+	if t, ok := p.tools[name]; ok {
+		return t
+	}
+	return nil
+}
+
+// -----------------------------------------------------------------------------
+
+// webSearchTool records the options of the web search tool, which is created by
+// the backend that serves the request.
+type webSearchTool struct {
+	ops []func(xai.WebSearchTool)
+}
+
+func (p *webSearchTool) UnderlyingAssignTo(ret any) {
+	panic("router: tools are set to the backends by GenParams.Tools")
+}
+
+func (p *webSearchTool) MaxUses(v int64) xai.WebSearchTool {
+	p.ops = append(p.ops, func(t xai.WebSearchTool) { t.MaxUses(v) })
+	return p
+}
+
+func (p *webSearchTool) AllowedDomains(v ...string) xai.WebSearchTool {
+	p.ops = append(p.ops, func(t xai.WebSearchTool) { t.AllowedDomains(v...) })
+	return p
+}
+
+func (p *webSearchTool) BlockedDomains(v ...string) xai.WebSearchTool {
+	p.ops = append(p.ops, func(t xai.WebSearchTool) { t.BlockedDomains(v...) })
+	return p
+}
+
+func (p *Service) WebSearchTool() xai.WebSearchTool {
+	return &webSearchTool{}
+}
+
+// buildTool returns the tool of the backend b. Tools not created by the router
+// are passed as is.
+func buildTool(b *backend, v xai.ToolBase) xai.ToolBase {
+	switch v := v.(type) {
+	case *tool:
+		if t, ok := v.tools[b]; ok {
+			return t
+		}
+		panic("router: tool not defined in " + schemeOf(b.uri))
+	case *webSearchTool:
+		ret := b.svc.WebSearchTool()
+		for _, op := range v.ops {
+			op(ret)
+		}
+		return ret
+	}
+	return v
+}
+
+// -----------------------------------------------------------------------------
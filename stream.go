@@ -0,0 +1,189 @@
+/*
+ * Copyright (c) 2026 The XGo Authors (xgo.dev). All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package xai
+
+import (
+	"errors"
+	"iter"
+	"strings"
+)
+
+// -----------------------------------------------------------------------------
+
+// EventType represents the type of a StreamEvent.
+type EventType int
+
+const (
+	// EventStart is the first event of a stream.
+	EventStart EventType = iota
+
+	// EventBlockStart starts a content block at StreamEvent.Index. For tool use
+	// blocks, StreamEvent.ToolUse holds the ID and the name of the tool.
+	EventBlockStart
+
+	// EventDelta appends StreamEvent.Delta to the content block at Index.
+	EventDelta
+
+	// EventBlockStop stops the content block at StreamEvent.Index.
+	EventBlockStop
+
+	// EventStop is the last event of a stream. StreamEvent.Response holds the
+	// complete response accumulated from the stream.
+	EventStop
+)
+
+// BlockType represents the type of a content block in a stream.
+type BlockType int
+
+const (
+	BlockOther    BlockType = iota // provider-specific block, e.g. a server tool result
+	BlockText                      // Delta is a chunk of text
+	BlockThinking                  // Delta is a chunk of thinking
+	BlockToolUse                   // Delta is a chunk of the tool input JSON
+)
+
+// StreamEvent represents an event of the stream returned by `Service.GenStream`.
+//
+// A stream starts with an EventStart event, and ends with an EventStop event.
+// In between, content blocks are streamed as an EventBlockStart event, followed
+// by zero or more EventDelta events, and an EventBlockStop event. Blocks may
+// interleave, so use Index to tell them apart.
+type StreamEvent struct {
+	Type EventType
+
+	// Index is the index of the content block, which is also the index of the
+	// corresponding Part of the final response.
+	Index int
+
+	// Block is the type of the content block, set for EventBlockStart, EventDelta
+	// and EventBlockStop events.
+	Block BlockType
+
+	// Delta is the content appended to the block for EventDelta events.
+	Delta string
+
+	// ToolUse holds the ID and the name of the tool for EventBlockStart events of
+	// tool use blocks. Its Input is not set, as it is streamed by deltas.
+	ToolUse ToolUse
+
+	// Response is the complete response for EventStop events. It supports ToMsg,
+	// so it can be passed back to the model in multi-turn conversations.
+	Response GenResponse
+
+	Underlying any // the provider-specific event
+}
+
+// -----------------------------------------------------------------------------
+
+// ErrIncompleteStream is returned by `Accumulate` if a stream ends without an
+// EventStop event.
+var ErrIncompleteStream = errors.New("incomplete stream")
+
+// Accumulator accumulates the events of a stream. It provides the partial content
+// of the blocks while streaming, and the complete response when the stream is
+// finished.
+type Accumulator struct {
+	blocks []accBlock
+	resp   GenResponse
+}
+
+type accBlock struct {
+	typ     BlockType
+	toolUse ToolUse
+	content strings.Builder
+}
+
+// Add adds an event of the stream to the accumulator.
+func (p *Accumulator) Add(ev StreamEvent) {
+	switch ev.Type {
+	case EventBlockStart:
+		b := p.block(ev.Index)
+		b.typ, b.toolUse = ev.Block, ev.ToolUse
+	case EventDelta:
+		b := p.block(ev.Index)
+		b.typ = ev.Block
+		b.content.WriteString(ev.Delta)
+	case EventStop:
+		p.resp = ev.Response
+	}
+}
+
+func (p *Accumulator) block(i int) *accBlock {
+	for len(p.blocks) <= i {
+		p.blocks = append(p.blocks, accBlock{})
+	}
+	return &p.blocks[i]
+}
+
+// Text returns the text accumulated so far, i.e. the content of all text blocks.
+func (p *Accumulator) Text() string {
+	return p.content(BlockText)
+}
+
+// Thinking returns the thinking accumulated so far, i.e. the content of all
+// thinking blocks.
+func (p *Accumulator) Thinking() string {
+	return p.content(BlockThinking)
+}
+
+func (p *Accumulator) content(typ BlockType) string {
+	var b strings.Builder
+	for i := range p.blocks {
+		if p.blocks[i].typ == typ {
+			b.WriteString(p.blocks[i].content.String())
+		}
+	}
+	return b.String()
+}
+
+// ToolUses returns the tool uses accumulated so far. The Input of each tool use
+// is the JSON input streamed so far, as RawMessage, which may be incomplete
+// until the block stops.
+func (p *Accumulator) ToolUses() []ToolUse {
+	var ret []ToolUse
+	for i := range p.blocks {
+		if b := &p.blocks[i]; b.typ == BlockToolUse {
+			use := b.toolUse
+			use.Input = RawMessage(b.content.String())
+			ret = append(ret, use)
+		}
+	}
+	return ret
+}
+
+// Response returns the complete response of the stream, or nil if the stream
+// isn't finished yet.
+func (p *Accumulator) Response() GenResponse {
+	return p.resp
+}
+
+// Accumulate consumes the stream and returns its complete response.
+func Accumulate(stream iter.Seq2[StreamEvent, error]) (GenResponse, error) {
+	var acc Accumulator
+	for ev, err := range stream {
+		if err != nil {
+			return nil, err
+		}
+		acc.Add(ev)
+	}
+	if acc.resp == nil {
+		return nil, ErrIncompleteStream
+	}
+	return acc.resp, nil
+}
+
+// -----------------------------------------------------------------------------
@@ -0,0 +1,106 @@
+/*
+ * Copyright (c) 2026 The XGo Authors (xgo.dev). All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package xai
+
+// -----------------------------------------------------------------------------
+
+// ReasoningEffort specifies how much effort the model should spend on thinking
+// before it responds.
+type ReasoningEffort string
+
+const (
+	EffortNone   ReasoningEffort = "none" // disable thinking
+	EffortLow    ReasoningEffort = "low"
+	EffortMedium ReasoningEffort = "medium"
+	EffortHigh   ReasoningEffort = "high"
+)
+
+// ThinkingConfig configures the thinking (also known as reasoning or extended
+// thinking) of the model. Some providers specify it with a token budget, and
+// others with a reasoning effort. Either of them can be used, and it is converted
+// to the other if the provider doesn't support it. If neither of them is set,
+// the default of the model is used.
+//
+// Use `ThinkingBudget`, `ThinkingEffort` or `NoThinking` to create it, and pass
+// it to `GenParams.Thinking`.
+type ThinkingConfig struct {
+	// Budget is the maximum number of tokens the model can use for thinking.
+	Budget int64
+
+	// Effort is the reasoning effort. It takes precedence over Budget.
+	Effort ReasoningEffort
+
+	// IncludeSummary requests the model to return its thinking, or a summary of
+	// it, as Thinking parts of the response.
+	IncludeSummary bool
+}
+
+// ThinkingBudget creates a ThinkingConfig with a token budget.
+func ThinkingBudget(budget int64, includeSummary bool) ThinkingConfig {
+	return ThinkingConfig{Budget: budget, IncludeSummary: includeSummary}
+}
+
+// ThinkingEffort creates a ThinkingConfig with a reasoning effort.
+func ThinkingEffort(effort ReasoningEffort, includeSummary bool) ThinkingConfig {
+	return ThinkingConfig{Effort: effort, IncludeSummary: includeSummary}
+}
+
+// NoThinking creates a ThinkingConfig that disables thinking. Models that can't
+// disable thinking use their lowest effort.
+func NoThinking() ThinkingConfig {
+	return ThinkingConfig{Effort: EffortNone}
+}
+
+// Disabled reports whether thinking is disabled.
+func (c ThinkingConfig) Disabled() bool {
+	return c.Effort == EffortNone
+}
+
+// EffortLevel returns the reasoning effort of the config. If Effort isn't set,
+// it is derived from Budget. It returns "" if neither of them is set.
+func (c ThinkingConfig) EffortLevel() ReasoningEffort {
+	switch {
+	case c.Effort != "":
+		return c.Effort
+	case c.Budget <= 0:
+		return ""
+	case c.Budget < 4096:
+		return EffortLow
+	case c.Budget < 16384:
+		return EffortMedium
+	}
+	return EffortHigh
+}
+
+// BudgetTokens returns the token budget of the config. If Effort is set, the
+// budget is derived from it. It returns 0 if thinking is disabled or neither of
+// Budget and Effort is set.
+func (c ThinkingConfig) BudgetTokens() int64 {
+	switch c.Effort {
+	case "":
+		return max(c.Budget, 0)
+	case EffortLow:
+		return 1024
+	case EffortMedium:
+		return 8192
+	case EffortHigh:
+		return 24576
+	}
+	return 0
+}
+
+// -----------------------------------------------------------------------------
@@ -98,6 +98,37 @@ type Tool interface {
 	ToolBase
 
 	Description(string) Tool
+
+	// InputSchema sets the JSON schema of the tool input. The schema must be an
+	// object schema, and expects anything that can be marshaled to JSON,
+	// including RawMessage. Use `SchemaFor` to derive it from a Go struct.
+	InputSchema(schema any) Tool
+}
+
+// -----------------------------------------------------------------------------
+
+// ToolChoiceMode specifies how the model should use the provided tools.
+type ToolChoiceMode string
+
+const (
+	ToolChoiceAuto ToolChoiceMode = "auto" // the model decides whether to use tools (default)
+	ToolChoiceAny  ToolChoiceMode = "any"  // the model must use at least one tool
+	ToolChoiceNone ToolChoiceMode = "none" // the model must not use any tool
+	ToolChoiceTool ToolChoiceMode = "tool" // the model must use the tool named Name
+)
+
+// ToolChoice specifies how the model should use the provided tools. Pass it to
+// `GenParams.ToolChoice`.
+type ToolChoice struct {
+	Mode ToolChoiceMode
+
+	// Name of the tool the model must use when Mode is ToolChoiceTool.
+	Name string
+}
+
+// UseTool creates a ToolChoice that forces the model to use the tool named name.
+func UseTool(name string) ToolChoice {
+	return ToolChoice{Mode: ToolChoiceTool, Name: name}
 }
 
 // -----------------------------------------------------------------------------
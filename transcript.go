@@ -0,0 +1,296 @@
+/*
+ * Copyright (c) 2026 The XGo Authors (xgo.dev). All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package xai
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// -----------------------------------------------------------------------------
+
+// PartType is the type of a MessagePart.
+type PartType string
+
+const (
+	PartText       PartType = "text"
+	PartImage      PartType = "image"
+	PartDoc        PartType = "document"
+	PartThinking   PartType = "thinking"
+	PartToolUse    PartType = "tool_use"
+	PartToolResult PartType = "tool_result"
+	PartCompaction PartType = "compaction"
+)
+
+// Role is the role of a Message.
+type Role string
+
+const (
+	RoleUser      Role = "user"
+	RoleAssistant Role = "assistant"
+)
+
+// MessagePart is a part of a Message in the provider-neutral transcript format.
+// Fields not used by the type of the part are omitted.
+type MessagePart struct {
+	Type PartType `json:"type"`
+
+	// Text is the text of PartText and PartThinking parts, or the opaque data of
+	// PartCompaction parts.
+	Text string `json:"text,omitempty"`
+
+	// MIME, Data, URL and FileID are the content of PartImage and PartDoc parts.
+	// One of Data, URL and FileID is set.
+	MIME   string `json:"mime,omitempty"`
+	Data   []byte `json:"data,omitempty"`
+	URL    string `json:"url,omitempty"`
+	FileID string `json:"file_id,omitempty"`
+
+	// Name is the display name of PartImage and PartDoc parts, or the tool name of
+	// PartToolUse and PartToolResult parts.
+	Name string `json:"name,omitempty"`
+
+	// ID, Input, Result and IsError are the content of PartToolUse and
+	// PartToolResult parts. The Result of an error is the error message in a JSON
+	// string.
+	ID      string     `json:"id,omitempty"`
+	Input   RawMessage `json:"input,omitempty"`
+	Result  RawMessage `json:"result,omitempty"`
+	IsError bool       `json:"is_error,omitempty"`
+
+	// Signature and Redacted are the content of PartThinking parts. Signature
+	// may also be set on PartText and PartToolUse parts by providers that sign
+	// them, e.g. gemini.
+	Signature string `json:"signature,omitempty"`
+	Redacted  bool   `json:"redacted,omitempty"`
+
+	// Provider is the provider whose opaque data the part holds, i.e. the Signature
+	// of parts and the Text of PartCompaction parts. Such data is only passed back
+	// to services of the provider, see `ProviderOf`.
+	Provider string `json:"provider,omitempty"`
+}
+
+// Message is a message in the provider-neutral transcript format.
+type Message struct {
+	Role  Role          `json:"role"`
+	Parts []MessagePart `json:"parts"`
+}
+
+// Transcript is a list of messages in the provider-neutral transcript format. It
+// can be marshaled to JSON to persist a conversation, and loaded into services of
+// any provider.
+type Transcript struct {
+	Messages []*Message `json:"messages"`
+}
+
+// MsgExporter is implemented by MsgBuilders that can be exported to Message.
+type MsgExporter interface {
+	ExportMsg() (*Message, error)
+}
+
+// ErrNotExportable is returned when a MsgBuilder or a part of it can't be exported
+// to the transcript format, e.g. the results of standard tools.
+var ErrNotExportable = errors.New("not exportable")
+
+// ExportMsg exports msg to the transcript format. msg should be created by a
+// Service or `Candidate.ToMsg` of a provider that supports it, or an
+// ErrNotExportable error is returned.
+func ExportMsg(msg MsgBuilder) (*Message, error) {
+	if v, ok := msg.(MsgExporter); ok {
+		return v.ExportMsg()
+	}
+	return nil, fmt.Errorf("xai: message of %T: %w", msg, ErrNotExportable)
+}
+
+// ExportCandidate exports the candidate c to the transcript format. See ExportMsg.
+func ExportCandidate(c Candidate) (*Message, error) {
+	return ExportMsg(c.ToMsg())
+}
+
+// ExportMsgs exports msgs to a Transcript. The tool names of tool results that
+// are unknown by their providers, e.g. Claude, are set by their tool uses.
+func ExportMsgs(msgs ...MsgBuilder) (*Transcript, error) {
+	ret := &Transcript{Messages: make([]*Message, len(msgs))}
+	names := make(map[string]string) // tool use ID => tool name
+	for i, msg := range msgs {
+		m, err := ExportMsg(msg)
+		if err != nil {
+			return nil, err
+		}
+		for j := range m.Parts {
+			part := &m.Parts[j]
+			switch part.Type {
+			case PartToolUse:
+				names[part.ID] = part.Name
+			case PartToolResult:
+				if part.Name == "" {
+					part.Name = names[part.ID]
+				}
+			}
+		}
+		ret.Messages[i] = m
+	}
+	return ret, nil
+}
+
+// ProviderOf returns the provider of svc, which is the scheme of the services of
+// the provider, e.g. "claude". It returns "" if svc doesn't report its provider.
+func ProviderOf(svc Service) string {
+	if v, ok := unwrapAs[interface{ Provider() string }](svc); ok {
+		return v.Provider()
+	}
+	return ""
+}
+
+// DocAcceptor is implemented by Services that only accept some types of documents.
+// Document parts of transcripts that they don't accept are dropped when building
+// messages for them.
+type DocAcceptor interface {
+	// AcceptsDoc reports whether documents of mime are accepted, by URL if url is
+	// true, or by data otherwise.
+	AcceptsDoc(mime DocumentType, url bool) bool
+}
+
+// acceptsDoc reports whether svc accepts the document part p. Documents of
+// uploaded files are passed as is.
+func (p *MessagePart) acceptsDoc(svc Service) bool {
+	if v, ok := unwrapAs[DocAcceptor](svc); ok && p.FileID == "" {
+		return v.AcceptsDoc(DocumentType(p.MIME), p.URL != "")
+	}
+	return true
+}
+
+// Msgs builds the messages of the transcript for svc. See `Message.Build`.
+func (p *Transcript) Msgs(svc Service) []MsgBuilder {
+	ret := make([]MsgBuilder, len(p.Messages))
+	for i, m := range p.Messages {
+		ret[i] = m.Build(svc)
+	}
+	return ret
+}
+
+// Build builds the message for svc by its `UserMsg` or `AssistantMsg`. Parts
+// holding opaque data of other providers are dropped, as well as thinking parts
+// without signatures that can't be passed back to models, and documents that svc
+// doesn't accept (see DocAcceptor), e.g. audio exported from gemini.
+func (p *Message) Build(svc Service) MsgBuilder {
+	var ret MsgBuilder
+	if p.Role == RoleAssistant {
+		ret = svc.AssistantMsg()
+	} else {
+		ret = svc.UserMsg()
+	}
+	provider := ProviderOf(svc)
+	for _, part := range p.Parts {
+		part.build(svc, provider, ret)
+	}
+	return ret
+}
+
+func (p *MessagePart) build(svc Service, provider string, m MsgBuilder) {
+	switch p.Type {
+	case PartText:
+		m.Text(p.Text)
+		p.sign(provider, m)
+	case PartImage:
+		switch {
+		case p.URL != "":
+			m.ImageURL(ImageType(p.MIME), p.URL)
+		case p.FileID != "":
+			m.ImageFile(ImageType(p.MIME), p.FileID)
+		default:
+			m.Image(svc.Images().FromBytes(ImageType(p.MIME), p.Name, p.Data))
+		}
+	case PartDoc:
+		if !p.acceptsDoc(svc) {
+			return
+		}
+		switch {
+		case p.URL != "":
+			m.DocURL(DocumentType(p.MIME), p.URL)
+		case p.FileID != "":
+			m.DocFile(DocumentType(p.MIME), p.FileID)
+		case p.MIME == string(DocPlainText):
+			m.Doc(svc.Docs().PlainText(string(p.Data)))
+		default:
+			m.Doc(svc.Docs().FromBytes(DocumentType(p.MIME), p.Name, p.Data))
+		}
+	case PartThinking:
+		if p.Provider == provider && provider != "" && p.Signature != "" {
+			m.Thinking(Thinking{Text: p.Text, Signature: p.Signature, Redacted: p.Redacted})
+		}
+	case PartToolUse:
+		m.ToolUse(ToolUse{ID: p.ID, Name: p.Name, Input: p.Input})
+		p.sign(provider, m)
+	case PartToolResult:
+		v := ToolResult{ID: p.ID, Name: p.Name, Result: p.Result, IsError: p.IsError}
+		if p.IsError {
+			var msg string
+			if json.Unmarshal(p.Result, &msg) != nil {
+				msg = string(p.Result)
+			}
+			v.Result = errors.New(msg)
+		}
+		m.ToolResult(v)
+	case PartCompaction:
+		if p.Provider == provider && provider != "" {
+			m.Compaction(p.Text)
+		}
+	}
+}
+
+// sign passes the signature of a PartText or PartToolUse part back to the
+// provider, by the SignPart method of the MsgBuilder if it has one.
+func (p *MessagePart) sign(provider string, m MsgBuilder) {
+	if p.Signature != "" && p.Provider == provider && provider != "" {
+		if v, ok := m.(interface{ SignPart(sig string) }); ok {
+			v.SignPart(p.Signature)
+		}
+	}
+}
+
+// ToolResultPart creates a PartToolResult part of the tool result content as
+// sent to providers: the error message if isError is true, or the result in JSON
+// otherwise. It is used by MsgExporter implementations.
+func ToolResultPart(id, name, content string, isError bool) MessagePart {
+	ret := MessagePart{Type: PartToolResult, ID: id, Name: name, IsError: isError}
+	if !isError && json.Valid([]byte(content)) {
+		ret.Result = RawMessage(content)
+	} else {
+		ret.Result, _ = json.Marshal(content)
+	}
+	return ret
+}
+
+// BlobPart creates a PartImage or PartDoc part from a data URL or base64 data.
+// It is used by MsgExporter implementations.
+func BlobPart(typ PartType, mime, name, data string) (ret MessagePart, err error) {
+	if rest, ok := strings.CutPrefix(data, "data:"); ok {
+		var meta string
+		meta, data, _ = strings.Cut(rest, ",")
+		mime, _, _ = strings.Cut(meta, ";")
+	}
+	raw, err := BlobFromBase64(data).Raw()
+	if err != nil {
+		return
+	}
+	return MessagePart{Type: typ, MIME: mime, Name: name, Data: raw}, nil
+}
+
+// -----------------------------------------------------------------------------
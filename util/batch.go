@@ -0,0 +1,188 @@
+/*
+ * Copyright (c) 2026 The XGo Authors (xgo.dev). All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package util
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/goplus/xai"
+)
+
+// -----------------------------------------------------------------------------
+
+// BatchStatus is the status of a batch submitted to a provider.
+type BatchStatus struct {
+	ID string
+
+	// Ended is true if the batch ended, i.e. all requests ended, or the batch was
+	// canceled or expired.
+	Ended bool
+
+	// EndedRequests is the number of ended (succeeded or failed) requests, and
+	// Requests is the number of all requests.
+	EndedRequests, Requests int
+}
+
+// WaitOptions are the options of polling set by xai.WaitParams.
+type WaitOptions struct {
+	BaseURL string
+	Timeout time.Duration
+}
+
+// BatchPoller polls the status of a batch submitted to a provider.
+type BatchPoller interface {
+	// Poll returns the status of the batch. Once the batch ended, it returns the
+	// results of the requests too, in the order of the requests.
+	Poll(ctx context.Context, opts *WaitOptions) (BatchStatus, []*xai.BatchResult, error)
+}
+
+// Batch implements xai.BatchResponse by polling a BatchPoller every interval,
+// until the batch ends and its results are fetched.
+type Batch struct {
+	status   BatchStatus
+	results  []*xai.BatchResult
+	done     bool
+	poller   BatchPoller
+	interval time.Duration
+}
+
+// NewBatch creates a Batch of the status returned on submission.
+func NewBatch(status BatchStatus, poller BatchPoller, interval time.Duration) *Batch {
+	return &Batch{status: status, poller: poller, interval: interval}
+}
+
+func (p *Batch) ID() string {
+	return p.status.ID
+}
+
+func (p *Batch) Counts() (ended, total int) {
+	return p.status.EndedRequests, p.status.Requests
+}
+
+func (p *Batch) Done() bool {
+	return p.done
+}
+
+func (p *Batch) Results() xai.Results {
+	return batchResults(p.results)
+}
+
+func (p *Batch) WaitParams() xai.WaitParams {
+	return new(batchWaitParams)
+}
+
+// Wait polls the batch until its results are fetched. The batch isn't polled
+// again if it is known to be ended, so that the results are fetched at once.
+func (p *Batch) Wait(ctx context.Context, wp xai.WaitParams) (xai.Results, error) {
+	params, _ := wp.(*batchWaitParams)
+	if params == nil {
+		params = new(batchWaitParams)
+	}
+	for !p.done {
+		if params.progress != nil {
+			params.progress(p)
+		}
+		if !p.status.Ended {
+			select {
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			case <-time.After(p.interval):
+			}
+		}
+		if err := p.poll(ctx, &params.opts); err != nil {
+			return nil, err
+		}
+	}
+	return p.Results(), nil
+}
+
+func (p *Batch) poll(ctx context.Context, opts *WaitOptions) error {
+	if opts.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, opts.Timeout)
+		defer cancel()
+	}
+	status, results, err := p.poller.Poll(ctx, opts)
+	if err != nil {
+		return err
+	}
+	p.status = status
+	if status.Ended {
+		p.results, p.done = results, true
+	}
+	return nil
+}
+
+// CustomID returns the custom ID of the i-th request of a batch.
+func CustomID(i int) string {
+	return strconv.Itoa(i)
+}
+
+// BatchResults sorts results by their custom IDs into the order of n requests.
+// Requests without results fail with err, e.g. the requests of canceled batches.
+func BatchResults(results []*xai.BatchResult, n int, err error) []*xai.BatchResult {
+	ret := make([]*xai.BatchResult, n)
+	for _, r := range results {
+		if i, e := strconv.Atoi(r.CustomID); e == nil && i >= 0 && i < n {
+			ret[i] = r
+		}
+	}
+	for i, r := range ret {
+		if r == nil {
+			ret[i] = &xai.BatchResult{CustomID: CustomID(i), Err: err}
+		}
+	}
+	return ret
+}
+
+type batchResults []*xai.BatchResult
+
+func (p batchResults) XGo_Attr(name string) any {
+	return nil
+}
+
+func (p batchResults) Len() int {
+	return len(p)
+}
+
+func (p batchResults) At(i int) xai.Generated {
+	return p[i]
+}
+
+type batchWaitParams struct {
+	opts     WaitOptions
+	progress func(xai.OperationResponse)
+}
+
+func (p *batchWaitParams) BaseURL(base string) xai.WaitParams {
+	p.opts.BaseURL = base
+	return p
+}
+
+func (p *batchWaitParams) Timeout(timeout time.Duration) xai.WaitParams {
+	p.opts.Timeout = timeout
+	return p
+}
+
+func (p *batchWaitParams) Progress(progress func(xai.OperationResponse)) xai.WaitParams {
+	p.progress = progress
+	return p
+}
+
+// -----------------------------------------------------------------------------
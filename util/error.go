@@ -0,0 +1,92 @@
+/*
+ * Copyright (c) 2026 The XGo Authors (xgo.dev). All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package util
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/goplus/xai"
+)
+
+// -----------------------------------------------------------------------------
+
+// KindOfStatus returns the xai error kind of an HTTP status code. It returns
+// nil if the status code is not an error or has no corresponding kind.
+func KindOfStatus(statusCode int) error {
+	switch statusCode {
+	case http.StatusTooManyRequests:
+		return xai.ErrRateLimited
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return xai.ErrAuth
+	case http.StatusNotFound:
+		return xai.ErrNotFound
+	case http.StatusBadRequest, http.StatusRequestEntityTooLarge, http.StatusUnprocessableEntity:
+		return xai.ErrInvalidRequest
+	case http.StatusServiceUnavailable, 529: // 529: anthropic overloaded
+		return xai.ErrOverloaded
+	}
+	if statusCode >= 500 {
+		return xai.ErrServer
+	}
+	return nil
+}
+
+// NewError creates an *xai.Error that wraps err. If kind is nil, it is derived
+// from statusCode. If there is no kind to derive, err is returned unchanged.
+func NewError(kind error, statusCode int, retryAfter time.Duration, err error) error {
+	if kind == nil {
+		if kind = KindOfStatus(statusCode); kind == nil {
+			return err
+		}
+	}
+	return &xai.Error{
+		Kind:       kind,
+		Err:        err,
+		StatusCode: statusCode,
+		RetryAfter: retryAfter,
+	}
+}
+
+// RetryAfter parses the `retry-after-ms` and `retry-after` headers of an HTTP
+// response. `retry-after` may be either a number of seconds or an HTTP date.
+// It returns 0 if none of them is present or valid.
+func RetryAfter(h http.Header) time.Duration {
+	if h == nil {
+		return 0
+	}
+	if v := h.Get("Retry-After-Ms"); v != "" {
+		if ms, err := strconv.ParseFloat(v, 64); err == nil && ms > 0 {
+			return time.Duration(ms * float64(time.Millisecond))
+		}
+	}
+	if v := h.Get("Retry-After"); v != "" {
+		if secs, err := strconv.ParseFloat(v, 64); err == nil {
+			if secs > 0 {
+				return time.Duration(secs * float64(time.Second))
+			}
+		} else if t, err := http.ParseTime(v); err == nil {
+			if d := time.Until(t); d > 0 {
+				return d
+			}
+		}
+	}
+	return 0
+}
+
+// -----------------------------------------------------------------------------
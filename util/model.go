@@ -0,0 +1,108 @@
+/*
+ * Copyright (c) 2026 The XGo Authors (xgo.dev). All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package util
+
+import (
+	"path"
+	"slices"
+
+	"github.com/goplus/xai"
+)
+
+// -----------------------------------------------------------------------------
+
+// ModelTable is a static table of known models, which completes the models
+// listed by the API of a provider, or lists them if the API doesn't exist.
+//
+// IDs of its entries are patterns of model names, as of path.Match, e.g.
+// "claude-sonnet-4*", and the first matching entry applies. So more specific
+// entries come first.
+type ModelTable []xai.ModelInfo
+
+// Lookup returns a copy of the first entry matching model, with ID set to model.
+func (p ModelTable) Lookup(model xai.Model) (ret *xai.ModelInfo, ok bool) {
+	for i := range p {
+		if matched, _ := path.Match(string(p[i].ID), string(model)); matched {
+			ret = clone(&p[i])
+			ret.ID = model
+			return ret, true
+		}
+	}
+	return &xai.ModelInfo{ID: model}, false
+}
+
+// Complete fills the unknown fields of info by the first entry matching info.ID.
+func (p ModelTable) Complete(info *xai.ModelInfo) *xai.ModelInfo {
+	v, ok := p.Lookup(info.ID)
+	if !ok {
+		return info
+	}
+	if info.DisplayName == "" {
+		info.DisplayName = v.DisplayName
+	}
+	if info.ContextWindow == 0 {
+		info.ContextWindow = v.ContextWindow
+	}
+	if info.MaxOutputTokens == 0 {
+		info.MaxOutputTokens = v.MaxOutputTokens
+	}
+	if info.InputModalities == nil {
+		info.InputModalities = v.InputModalities
+	}
+	if info.OutputModalities == nil {
+		info.OutputModalities = v.OutputModalities
+	}
+	info.Tools = info.Tools || v.Tools
+	info.Thinking = info.Thinking || v.Thinking
+	info.StructuredOutput = info.StructuredOutput || v.StructuredOutput
+	if info.Actions == nil {
+		info.Actions = v.Actions
+	}
+	return info
+}
+
+// Models lists the entries whose IDs are model names rather than patterns.
+func (p ModelTable) Models() []*xai.ModelInfo {
+	ret := make([]*xai.ModelInfo, 0, len(p))
+	for i := range p {
+		if !hasMeta(string(p[i].ID)) {
+			ret = append(ret, clone(&p[i]))
+		}
+	}
+	return ret
+}
+
+// clone returns a copy of info, which doesn't share slices with the table.
+func clone(info *xai.ModelInfo) *xai.ModelInfo {
+	ret := *info
+	ret.InputModalities = slices.Clone(info.InputModalities)
+	ret.OutputModalities = slices.Clone(info.OutputModalities)
+	ret.Actions = slices.Clone(info.Actions)
+	return &ret
+}
+
+func hasMeta(pattern string) bool {
+	for i := 0; i < len(pattern); i++ {
+		switch pattern[i] {
+		case '*', '?', '[', '\\':
+			return true
+		}
+	}
+	return false
+}
+
+// -----------------------------------------------------------------------------
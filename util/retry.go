@@ -0,0 +1,247 @@
+/*
+ * Copyright (c) 2026 The XGo Authors (xgo.dev). All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package util
+
+import (
+	"context"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/goplus/xai"
+)
+
+// -----------------------------------------------------------------------------
+
+const (
+	retryBaseDelay  = 500 * time.Millisecond
+	retryMaxDelay   = 8 * time.Second // max backoff delay without Retry-After
+	defaultMaxWait  = time.Minute
+	maxDrainedBytes = 64 << 10
+)
+
+// DefaultRetryPolicy is the retry policy of services whose URI doesn't specify
+// `retries`.
+var DefaultRetryPolicy = xai.RetryPolicy{MaxRetries: 2}
+
+// ParseRetryPolicy parses the `retries` and `retry_max_wait` parameters of a
+// service URI. Parameters not specified default to DefaultRetryPolicy.
+func ParseRetryPolicy(params url.Values) (ret xai.RetryPolicy, err error) {
+	ret = DefaultRetryPolicy
+	if retries := params["retries"]; len(retries) > 0 {
+		if ret.MaxRetries, err = strconv.Atoi(retries[0]); err != nil {
+			return
+		}
+	}
+	if maxWait := params["retry_max_wait"]; len(maxWait) > 0 {
+		if ret.MaxWait, err = time.ParseDuration(maxWait[0]); err != nil {
+			return
+		}
+	}
+	return
+}
+
+type retryKey struct{}
+
+// WithRetryPolicy returns a context that overrides the retry policy of requests
+// made with it. It returns ctx itself if policy is nil.
+func WithRetryPolicy(ctx context.Context, policy *xai.RetryPolicy) context.Context {
+	if policy == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, retryKey{}, *policy)
+}
+
+// RetryPolicyOf returns the retry policy set by WithRetryPolicy, or def if ctx
+// doesn't have one.
+func RetryPolicyOf(ctx context.Context, def xai.RetryPolicy) xai.RetryPolicy {
+	if policy, ok := ctx.Value(retryKey{}).(xai.RetryPolicy); ok {
+		return policy
+	}
+	return def
+}
+
+type timeoutKey struct{}
+
+// WithTimeout returns a context that sets the timeout of each attempt of requests
+// made with it, rather than of all the attempts. It returns ctx itself if timeout
+// isn't positive.
+func WithTimeout(ctx context.Context, timeout time.Duration) context.Context {
+	if timeout <= 0 {
+		return ctx
+	}
+	return context.WithValue(ctx, timeoutKey{}, timeout)
+}
+
+// TimeoutOf returns the timeout set by WithTimeout, or 0 if ctx doesn't have one.
+func TimeoutOf(ctx context.Context) time.Duration {
+	timeout, _ := ctx.Value(timeoutKey{}).(time.Duration)
+	return timeout
+}
+
+// -----------------------------------------------------------------------------
+
+// DoWithRetry sends req by do, and retries it according to the retry policy of
+// req's context (see RetryPolicyOf), which defaults to policy. Requests with a
+// body are retried only if req.GetBody is set. The timeout of req's context (see
+// TimeoutOf) applies to each attempt, including reading its response body.
+//
+// It returns the response or error of the last attempt, as do returns them.
+func DoWithRetry(req *http.Request, policy xai.RetryPolicy, do func(*http.Request) (*http.Response, error)) (*http.Response, error) {
+	ctx := req.Context()
+	policy = RetryPolicyOf(ctx, policy)
+	timeout := TimeoutOf(ctx)
+	for attempt := 0; ; attempt++ {
+		resp, err := doAttempt(req, timeout, do)
+		if attempt >= policy.MaxRetries || !shouldRetry(ctx, resp, err) ||
+			req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+			return resp, err
+		}
+		var retryAfter time.Duration
+		if resp != nil {
+			retryAfter = RetryAfter(resp.Header)
+		}
+		wait, ok := retryDelay(policy, attempt, retryAfter)
+		if !ok {
+			return resp, err
+		}
+		if resp != nil {
+			// drain the body, so that the connection can be reused
+			io.CopyN(io.Discard, resp.Body, maxDrainedBytes)
+			resp.Body.Close()
+		}
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+		if req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			req = req.Clone(ctx)
+			req.Body = body
+		}
+	}
+}
+
+// doAttempt sends req by do, canceling it if it takes longer than timeout.
+func doAttempt(req *http.Request, timeout time.Duration, do func(*http.Request) (*http.Response, error)) (*http.Response, error) {
+	if timeout <= 0 {
+		return do(req)
+	}
+	ctx, cancel := context.WithTimeout(req.Context(), timeout)
+	resp, err := do(req.WithContext(ctx))
+	if err != nil {
+		cancel()
+		return resp, err
+	}
+	resp.Body = &cancelBody{resp.Body, cancel}
+	return resp, nil
+}
+
+// cancelBody cancels the context of its request when it is closed.
+type cancelBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (p *cancelBody) Close() error {
+	err := p.ReadCloser.Close()
+	p.cancel()
+	return err
+}
+
+// shouldRetry reports whether a request is failed with a transient error.
+func shouldRetry(ctx context.Context, resp *http.Response, err error) bool {
+	if err != nil {
+		return ctx.Err() == nil // network errors, unless canceled
+	}
+	switch resp.Header.Get("X-Should-Retry") {
+	case "true":
+		return true
+	case "false":
+		return false
+	}
+	code := resp.StatusCode
+	return code == http.StatusRequestTimeout || code == http.StatusConflict ||
+		code == http.StatusTooManyRequests || code >= 500 && code != http.StatusNotImplemented
+}
+
+// retryDelay returns the delay before the retry after the attempt-th attempt. It
+// uses exponential backoff with equal jitter, i.e. a random delay between half of
+// the backoff and the backoff, unless the server asks to wait by Retry-After. It reports false if the delay exceeds the max wait of policy.
+func retryDelay(policy xai.RetryPolicy, attempt int, retryAfter time.Duration) (time.Duration, bool) {
+	maxWait := policy.MaxWait
+	if maxWait <= 0 {
+		maxWait = defaultMaxWait
+	}
+	if retryAfter > 0 {
+		return retryAfter, retryAfter <= maxWait
+	}
+	delay := min(retryBaseDelay<<min(attempt, 16), retryMaxDelay, maxWait)
+	return delay/2 + rand.N(delay/2+1), true
+}
+
+// -----------------------------------------------------------------------------
+
+// RetryTransport is an http.RoundTripper that retries requests failed with
+// transient errors. See DoWithRetry.
+type RetryTransport struct {
+	// Base is the underlying RoundTripper. If nil, http.DefaultTransport is used.
+	Base http.RoundTripper
+
+	// Policy is the default retry policy.
+	Policy xai.RetryPolicy
+
+	// Timeout is the default timeout of each attempt. Zero means no timeout.
+	Timeout time.Duration
+}
+
+func (p *RetryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := p.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return DoWithRetry(withDefaultTimeout(req, p.Timeout), p.Policy, base.RoundTrip)
+}
+
+// RetryMiddleware returns a middleware of SDK clients that retries requests failed
+// with transient errors, and times out each attempt after timeout by default.
+// Zero timeout means no timeout. See DoWithRetry.
+func RetryMiddleware(policy xai.RetryPolicy, timeout time.Duration) func(req *http.Request, next func(*http.Request) (*http.Response, error)) (*http.Response, error) {
+	return func(req *http.Request, next func(*http.Request) (*http.Response, error)) (*http.Response, error) {
+		return DoWithRetry(withDefaultTimeout(req, timeout), policy, next)
+	}
+}
+
+// withDefaultTimeout returns req with the timeout of each attempt, unless its
+// context has one already.
+func withDefaultTimeout(req *http.Request, timeout time.Duration) *http.Request {
+	if ctx := req.Context(); timeout > 0 && TimeoutOf(ctx) == 0 {
+		return req.WithContext(WithTimeout(ctx, timeout))
+	}
+	return req
+}
+
+// -----------------------------------------------------------------------------
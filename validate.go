@@ -0,0 +1,279 @@
+/*
+ * Copyright (c) 2026 The XGo Authors (xgo.dev). All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package xai
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// -----------------------------------------------------------------------------
+
+// SchemaError represents a JSON value that doesn't match its JSON schema.
+type SchemaError struct {
+	// Path is the JSON pointer of the invalid value, e.g. "/items/0/name". It
+	// is empty for the root value.
+	Path string
+
+	// Reason describes why the value is invalid.
+	Reason string
+}
+
+func (e *SchemaError) Error() string {
+	path := e.Path
+	if path == "" {
+		path = "/"
+	}
+	return "schema violation at " + path + ": " + e.Reason
+}
+
+// ValidateJSON validates the JSON document data against schema. It supports
+// the subset of JSON schema used by structured outputs: type, enum, const,
+// properties, required, additionalProperties, items, anyOf, oneOf, allOf, $ref,
+// and the basic length and range keywords. All violations are reported, joined
+// by `errors.Join`, and each of them is a *SchemaError.
+func ValidateJSON(schema map[string]any, data []byte) error {
+	var v any
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	vd := &validator{root: schema}
+	vd.validate(schema, v, "")
+	return errors.Join(vd.errs...)
+}
+
+type validator struct {
+	root map[string]any
+	errs []error
+}
+
+func (p *validator) fail(path, format string, args ...any) {
+	p.errs = append(p.errs, &SchemaError{Path: path, Reason: fmt.Sprintf(format, args...)})
+}
+
+// valid reports whether v matches schema, without recording any violation.
+func (p *validator) valid(schema map[string]any, v any, path string) bool {
+	sub := &validator{root: p.root}
+	sub.validate(schema, v, path)
+	return len(sub.errs) == 0
+}
+
+func (p *validator) resolve(ref string) map[string]any {
+	if !strings.HasPrefix(ref, "#") {
+		return nil // only local references are supported
+	}
+	var node any = p.root
+	for _, tok := range strings.Split(strings.TrimPrefix(ref[1:], "/"), "/") {
+		if tok == "" {
+			continue
+		}
+		tok = strings.ReplaceAll(strings.ReplaceAll(tok, "~1", "/"), "~0", "~")
+		m, ok := node.(map[string]any)
+		if !ok {
+			return nil
+		}
+		node = m[tok]
+	}
+	ret, _ := node.(map[string]any)
+	return ret
+}
+
+func (p *validator) validate(schema map[string]any, v any, path string) {
+	if ref, ok := schema["$ref"].(string); ok {
+		target := p.resolve(ref)
+		if target == nil {
+			p.fail(path, "unresolved $ref %q", ref)
+			return
+		}
+		p.validate(target, v, path)
+		return
+	}
+	if t, ok := schema["type"]; ok && !matchType(t, v) {
+		p.fail(path, "expected %v, got %s", t, typeName(v))
+		return
+	}
+	if enum, ok := schema["enum"].([]any); ok {
+		if !slices.ContainsFunc(enum, func(e any) bool { return reflect.DeepEqual(e, v) }) {
+			p.fail(path, "value %v is not one of %v", v, enum)
+		}
+	}
+	if c, ok := schema["const"]; ok && !reflect.DeepEqual(c, v) {
+		p.fail(path, "value %v is not %v", v, c)
+	}
+	if subs, ok := schema["allOf"].([]any); ok {
+		for _, sub := range subs {
+			if s, ok := sub.(map[string]any); ok {
+				p.validate(s, v, path)
+			}
+		}
+	}
+	if subs, ok := schema["anyOf"].([]any); ok && p.countValid(subs, v, path) == 0 {
+		p.fail(path, "value doesn't match any schema of anyOf")
+	}
+	if subs, ok := schema["oneOf"].([]any); ok {
+		if n := p.countValid(subs, v, path); n != 1 {
+			p.fail(path, "value matches %d schemas of oneOf, expected 1", n)
+		}
+	}
+	switch v := v.(type) {
+	case map[string]any:
+		p.validateObject(schema, v, path)
+	case []any:
+		p.validateArray(schema, v, path)
+	case string:
+		p.validateString(schema, v, path)
+	case float64:
+		p.validateNumber(schema, v, path)
+	}
+}
+
+func (p *validator) countValid(subs []any, v any, path string) (n int) {
+	for _, sub := range subs {
+		if s, ok := sub.(map[string]any); ok && p.valid(s, v, path) {
+			n++
+		}
+	}
+	return
+}
+
+func (p *validator) validateObject(schema, v map[string]any, path string) {
+	props, _ := schema["properties"].(map[string]any)
+	if required, ok := schema["required"].([]any); ok {
+		for _, name := range required {
+			if name, ok := name.(string); ok {
+				if _, ok := v[name]; !ok {
+					p.fail(path, "missing required property %q", name)
+				}
+			}
+		}
+	}
+	keys := make([]string, 0, len(v))
+	for k := range v {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	for _, k := range keys {
+		sub := path + "/" + strings.ReplaceAll(strings.ReplaceAll(k, "~", "~0"), "/", "~1")
+		if s, ok := props[k].(map[string]any); ok {
+			p.validate(s, v[k], sub)
+			continue
+		}
+		switch ap := schema["additionalProperties"].(type) {
+		case bool:
+			if !ap {
+				p.fail(path, "unexpected property %q", k)
+			}
+		case map[string]any:
+			p.validate(ap, v[k], sub)
+		}
+	}
+}
+
+func (p *validator) validateArray(schema map[string]any, v []any, path string) {
+	if items, ok := schema["items"].(map[string]any); ok {
+		for i, item := range v {
+			p.validate(items, item, path+"/"+strconv.Itoa(i))
+		}
+	}
+	if n, ok := schema["minItems"].(float64); ok && float64(len(v)) < n {
+		p.fail(path, "expected at least %v items, got %d", n, len(v))
+	}
+	if n, ok := schema["maxItems"].(float64); ok && float64(len(v)) > n {
+		p.fail(path, "expected at most %v items, got %d", n, len(v))
+	}
+}
+
+func (p *validator) validateString(schema map[string]any, v string, path string) {
+	n := float64(utf8.RuneCountInString(v))
+	if min, ok := schema["minLength"].(float64); ok && n < min {
+		p.fail(path, "expected at least %v characters, got %v", min, n)
+	}
+	if max, ok := schema["maxLength"].(float64); ok && n > max {
+		p.fail(path, "expected at most %v characters, got %v", max, n)
+	}
+	if pattern, ok := schema["pattern"].(string); ok {
+		if re, err := regexp.Compile(pattern); err == nil && !re.MatchString(v) {
+			p.fail(path, "value %q doesn't match pattern %q", v, pattern)
+		}
+	}
+}
+
+func (p *validator) validateNumber(schema map[string]any, v float64, path string) {
+	if min, ok := schema["minimum"].(float64); ok && v < min {
+		p.fail(path, "value %v is less than minimum %v", v, min)
+	}
+	if max, ok := schema["maximum"].(float64); ok && v > max {
+		p.fail(path, "value %v is greater than maximum %v", v, max)
+	}
+	if min, ok := schema["exclusiveMinimum"].(float64); ok && v <= min {
+		p.fail(path, "value %v is not greater than %v", v, min)
+	}
+	if max, ok := schema["exclusiveMaximum"].(float64); ok && v >= max {
+		p.fail(path, "value %v is not less than %v", v, max)
+	}
+}
+
+func matchType(t any, v any) bool {
+	switch t := t.(type) {
+	case string:
+		return matchTypeName(t, v)
+	case []any:
+		for _, name := range t {
+			if name, ok := name.(string); ok && matchTypeName(name, v) {
+				return true
+			}
+		}
+		return false
+	}
+	return true // unknown type keyword, ignore it
+}
+
+func matchTypeName(name string, v any) bool {
+	if name == "integer" {
+		f, ok := v.(float64)
+		return ok && f == math.Trunc(f)
+	}
+	return typeName(v) == name
+}
+
+func typeName(v any) string {
+	switch v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case float64:
+		return "number"
+	case string:
+		return "string"
+	case []any:
+		return "array"
+	case map[string]any:
+		return "object"
+	}
+	return fmt.Sprintf("%T", v)
+}
+
+// -----------------------------------------------------------------------------
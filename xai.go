@@ -20,6 +20,8 @@ import (
 	"context"
 	"errors"
 	"iter"
+	"net/http"
+	"net/url"
 	"strings"
 	"time"
 )
@@ -129,6 +131,18 @@ type GenParams interface {
 	// return results back to the model using `tool_result` content blocks.
 	Tools(tools ...ToolBase) GenParams
 
+	// ToolChoice specifies how the model should use the provided tools. The model
+	// can decide by itself (ToolChoiceAuto), use any available tool (ToolChoiceAny),
+	// not use tools at all (ToolChoiceNone), or use a specific tool (`UseTool`).
+	ToolChoice(ToolChoice) GenParams
+
+	// ParallelToolCalls specifies whether the model may call multiple tools in a
+	// single response. Most providers allow it by default.
+	//
+	// Providers that can't disable parallel tool calls ignore it, e.g. gemini,
+	// whose models may always call multiple tools in a response.
+	ParallelToolCalls(bool) GenParams
+
 	// The model that will complete your prompt.
 	Model(Model) GenParams
 
@@ -147,6 +161,14 @@ type GenParams interface {
 	// The format of the content is provider-specific.
 	Compact(maxInputTokens int64) GenParams
 
+	// CacheSystem marks the end of the system prompt as a prompt cache breakpoint,
+	// see `MsgBuilder.CacheBreakpoint`. It has no effect without a system prompt.
+	CacheSystem(ttl time.Duration) GenParams
+
+	// CacheTools marks the end of the tools as a prompt cache breakpoint, see
+	// `MsgBuilder.CacheBreakpoint`. It has no effect without tools.
+	CacheTools(ttl time.Duration) GenParams
+
 	// Amount of randomness injected into the response.
 	//
 	// Defaults to `1.0`. Ranges from `0.0` to `1.0`. Use `temperature` closer to `0.0`
@@ -168,12 +190,30 @@ type GenParams interface {
 	// `temperature`.
 	TopP(float64) GenParams
 
+	// Thinking configures the thinking (reasoning) of the model, e.g. its token
+	// budget or reasoning effort, and whether its thinking is returned. Thinking
+	// parts of the response should be passed back with `MsgBuilder.Thinking` or
+	// `Candidate.ToMsg` in multi-turn conversations.
+	//
+	// Models that don't support thinking ignore it.
+	Thinking(ThinkingConfig) GenParams
+
+	// ResponseFormat constrains the output of the model to a specific format,
+	// e.g. JSON that matches a schema. Use `JSONSchema` or `JSONObject` to create
+	// the format, and `ResponseFormat.Decode` to decode the output.
+	//
+	// Providers that don't support a format natively ignore it.
+	ResponseFormat(ResponseFormat) GenParams
+
 	// BaseURL sets the base URL for the API endpoint.
 	BaseURL(string) GenParams
 
 	// Timeout sets a timeout for the API request. If the request takes longer than
 	// the specified duration, it will be aborted and an error will be returned.
 	Timeout(time.Duration) GenParams
+
+	// Retry overrides the retry policy of the service for this request.
+	Retry(RetryPolicy) GenParams
 }
 
 // -----------------------------------------------------------------------------
@@ -207,12 +247,52 @@ type Candidate interface {
 	ToMsg() MsgBuilder
 }
 
+// Usage represents the number of tokens billed for a generation request. The
+// counts are normalized across providers, so they can be compared and summed
+// regardless of which provider served the request.
+type Usage struct {
+	// InputTokens is the total number of input tokens, including the tokens
+	// read from or written to the prompt cache.
+	InputTokens int64
+
+	// OutputTokens is the total number of output tokens, including reasoning
+	// tokens.
+	OutputTokens int64
+
+	// CacheReadTokens is the number of input tokens read from the prompt cache.
+	CacheReadTokens int64
+
+	// CacheWriteTokens is the number of input tokens written to the prompt cache.
+	CacheWriteTokens int64
+
+	// ReasoningTokens is the number of output tokens used for reasoning, or 0 if
+	// the provider doesn't report it separately.
+	ReasoningTokens int64
+}
+
+// TotalTokens returns the sum of input and output tokens.
+func (u Usage) TotalTokens() int64 {
+	return u.InputTokens + u.OutputTokens
+}
+
+// Add adds the token counts of v to u.
+func (u *Usage) Add(v Usage) {
+	u.InputTokens += v.InputTokens
+	u.OutputTokens += v.OutputTokens
+	u.CacheReadTokens += v.CacheReadTokens
+	u.CacheWriteTokens += v.CacheWriteTokens
+	u.ReasoningTokens += v.ReasoningTokens
+}
+
 // GenResponse represents the response from a generation request. It contains one
 // or more candidates, which are the different possible completions generated by
 // the model for the given input and parameters.
 type GenResponse interface {
 	Len() int
 	At(i int) Candidate
+
+	// Usage returns the token usage of the request.
+	Usage() Usage
 }
 
 // -----------------------------------------------------------------------------
@@ -230,6 +310,10 @@ const (
 	FeatureGen Feature = 1 << iota
 	FeatureGenStream
 	FeatureOperation
+	FeatureCountTokens
+	FeatureEmbed
+	FeatureFiles
+	FeatureBatch
 )
 
 type Service interface {
@@ -257,10 +341,40 @@ type Service interface {
 	// model will generate the next message in the conversation.
 	//
 	// The GenStream API can be used for either single queries or stateless multi-turn
-	// conversations.
+	// conversations. The response is streamed as a sequence of `StreamEvent`s, and
+	// the last event (EventStop) holds the complete response. Use `Accumulator` or
+	// `Accumulate` to collect the events.
 	//
 	// Note: If you choose to set a timeout for this request, we recommend 10 minutes.
-	GenStream(ctx context.Context, params GenParams) iter.Seq2[GenResponse, error]
+	GenStream(ctx context.Context, params GenParams) iter.Seq2[StreamEvent, error]
+
+	// CountTokens counts the input tokens of a generation request with params,
+	// without generating a response, e.g. to check whether the messages fit in
+	// the context window of the model before sending them. It is supported if
+	// the Service has `FeatureCountTokens`.
+	CountTokens(ctx context.Context, params GenParams) (int64, error)
+
+	// Embed creates embeddings of the texts and images of params, e.g. for
+	// retrieval. It is supported if the Service has `FeatureEmbed`, or returns
+	// ErrNotFound otherwise.
+	Embed(ctx context.Context, params EmbedParams) (*Embeddings, error)
+
+	// Models lists the models available from this Service and their capabilities.
+	// They are listed by the API of the provider where it exists, and completed by
+	// a static table of known models.
+	Models(ctx context.Context) ([]*ModelInfo, error)
+
+	// SubmitBatch submits the generation requests of reqs as a batch, which is
+	// processed asynchronously at a lower price, e.g. within 24 hours. Wait for
+	// the returned BatchResponse to get the results of the requests. It is
+	// supported if the Service has `FeatureBatch`, or returns ErrNotFound
+	// otherwise.
+	SubmitBatch(ctx context.Context, reqs []GenParams) (BatchResponse, error)
+
+	// Files returns the `Files` to upload files that are referenced by ID in the
+	// messages, e.g. images and documents used in many requests. It is supported
+	// if the Service has `FeatureFiles`, or returns nil otherwise.
+	Files() Files
 
 	// GenParams creates a `GenParams` that can be used to build the parameters for
 	// generation requests. This includes setting the system prompt, input messages,
@@ -307,25 +421,74 @@ type NewFunc = func(ctx context.Context, uri string) (Service, error)
 
 var (
 	creators = map[string]NewFunc{}
+	opaques  = map[string]bool{} // schemes whose URIs aren't URL queries
 )
 
 // Register registers a NewFunc for a specific scheme. This allows different
 // services to be created based on the scheme in the URI.
 func Register(scheme string, creator NewFunc) {
 	creators[scheme] = creator
+	delete(opaques, scheme)
+}
+
+// RegisterOpaque registers a NewFunc for a scheme whose URIs aren't in the form
+// of URL queries, e.g. they contain other URIs. `New` passes the URIs of the
+// scheme as is to creator, without applying the limits of the URI parameters.
+func RegisterOpaque(scheme string, creator NewFunc) {
+	creators[scheme] = creator
+	opaques[scheme] = true
 }
 
 // New creates a new Service instance based on the scheme in the given URI. It
 // looks up the scheme in the registered creators and calls the corresponding
 // NewFunc. If the scheme is not found, it returns an ErrUnknownScheme error.
+//
+// The following parameters of the URI apply to services of all schemes, except
+// the ones registered by `RegisterOpaque`:
+//
+// `rpm` is the max number of requests per minute.
+// `tpm` is the max number of tokens per minute.
+// `max_concurrency` is the max number of concurrent requests.
+//
+// See `Limits` for details.
 func New(ctx context.Context, uri string) (Service, error) {
 	scheme := schemeOf(uri)
 	if creator, ok := creators[scheme]; ok {
-		return creator(ctx, uri)
+		if opaques[scheme] {
+			return creator(ctx, uri)
+		}
+		params, err := url.ParseQuery(uri[len(scheme)+1:])
+		if err != nil {
+			return nil, err
+		}
+		limits, err := ParseLimits(params)
+		if err != nil {
+			return nil, err
+		}
+		svc, err := creator(ctx, uri)
+		if err != nil {
+			return nil, err
+		}
+		return Limit(svc, limits), nil
 	}
 	return nil, ErrUnknownScheme
 }
 
+type httpClientKey struct{}
+
+// WithHTTPClient returns a context that makes `New` create services that send
+// requests with client, e.g. to record or replay the requests in tests.
+func WithHTTPClient(ctx context.Context, client *http.Client) context.Context {
+	return context.WithValue(ctx, httpClientKey{}, client)
+}
+
+// HTTPClient returns the HTTP client set by WithHTTPClient, or nil if ctx
+// doesn't have one. It is used by NewFunc implementations.
+func HTTPClient(ctx context.Context) *http.Client {
+	client, _ := ctx.Value(httpClientKey{}).(*http.Client)
+	return client
+}
+
 func schemeOf(uri string) (scheme string) {
 	pos := strings.IndexByte(uri, ':')
 	if pos > 0 {
@@ -0,0 +1,492 @@
+/*
+ * Copyright (c) 2026 The XGo Authors (xgo.dev). All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package xaitest
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"time"
+)
+
+// -----------------------------------------------------------------------------
+
+type anthropicRequest struct {
+	Model     string          `json:"model"`
+	MaxTokens int64           `json:"max_tokens"`
+	System    json.RawMessage `json:"system"`
+	Messages  []struct {
+		Role    string          `json:"role"`
+		Content json.RawMessage `json:"content"`
+	} `json:"messages"`
+	Tools    []anthropicTool `json:"tools"`
+	Thinking struct {
+		Type string `json:"type"`
+	} `json:"thinking"`
+	Stream bool `json:"stream"`
+}
+
+type anthropicTool struct {
+	Type         string          `json:"type"`
+	Name         string          `json:"name"`
+	CacheControl json.RawMessage `json:"cache_control"`
+}
+
+type anthropicBlock struct {
+	Type   string `json:"type"`
+	Text   string `json:"text"`
+	Source struct {
+		Type      string `json:"type"`
+		MediaType string `json:"media_type"`
+		FileID    string `json:"file_id"`
+	} `json:"source"`
+	ID        string          `json:"id"`
+	Name      string          `json:"name"`
+	Input     map[string]any  `json:"input"`
+	ToolUseID string          `json:"tool_use_id"`
+	Content   json.RawMessage `json:"content"`
+	Thinking  string          `json:"thinking"`
+	Signature string          `json:"signature"`
+	Citations json.RawMessage `json:"citations"` // config of documents, or citations of text
+
+	CacheControl json.RawMessage `json:"cache_control"`
+}
+
+// anthropicBlocks decodes content that is either a string or a list of blocks.
+func anthropicBlocks(content json.RawMessage) (ret []anthropicBlock, err error) {
+	var text string
+	if err = json.Unmarshal(content, &text); err == nil {
+		return []anthropicBlock{{Type: "text", Text: text}}, nil
+	}
+	err = json.Unmarshal(content, &ret)
+	return
+}
+
+func (p *anthropicRequest) fake() (*fakeRequest, error) {
+	ret := &fakeRequest{maxTokens: p.MaxTokens, thinking: p.Thinking.Type == "enabled"}
+	// the prompt is cached up to the last tool or block with cache_control, in the
+	// order of tools, system and messages.
+	cached := false
+	if len(p.System) > 0 {
+		blocks, err := anthropicBlocks(p.System)
+		if err != nil {
+			return nil, err
+		}
+		for _, b := range blocks {
+			ret.system += b.Text
+			cached = cached || b.CacheControl != nil
+		}
+	}
+	for _, tool := range p.Tools {
+		if strings.HasPrefix(tool.Type, "web_search_") {
+			ret.webSearch = true
+		} else {
+			ret.tools = append(ret.tools, tool.Name)
+		}
+		cached = cached || tool.CacheControl != nil
+	}
+	if cached {
+		ret.cache(-1, 0)
+	}
+	for _, msg := range p.Messages {
+		blocks, err := anthropicBlocks(msg.Content)
+		if err != nil {
+			return nil, err
+		}
+		ret.msgs = append(ret.msgs, fakeMsg{assistant: msg.Role == "assistant"})
+		m := &ret.msgs[len(ret.msgs)-1]
+		for _, b := range blocks {
+			var part fakePart
+			switch b.Type {
+			case "text":
+				part = fakePart{kind: fakeText, text: b.Text}
+			case "image", "document":
+				part = fakePart{kind: fakeImage, text: b.Source.MediaType, file: b.Source.FileID}
+				if b.Type == "document" {
+					var citations struct {
+						Enabled bool `json:"enabled"`
+					}
+					if len(b.Citations) > 0 {
+						if err := json.Unmarshal(b.Citations, &citations); err != nil {
+							return nil, err
+						}
+					}
+					part.kind, part.cite = fakeDoc, citations.Enabled
+				}
+				if part.text == "" {
+					part.text = b.Source.Type
+				}
+			case "thinking":
+				part = fakePart{kind: fakeThinking, text: b.Thinking, sig: b.Signature}
+			case "tool_use":
+				part = fakePart{kind: fakeToolUse, id: b.ID, name: b.Name, input: b.Input}
+			case "tool_result":
+				part = fakePart{kind: fakeToolResult, id: b.ToolUseID}
+				results, err := anthropicBlocks(b.Content)
+				if err != nil {
+					return nil, err
+				}
+				for _, r := range results {
+					part.text += r.Text
+				}
+			default:
+				continue
+			}
+			m.parts = append(m.parts, part)
+			if b.CacheControl != nil {
+				ret.cache(len(ret.msgs)-1, len(m.parts))
+			}
+		}
+	}
+	return ret, nil
+}
+
+// anthropicContent returns the content blocks of part. A web search consists of
+// a server_tool_use block and a web_search_tool_result block.
+func anthropicContent(part fakePart) []map[string]any {
+	switch part.kind {
+	case fakeThinking:
+		return []map[string]any{{"type": "thinking", "thinking": part.text, "signature": part.sig}}
+	case fakeToolUse:
+		return []map[string]any{{"type": "tool_use", "id": part.id, "name": part.name, "input": part.input}}
+	case fakeSearch:
+		id := "srvtoolu_" + part.id
+		return []map[string]any{
+			{"type": "server_tool_use", "id": id, "name": "web_search", "input": map[string]any{"query": part.text}},
+			{"type": "web_search_tool_result", "tool_use_id": id, "content": anthropicSearchResults(part)},
+		}
+	}
+	ret := map[string]any{"type": "text", "text": part.text}
+	if len(part.cites) > 0 {
+		citations := make([]map[string]any, len(part.cites))
+		for i, c := range part.cites {
+			citations[i] = anthropicCitation(c)
+		}
+		ret["citations"] = citations
+	}
+	return []map[string]any{ret}
+}
+
+func anthropicSearchResults(part fakePart) []map[string]any {
+	ret := make([]map[string]any, len(part.cites))
+	for i, c := range part.cites {
+		ret[i] = map[string]any{
+			"type": "web_search_result", "url": c.url, "title": c.title,
+			"encrypted_content": "enc-" + part.id, "page_age": nil,
+		}
+	}
+	return ret
+}
+
+// anthropicCitation returns the citation c, which cites the whole text block as
+// the text blocks of Claude are split by their citations. The fake sources have
+// no text to cite.
+func anthropicCitation(c fakeCite) map[string]any {
+	if c.doc < 0 {
+		return map[string]any{
+			"type": "web_search_result_location", "url": c.url, "title": c.title,
+			"cited_text": "", "encrypted_index": "",
+		}
+	}
+	return map[string]any{
+		"type": "char_location", "document_index": c.doc, "document_title": nil,
+		"cited_text": "", "start_char_index": 0, "end_char_index": 0,
+	}
+}
+
+func anthropicMessage(id, model string, reply *fakeReply) map[string]any {
+	content := []map[string]any{}
+	for _, part := range reply.parts {
+		content = append(content, anthropicContent(part)...)
+	}
+	return map[string]any{
+		"id":            id,
+		"type":          "message",
+		"role":          "assistant",
+		"model":         model,
+		"content":       content,
+		"stop_reason":   reply.stop,
+		"stop_sequence": nil,
+		"usage": map[string]any{
+			"input_tokens":                reply.inputTokens - reply.cacheRead - reply.cacheWrite,
+			"output_tokens":               reply.outputTokens,
+			"cache_read_input_tokens":     reply.cacheRead,
+			"cache_creation_input_tokens": reply.cacheWrite,
+		},
+	}
+}
+
+func anthropicStream(w http.ResponseWriter, id, model string, reply *fakeReply) {
+	sse := newSSEWriter(w)
+	start := anthropicMessage(id, model, &fakeReply{
+		inputTokens: reply.inputTokens, cacheRead: reply.cacheRead, cacheWrite: reply.cacheWrite,
+	})
+	start["stop_reason"] = nil
+	sse.send("message_start", map[string]any{"type": "message_start", "message": start})
+	i := 0
+	send := func(block map[string]any, deltas []map[string]any) {
+		sse.send("content_block_start", map[string]any{"type": "content_block_start", "index": i, "content_block": block})
+		for _, delta := range deltas {
+			sse.send("content_block_delta", map[string]any{"type": "content_block_delta", "index": i, "delta": delta})
+		}
+		sse.send("content_block_stop", map[string]any{"type": "content_block_stop", "index": i})
+		i++
+	}
+	for _, part := range reply.parts {
+		var block map[string]any
+		var deltas []map[string]any
+		switch part.kind {
+		case fakeThinking:
+			block = map[string]any{"type": "thinking", "thinking": "", "signature": ""}
+			for _, s := range chunks(part.text) {
+				deltas = append(deltas, map[string]any{"type": "thinking_delta", "thinking": s})
+			}
+			deltas = append(deltas, map[string]any{"type": "signature_delta", "signature": part.sig})
+		case fakeToolUse:
+			block = map[string]any{"type": "tool_use", "id": part.id, "name": part.name, "input": map[string]any{}}
+			b, _ := json.Marshal(part.input)
+			half := len(b) / 2
+			for _, s := range []string{string(b[:half]), string(b[half:])} {
+				deltas = append(deltas, map[string]any{"type": "input_json_delta", "partial_json": s})
+			}
+		case fakeSearch:
+			blocks := anthropicContent(part)
+			b, _ := json.Marshal(blocks[0]["input"])
+			blocks[0]["input"] = map[string]any{}
+			send(blocks[0], []map[string]any{{"type": "input_json_delta", "partial_json": string(b)}})
+			block = blocks[1]
+		default:
+			block = map[string]any{"type": "text", "text": ""}
+			for _, s := range chunks(part.text) {
+				deltas = append(deltas, map[string]any{"type": "text_delta", "text": s})
+			}
+			for _, c := range part.cites {
+				deltas = append(deltas, map[string]any{"type": "citations_delta", "citation": anthropicCitation(c)})
+			}
+		}
+		send(block, deltas)
+	}
+	sse.send("message_delta", map[string]any{
+		"type":  "message_delta",
+		"delta": map[string]any{"stop_reason": reply.stop, "stop_sequence": nil},
+		"usage": map[string]any{"output_tokens": reply.outputTokens},
+	})
+	sse.send("message_stop", map[string]any{"type": "message_stop"})
+}
+
+func anthropicError(w http.ResponseWriter, status int, typ, msg string) {
+	writeJSON(w, status, map[string]any{
+		"type":  "error",
+		"error": map[string]any{"type": typ, "message": msg},
+	})
+}
+
+// anthropicFiles serves the Files API (beta).
+func anthropicFiles(w http.ResponseWriter, r *http.Request, model *fakeModel) {
+	if !strings.Contains(r.Header.Get("Anthropic-Beta"), "files-api-") {
+		anthropicError(w, http.StatusBadRequest, "invalid_request_error", "the files API beta is required")
+		return
+	}
+	metadata := func(f *fakeFile) map[string]any {
+		return map[string]any{
+			"type": "file", "id": f.id, "filename": f.name, "mime_type": f.mime,
+			"size_bytes": f.size, "created_at": f.created.Format(time.RFC3339),
+		}
+	}
+	id := strings.TrimPrefix(r.URL.Path, "/v1/files/")
+	switch {
+	case r.Method == http.MethodPost && r.URL.Path == "/v1/files":
+		name, mime, data, err := formFile(r, "file")
+		if err != nil {
+			anthropicError(w, http.StatusBadRequest, "invalid_request_error", err.Error())
+			return
+		}
+		writeJSON(w, http.StatusOK, metadata(model.upload(name, mime, int64(len(data)), 0)))
+	case r.Method == http.MethodGet && r.URL.Path == "/v1/files":
+		data := []map[string]any{}
+		for _, f := range model.listFiles() {
+			data = append(data, metadata(f))
+		}
+		writeJSON(w, http.StatusOK, map[string]any{"data": data, "has_more": false})
+	case r.Method == http.MethodGet:
+		if f, ok := model.file(id); ok {
+			writeJSON(w, http.StatusOK, metadata(f))
+			return
+		}
+		anthropicError(w, http.StatusNotFound, "not_found_error", "file not found: "+id)
+	case r.Method == http.MethodDelete:
+		if model.deleteFile(id) {
+			writeJSON(w, http.StatusOK, map[string]any{"type": "file_deleted", "id": id})
+			return
+		}
+		anthropicError(w, http.StatusNotFound, "not_found_error", "file not found: "+id)
+	default:
+		anthropicError(w, http.StatusNotFound, "not_found_error", "not found: "+r.URL.Path)
+	}
+}
+
+// anthropicBatches serves the Message Batches API (beta), whose batches end once
+// created.
+func anthropicBatches(w http.ResponseWriter, r *http.Request, model *fakeModel) {
+	if !strings.Contains(r.Header.Get("Anthropic-Beta"), "message-batches-") {
+		anthropicError(w, http.StatusBadRequest, "invalid_request_error", "the message batches beta is required")
+		return
+	}
+	batch := func(b *fakeBatch) map[string]any {
+		created := b.created.Format(time.RFC3339)
+		return map[string]any{
+			"type": "message_batch", "id": b.id, "processing_status": "ended",
+			"request_counts": map[string]any{
+				"processing": 0, "succeeded": len(b.results) - b.failed, "errored": b.failed,
+				"canceled": 0, "expired": 0,
+			},
+			"created_at": created, "ended_at": created,
+			"expires_at":  b.created.Add(24 * time.Hour).Format(time.RFC3339),
+			"results_url": "/v1/messages/batches/" + b.id + "/results",
+			"archived_at": nil, "cancel_initiated_at": nil,
+		}
+	}
+	if r.Method == http.MethodPost && r.URL.Path == "/v1/messages/batches" {
+		var in struct {
+			Requests []struct {
+				CustomID string           `json:"custom_id"`
+				Params   anthropicRequest `json:"params"`
+			} `json:"requests"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&in); err != nil || len(in.Requests) == 0 {
+			anthropicError(w, http.StatusBadRequest, "invalid_request_error", "invalid batch")
+			return
+		}
+		results, failed := make([]any, len(in.Requests)), 0
+		for i, req := range in.Requests {
+			reply, err := func() (*fakeReply, error) {
+				fr, err := req.Params.fake()
+				if err != nil {
+					return nil, err
+				}
+				if fr.usesFiles() && !strings.Contains(r.Header.Get("Anthropic-Beta"), "files-api-") {
+					return nil, errors.New("the files API beta is required")
+				}
+				return model.reply(fr)
+			}()
+			result := map[string]any{"type": "errored"}
+			if err != nil {
+				result["error"] = map[string]any{
+					"type": "error", "error": map[string]any{"type": "invalid_request_error", "message": err.Error()},
+				}
+				failed++
+			} else {
+				result = map[string]any{"type": "succeeded", "message": anthropicMessage(model.next("msg_"), req.Params.Model, reply)}
+			}
+			results[i] = map[string]any{"custom_id": req.CustomID, "result": result}
+		}
+		writeJSON(w, http.StatusOK, batch(model.addBatch("msgbatch_", &fakeBatch{results: results, failed: failed})))
+		return
+	}
+	id, results := strings.CutSuffix(strings.TrimPrefix(r.URL.Path, "/v1/messages/batches/"), "/results")
+	b, ok := model.batch(id)
+	if r.Method != http.MethodGet || !ok {
+		anthropicError(w, http.StatusNotFound, "not_found_error", "batch not found: "+id)
+		return
+	}
+	if !results {
+		writeJSON(w, http.StatusOK, batch(b))
+		return
+	}
+	w.Header().Set("Content-Type", "application/x-jsonl")
+	enc := json.NewEncoder(w)
+	for _, result := range b.results {
+		enc.Encode(result)
+	}
+}
+
+// anthropicModels are the models listed by the fake Models API.
+var anthropicModels = []map[string]any{
+	{"type": "model", "id": "claude-sonnet-4-5", "display_name": "Claude Sonnet 4.5", "created_at": "2025-09-29T00:00:00Z"},
+	{"type": "model", "id": "claude-haiku-4-5", "display_name": "Claude Haiku 4.5", "created_at": "2025-10-01T00:00:00Z"},
+}
+
+// NewAnthropicServer starts a server that imitates the Anthropic Messages API
+// (POST /v1/messages and /v1/messages/count_tokens), Models API (GET /v1/models),
+// Files API (/v1/files) and Message Batches API (/v1/messages/batches), replying
+// by the fake model described in Do. Create the service to test with
+// "claude:base=<server URL>&key=<any key>".
+func NewAnthropicServer() *httptest.Server {
+	model := newFakeModel()
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		count := r.URL.Path == "/v1/messages/count_tokens"
+		list := r.Method == http.MethodGet && r.URL.Path == "/v1/models"
+		files := r.URL.Path == "/v1/files" || strings.HasPrefix(r.URL.Path, "/v1/files/")
+		batches := strings.HasPrefix(r.URL.Path, "/v1/messages/batches")
+		if !list && !files && !batches && (r.Method != http.MethodPost || r.URL.Path != "/v1/messages" && !count) {
+			anthropicError(w, http.StatusNotFound, "not_found_error", "not found: "+r.URL.Path)
+			return
+		}
+		if r.Header.Get("X-Api-Key") == "" && !strings.HasPrefix(r.Header.Get("Authorization"), "Bearer ") {
+			anthropicError(w, http.StatusUnauthorized, "authentication_error", "missing API key")
+			return
+		}
+		if list {
+			writeJSON(w, http.StatusOK, map[string]any{
+				"data": anthropicModels, "has_more": false,
+				"first_id": anthropicModels[0]["id"], "last_id": anthropicModels[len(anthropicModels)-1]["id"],
+			})
+			return
+		}
+		if files {
+			anthropicFiles(w, r, model)
+			return
+		}
+		if batches {
+			anthropicBatches(w, r, model)
+			return
+		}
+		var in anthropicRequest
+		if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+			anthropicError(w, http.StatusBadRequest, "invalid_request_error", err.Error())
+			return
+		}
+		req, err := in.fake()
+		if err != nil {
+			anthropicError(w, http.StatusBadRequest, "invalid_request_error", err.Error())
+			return
+		}
+		if req.usesFiles() && !strings.Contains(r.Header.Get("Anthropic-Beta"), "files-api-") {
+			anthropicError(w, http.StatusBadRequest, "invalid_request_error", "the files API beta is required")
+			return
+		}
+		if count {
+			writeJSON(w, http.StatusOK, map[string]any{"input_tokens": req.inputTokens()})
+			return
+		}
+		reply, err := model.reply(req)
+		if err != nil {
+			anthropicError(w, http.StatusBadRequest, "invalid_request_error", err.Error())
+			return
+		}
+		id := model.next("msg_")
+		if in.Stream {
+			anthropicStream(w, id, in.Model, reply)
+		} else {
+			writeJSON(w, http.StatusOK, anthropicMessage(id, in.Model, reply))
+		}
+	}))
+}
+
+// -----------------------------------------------------------------------------
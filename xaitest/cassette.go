@@ -0,0 +1,319 @@
+/*
+ * Copyright (c) 2026 The XGo Authors (xgo.dev). All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package xaitest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/goplus/xai"
+)
+
+// -----------------------------------------------------------------------------
+
+// Mode is the mode of a Recorder.
+type Mode int
+
+const (
+	// ModeReplay serves the requests from the cassette, without network.
+	ModeReplay Mode = iota
+
+	// ModeRecord sends the requests to the server, and saves them with their
+	// responses to the cassette.
+	ModeRecord
+)
+
+// Interaction is a request/response pair of a cassette.
+type Interaction struct {
+	Request  Request  `json:"request"`
+	Response Response `json:"response"`
+}
+
+// Request is a recorded HTTP request.
+type Request struct {
+	Method string      `json:"method"`
+	URL    string      `json:"url"`
+	Header http.Header `json:"header,omitempty"`
+	Body   string      `json:"body,omitempty"`
+}
+
+// Response is a recorded HTTP response.
+type Response struct {
+	StatusCode int         `json:"status_code"`
+	Header     http.Header `json:"header,omitempty"`
+	Body       string      `json:"body,omitempty"`
+}
+
+// Cassette is a list of recorded interactions, saved as a JSON file.
+type Cassette struct {
+	Interactions []*Interaction `json:"interactions"`
+}
+
+const redacted = "REDACTED"
+
+var (
+	// secretHeaders are headers scrubbed from cassettes.
+	secretHeaders = []string{
+		"Authorization", "X-Api-Key", "X-Goog-Api-Key", "Api-Key",
+		"Cookie", "Set-Cookie", "Openai-Organization", "Openai-Project",
+	}
+
+	// secretParams are URL query parameters scrubbed from cassettes.
+	secretParams = []string{"key", "api_key", "access_token", "token"}
+)
+
+// ErrNoInteraction is returned in ModeReplay when a request doesn't match any
+// unused interaction of the cassette.
+var ErrNoInteraction = errors.New("xaitest: no matching interaction in cassette")
+
+// -----------------------------------------------------------------------------
+
+// Recorder is an http.RoundTripper that records requests and their responses to
+// a cassette, or replays them from it. Give it to services by `Client` and
+// `xai.WithHTTPClient`, or to geno-based clients by `geno.NewClient`.
+//
+// In ModeReplay, a request matches an interaction if they have the same method,
+// URL and body (compared as JSON if possible), ignoring the secrets scrubbed.
+// Each interaction is served at most once.
+type Recorder struct {
+	// Base is the transport to send requests in ModeRecord. If nil,
+	// http.DefaultTransport is used.
+	Base http.RoundTripper
+
+	// Scrub, if not nil, is called to scrub more data from an interaction
+	// before it is saved, in addition to the well-known secret headers and
+	// URL parameters. In ModeReplay, it is also called on the requests before
+	// they are matched, with an empty Response, so that they match the scrubbed
+	// interactions.
+	Scrub func(*Interaction)
+
+	mode Mode
+	path string
+
+	mu       sync.Mutex
+	cassette Cassette
+	used     []bool
+}
+
+// NewRecorder creates a Recorder of the cassette file at path. In ModeReplay,
+// it loads the cassette from path.
+func NewRecorder(path string, mode Mode) (*Recorder, error) {
+	ret := &Recorder{mode: mode, path: path}
+	if mode == ModeReplay {
+		b, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		if err = json.Unmarshal(b, &ret.cassette); err != nil {
+			return nil, fmt.Errorf("xaitest: invalid cassette %s: %w", path, err)
+		}
+		ret.used = make([]bool, len(ret.cassette.Interactions))
+	}
+	return ret, nil
+}
+
+// Mode returns the mode of the recorder.
+func (p *Recorder) Mode() Mode {
+	return p.mode
+}
+
+// Client returns an http.Client that uses the recorder as its transport.
+func (p *Recorder) Client() *http.Client {
+	return &http.Client{Transport: p}
+}
+
+func (p *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		b, err := io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		body = b
+	}
+	in := Request{Method: req.Method, URL: req.URL.String(), Header: req.Header.Clone(), Body: string(body)}
+	if p.mode == ModeReplay {
+		return p.replay(req, &in)
+	}
+
+	base := p.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	req = req.Clone(req.Context())
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	resp, err := base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	b, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(b))
+
+	it := &Interaction{
+		Request:  in,
+		Response: Response{StatusCode: resp.StatusCode, Header: resp.Header.Clone(), Body: string(b)},
+	}
+	scrub(it)
+	if p.Scrub != nil {
+		p.Scrub(it)
+	}
+	p.mu.Lock()
+	p.cassette.Interactions = append(p.cassette.Interactions, it)
+	p.mu.Unlock()
+	return resp, nil
+}
+
+func (p *Recorder) replay(req *http.Request, in *Request) (*http.Response, error) {
+	scrubbed := &Interaction{Request: *in}
+	scrub(scrubbed)
+	if p.Scrub != nil {
+		p.Scrub(scrubbed)
+	}
+	in = &scrubbed.Request
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for i, it := range p.cassette.Interactions {
+		if !p.used[i] && matchRequest(&it.Request, in) {
+			p.used[i] = true
+			out := &it.Response
+			return &http.Response{
+				Status:        fmt.Sprintf("%d %s", out.StatusCode, http.StatusText(out.StatusCode)),
+				StatusCode:    out.StatusCode,
+				Proto:         "HTTP/1.1",
+				ProtoMajor:    1,
+				ProtoMinor:    1,
+				Header:        out.Header.Clone(),
+				Body:          io.NopCloser(strings.NewReader(out.Body)),
+				ContentLength: int64(len(out.Body)),
+				Request:       req,
+			}, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %s %s", ErrNoInteraction, in.Method, in.URL)
+}
+
+// Close saves the cassette in ModeRecord. In ModeReplay, it returns an error if
+// some interactions of the cassette are not used.
+func (p *Recorder) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.mode == ModeReplay {
+		for i, used := range p.used {
+			if !used {
+				it := p.cassette.Interactions[i]
+				return fmt.Errorf("xaitest: interaction %d (%s %s) of %s is not used", i, it.Request.Method, it.Request.URL, p.path)
+			}
+		}
+		return nil
+	}
+	b, err := json.MarshalIndent(&p.cassette, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err = os.MkdirAll(filepath.Dir(p.path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(p.path, b, 0644)
+}
+
+func scrub(it *Interaction) {
+	for _, h := range secretHeaders {
+		for _, hdr := range []http.Header{it.Request.Header, it.Response.Header} {
+			if hdr.Get(h) != "" {
+				hdr.Set(h, redacted)
+			}
+		}
+	}
+	it.Request.URL = scrubURL(it.Request.URL)
+}
+
+func scrubURL(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.RawQuery == "" {
+		return rawURL
+	}
+	q := u.Query()
+	for _, name := range secretParams {
+		if q.Has(name) {
+			q.Set(name, redacted)
+		}
+	}
+	u.RawQuery = q.Encode()
+	return u.String()
+}
+
+func matchRequest(recorded, req *Request) bool {
+	if recorded.Method != req.Method || recorded.URL != req.URL {
+		return false
+	}
+	if recorded.Body == req.Body {
+		return true
+	}
+	var v1, v2 any
+	if json.Unmarshal([]byte(recorded.Body), &v1) != nil || json.Unmarshal([]byte(req.Body), &v2) != nil {
+		return false
+	}
+	b1, _ := json.Marshal(v1)
+	b2, _ := json.Marshal(v2)
+	return bytes.Equal(b1, b2)
+}
+
+// -----------------------------------------------------------------------------
+
+// RecordEnv is the environment variable that switches UseCassette to ModeRecord
+// if it is set to a non-empty value.
+const RecordEnv = "XAITEST_RECORD"
+
+// UseCassette returns a context that makes `xai.New` create services whose
+// requests are replayed from the cassette testdata/cassettes/<name>.json, or
+// recorded to it if the environment variable XAITEST_RECORD is set. The cassette
+// is closed when the test ends, failing the test if Close fails.
+func UseCassette(t testing.TB, ctx context.Context, name string) context.Context {
+	t.Helper()
+	mode := ModeReplay
+	if os.Getenv(RecordEnv) != "" {
+		mode = ModeRecord
+	}
+	rec, err := NewRecorder(filepath.Join("testdata", "cassettes", name+".json"), mode)
+	if err != nil {
+		t.Fatal("UseCassette:", err)
+	}
+	t.Cleanup(func() {
+		if err := rec.Close(); err != nil {
+			t.Error("UseCassette:", err)
+		}
+	})
+	return xai.WithHTTPClient(ctx, rec.Client())
+}
+
+// -----------------------------------------------------------------------------
@@ -0,0 +1,124 @@
+/*
+ * Copyright (c) 2026 The XGo Authors (xgo.dev). All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package xaitest
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// -----------------------------------------------------------------------------
+
+func TestRecorder(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		w.Header().Set("Set-Cookie", "session=secret")
+		w.Write([]byte(r.URL.Path + ":" + string(body)))
+	}))
+	path := filepath.Join(t.TempDir(), "cassette.json")
+	send := func(rec *Recorder, path, body string) (string, error) {
+		req, _ := http.NewRequest(http.MethodPost, ts.URL+path+"?key=secret", strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer secret")
+		resp, err := rec.Client().Do(req)
+		if err != nil {
+			return "", err
+		}
+		defer resp.Body.Close()
+		b, err := io.ReadAll(resp.Body)
+		return string(b), err
+	}
+
+	rec, err := NewRecorder(path, ModeRecord)
+	if err != nil {
+		t.Fatal("NewRecorder:", err)
+	}
+	for _, path := range []string{"/a", "/b"} {
+		if _, err = send(rec, path, `{"x":1,"y":2}`); err != nil {
+			t.Fatal("send:", err)
+		}
+	}
+	if err = rec.Close(); err != nil {
+		t.Fatal("Close:", err)
+	}
+	ts.Close()
+	if b, _ := os.ReadFile(path); strings.Contains(string(b), "secret") {
+		t.Fatal("secrets are not scrubbed:", string(b))
+	}
+
+	rec, err = NewRecorder(path, ModeReplay)
+	if err != nil {
+		t.Fatal("NewRecorder:", err)
+	}
+	if got, err := send(rec, "/b", `{"y":2, "x":1}`); err != nil || got != `/b:{"x":1,"y":2}` {
+		t.Fatal("replay:", got, err)
+	}
+	if _, err = send(rec, "/b", `{"x":1,"y":2}`); !errors.Is(err, ErrNoInteraction) {
+		t.Fatal("replay: expected ErrNoInteraction, got", err)
+	}
+	if _, err = send(rec, "/a", `{"x":2}`); !errors.Is(err, ErrNoInteraction) {
+		t.Fatal("replay: expected ErrNoInteraction, got", err)
+	}
+	if err = rec.Close(); err == nil {
+		t.Fatal("Close: expected error of unused interaction")
+	}
+}
+
+func TestRecorderScrub(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	}))
+	defer ts.Close()
+	path := filepath.Join(t.TempDir(), "cassette.json")
+	// the user ID in the body is scrubbed
+	scrubUser := func(it *Interaction) {
+		it.Request.Body = strings.ReplaceAll(it.Request.Body, "alice", "<user>")
+		it.Request.Header.Del("X-User")
+	}
+	send := func(rec *Recorder) error {
+		req, _ := http.NewRequest(http.MethodPost, ts.URL, strings.NewReader(`{"user":"alice"}`))
+		req.Header.Set("X-User", "alice")
+		resp, err := rec.Client().Do(req)
+		if err == nil {
+			resp.Body.Close()
+		}
+		return err
+	}
+
+	for _, mode := range []Mode{ModeRecord, ModeReplay} {
+		rec, err := NewRecorder(path, mode)
+		if err != nil {
+			t.Fatal("NewRecorder:", err)
+		}
+		rec.Scrub = scrubUser
+		if err = send(rec); err != nil {
+			t.Fatal("send:", mode, err)
+		}
+		if err = rec.Close(); err != nil {
+			t.Fatal("Close:", mode, err)
+		}
+		if b, _ := os.ReadFile(path); strings.Contains(string(b), "alice") {
+			t.Fatal("user is not scrubbed:", string(b))
+		}
+	}
+}
+
+// -----------------------------------------------------------------------------
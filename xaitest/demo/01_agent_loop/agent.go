@@ -8,10 +8,10 @@ import (
 	"io/fs"
 	"path/filepath"
 	"strings"
-	"sync"
 	"unicode/utf8"
 
 	"github.com/goplus/xai"
+	"github.com/goplus/xai/agent"
 )
 
 // ---------------------------------------------------------------------------
@@ -69,19 +69,6 @@ func buildSystemPrompt(targetDir, outputDir string) string {
 // ---------------------------------------------------------------------------
 
 func runAgent(ctx context.Context, cfg AgentConfig) error {
-	if cfg.MaxTurns <= 0 {
-		cfg.MaxTurns = 30
-	}
-	if cfg.MaxOutputTokens <= 0 {
-		cfg.MaxOutputTokens = 8192
-	}
-
-	toolRefs := make([]xai.ToolBase, 0, len(cfg.Tools))
-	for _, t := range cfg.Tools {
-		toolRefs = append(toolRefs, cfg.Service.Tool(t.Name()))
-	}
-	toolIndex := buildToolIndex(cfg.Tools)
-
 	// Always use the absolute output path in the system prompt.
 	// Computing a relative path from TargetDir to OutputDir is unreliable when
 	// they live on completely different directory trees (e.g. separate repos).
@@ -90,91 +77,47 @@ func runAgent(ctx context.Context, cfg AgentConfig) error {
 		absOutput = cfg.OutputDir
 	}
 
-	sysPrompt := buildSystemPrompt(cfg.TargetDir, absOutput)
-
 	// The Task is the complete user instruction — filenames, content structure, everything.
-	history := []xai.MsgBuilder{cfg.Service.UserMsg().Text(cfg.Task)}
-
-	for turn := 0; turn < cfg.MaxTurns; turn++ {
-		fmt.Printf("\n-- Turn %d/%d --\n", turn+1, cfg.MaxTurns)
-
-		resp, err := cfg.Service.Gen(ctx,
-			cfg.Service.Params().
-				Model(xai.Model(cfg.Model)).
-				MaxOutputTokens(cfg.MaxOutputTokens).
-				System(cfg.Service.Texts(sysPrompt)).
-				Messages(history...).
-				Tools(toolRefs...),
-			cfg.Service.Options(),
-		)
-		if err != nil {
-			return fmt.Errorf("gen: %w", err)
-		}
-		if resp.Len() == 0 {
-			return fmt.Errorf("gen: empty response")
-		}
-
-		printTextParts(resp)
-		history = append(history, resp.At(0).ToMsg())
-
-		toolCalls := extractAllToolUses(resp)
+	_, err = agent.Run(ctx, agent.Config{
+		Service:         cfg.Service,
+		Model:           xai.Model(cfg.Model),
+		System:          buildSystemPrompt(cfg.TargetDir, absOutput),
+		Tools:           cfg.Tools,
+		MaxTurns:        cfg.MaxTurns,
+		MaxOutputTokens: cfg.MaxOutputTokens,
 
 		// No tool calls → LLM thinks it's done; check for actual output.
-		if len(toolCalls) == 0 {
+		Stop: agent.Until(func(ctx context.Context) bool {
 			has, err := hasOutputFiles(absOutput)
 			if err != nil {
 				fmt.Printf("[Agent] Warning: could not check output dir: %v\n", err)
 			}
 			if has {
 				fmt.Println("\n[Agent] Deliverables produced. Finishing.")
-				break
 			}
-			// No output yet — nudge the agent to keep working.
-			history = append(history, cfg.Service.UserMsg().Text(
-				"You haven't produced any deliverables yet. Please continue working.",
-			))
-			continue
-		}
-
-		// Execute tool calls concurrently.
-		type result struct {
-			id, name string
-			out      string
-			isError  bool
-		}
-		results := make([]result, len(toolCalls))
-		var wg sync.WaitGroup
-		for i, call := range toolCalls {
-			wg.Add(1)
-			go func(idx int, c xai.ToolUse) {
-				defer wg.Done()
-				fmt.Printf("\n[Tool] %s(%s)\n", c.Name, shortJSON(c.Input))
-
-				out, err := dispatchTool(ctx, toolIndex, c)
-				if err != nil {
-					fmt.Printf("[Result] ERROR: %s\n", err)
-					results[idx] = result{id: c.ID, name: c.Name, out: err.Error(), isError: true}
+			return has
+		}, "You haven't produced any deliverables yet. Please continue working."),
+
+		Hooks: agent.Hooks{
+			OnTurn: func(ctx context.Context, turn *agent.Turn) {
+				fmt.Printf("\n-- Turn %d --\n", turn.Index+1)
+				printTextParts(turn.Response)
+			},
+			BeforeToolCall: func(ctx context.Context, use xai.ToolUse) {
+				fmt.Printf("\n[Tool] %s(%s)\n", use.Name, shortJSON(use.Input))
+			},
+			AfterToolCall: func(ctx context.Context, use xai.ToolUse, result xai.ToolResult) {
+				if result.IsError {
+					fmt.Printf("[Result] ERROR: %s\n", result.Result)
 					return
 				}
-				fmt.Printf("[Result] %s\n", truncate(out, 300))
-				results[idx] = result{id: c.ID, name: c.Name, out: out}
-			}(i, call)
-		}
-		wg.Wait()
-
-		trMsg := cfg.Service.UserMsg()
-		for _, r := range results {
-			val := any(r.out)
-			if r.isError {
-				val = errors.New(r.out)
-			}
-			trMsg = trMsg.ToolResult(xai.ToolResult{
-				ID: r.id, Name: r.name, Result: val, IsError: r.isError,
-			})
-		}
-		history = append(history, trMsg)
+				fmt.Printf("[Result] %s\n", truncate(result.Result.(string), 300))
+			},
+		},
+	}, cfg.Task)
+	if err != nil && !errors.Is(err, agent.ErrMaxTurns) {
+		return err
 	}
-
 	return reportResults(absOutput)
 }
 
@@ -251,20 +194,6 @@ func reportResults(outputDir string) error {
 	return nil
 }
 
-func extractAllToolUses(resp xai.GenResponse) []xai.ToolUse {
-	if resp.Len() == 0 {
-		return nil
-	}
-	candidate := resp.At(0)
-	var uses []xai.ToolUse
-	for i := 0; i < candidate.Parts(); i++ {
-		if toolUse, ok := candidate.Part(i).AsToolUse(); ok {
-			uses = append(uses, toolUse)
-		}
-	}
-	return uses
-}
-
 func printTextParts(resp xai.GenResponse) {
 	if resp.Len() == 0 {
 		return
@@ -279,18 +208,6 @@ func printTextParts(resp xai.GenResponse) {
 	}
 }
 
-func dispatchTool(ctx context.Context, index map[string]LocalTool, toolUse xai.ToolUse) (string, error) {
-	tool, ok := index[toolUse.Name]
-	if !ok {
-		return "", fmt.Errorf("unknown tool: %s", toolUse.Name)
-	}
-	input, err := json.Marshal(toolUse.Input)
-	if err != nil {
-		return "", fmt.Errorf("marshal tool input: %w", err)
-	}
-	return tool.Execute(ctx, input)
-}
-
 func shortJSON(value any) string {
 	b, err := json.Marshal(value)
 	if err != nil {
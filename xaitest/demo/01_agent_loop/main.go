@@ -64,7 +64,6 @@ func main() {
 	}
 
 	tools := buildTools(absTarget, absOutput)
-	registerTools(provider, absTarget, tools)
 
 	if err := runAgent(ctx, AgentConfig{
 		Service:   provider,
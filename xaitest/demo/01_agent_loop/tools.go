@@ -14,15 +14,11 @@ import (
 	"sort"
 	"strings"
 
-	"github.com/goplus/xai"
+	"github.com/goplus/xai/agent"
 )
 
-type LocalTool interface {
-	Name() string
-	Description() string
-	InputSchema() string
-	Execute(ctx context.Context, input json.RawMessage) (string, error)
-}
+// LocalTool is a tool executed locally by the agent.
+type LocalTool = agent.Tool
 
 func buildTools(root, outputDir string) []LocalTool {
 	return []LocalTool{
@@ -33,31 +29,6 @@ func buildTools(root, outputDir string) []LocalTool {
 	}
 }
 
-// toolWithInputSchema is an optional capability that provider tools may support.
-// When present, the JSON schema is set as a structured parameter definition
-// instead of being embedded in the description text.
-type toolWithInputSchema interface {
-	InputSchema(string) xai.Tool
-}
-
-func registerTools(provider xai.Service, root string, tools []LocalTool) {
-	for _, tool := range tools {
-		t := provider.ToolDef(tool.Name())
-		t.Description(tool.Description())
-		if ts, ok := t.(toolWithInputSchema); ok {
-			ts.InputSchema(tool.InputSchema())
-		}
-	}
-}
-
-func buildToolIndex(tools []LocalTool) map[string]LocalTool {
-	index := make(map[string]LocalTool, len(tools))
-	for _, tool := range tools {
-		index[tool.Name()] = tool
-	}
-	return index
-}
-
 // isBinary reports whether the file at path appears to be a binary file.
 // It reads up to the first 512 bytes and checks for NUL bytes.
 func isBinary(path string) bool {
@@ -0,0 +1,484 @@
+/*
+ * Copyright (c) 2026 The XGo Authors (xgo.dev). All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package xaitest
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// -----------------------------------------------------------------------------
+
+// The fake servers of this package decode requests of their wire formats into a
+// fakeRequest, and encode the fakeReply of the fake model back. The fake model
+// replies deterministically by the rules described in Do, so that the suite can
+// check the round trips.
+
+type fakeKind int
+
+const (
+	fakeText fakeKind = iota
+	fakeImage
+	fakeDoc
+	fakeThinking
+	fakeToolUse
+	fakeToolResult
+	fakeSearch // web search done by the server, which precedes its text reply
+)
+
+type fakePart struct {
+	kind fakeKind
+	text string // text, thinking, tool result or search query; MIME of images and docs
+	sig  string // signature of thinking
+
+	// ID and name of tool uses and tool results, input of tool uses, and ID of
+	// web searches.
+	id, name string
+	input    map[string]any
+
+	file string // ID of the uploaded file of images and docs
+	cite bool   // citations of the doc are enabled
+
+	cites []fakeCite // citations of text replies, or results of web searches
+}
+
+// fakeCite is a citation of the span [start, end) of a text reply, which cites
+// either the doc-th document of the request or a web page.
+type fakeCite struct {
+	start, end int
+	doc        int // -1 for web pages
+	url, title string
+}
+
+type fakeMsg struct {
+	assistant bool
+	parts     []fakePart
+}
+
+type fakeRequest struct {
+	system    string
+	msgs      []fakeMsg
+	tools     []string
+	thinking  bool
+	maxTokens int64
+	webSearch bool // the web search tool is given
+
+	// prefix is the key of the cached prompt prefix, if any, whose messages have
+	// prefixTokens tokens.
+	prefix       string
+	prefixTokens int64
+}
+
+const (
+	stopEndTurn   = "end_turn"
+	stopMaxTokens = "max_tokens"
+	stopToolUse   = "tool_use"
+)
+
+type fakeReply struct {
+	parts        []fakePart
+	stop         string
+	inputTokens  int64 // including the cached tokens
+	outputTokens int64
+	cacheRead    int64
+	cacheWrite   int64
+}
+
+// text returns the text part of the reply.
+func (p *fakeReply) text() string {
+	for _, part := range p.parts {
+		if part.kind == fakeText {
+			return part.text
+		}
+	}
+	return ""
+}
+
+// fakeFile is a file uploaded to a fake server.
+type fakeFile struct {
+	id, name, mime string
+	size           int64
+	created        time.Time
+	expires        time.Time // zero if the file doesn't expire
+	purpose        string    // purpose of OpenAI files
+	data           []byte    // content of batch input and output files
+}
+
+// fakeBatch is a batch created on a fake server. Batches end once created, and
+// keep the results of their requests in the wire format of the server.
+type fakeBatch struct {
+	id      string
+	created time.Time
+	results []any
+	failed  int      // number of failed requests
+	files   []string // IDs of the files of the results, if any
+}
+
+type fakeModel struct {
+	mu      sync.Mutex
+	n       int
+	sigs    map[string]bool
+	files   []*fakeFile
+	batches []*fakeBatch
+	prompts map[string]bool // keys of the cached prompt prefixes
+}
+
+func newFakeModel() *fakeModel {
+	return &fakeModel{sigs: make(map[string]bool), prompts: make(map[string]bool)}
+}
+
+// upload adds a file, whose ID consists of lowercase letters and digits as
+// required by the Gemini API.
+func (p *fakeModel) upload(name, mime string, size int64, ttl time.Duration) *fakeFile {
+	return p.addFile(&fakeFile{name: name, mime: mime, size: size}, ttl)
+}
+
+// addFile adds the file f with a new ID, which expires after ttl if ttl > 0.
+func (p *fakeModel) addFile(f *fakeFile, ttl time.Duration) *fakeFile {
+	f.id, f.created = p.next("file"), time.Now().Truncate(time.Second)
+	if ttl > 0 {
+		f.expires = f.created.Add(ttl)
+	}
+	p.mu.Lock()
+	p.files = append(p.files, f)
+	p.mu.Unlock()
+	return f
+}
+
+func (p *fakeModel) file(id string) (*fakeFile, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, f := range p.files {
+		if f.id == id {
+			return f, true
+		}
+	}
+	return nil, false
+}
+
+func (p *fakeModel) listFiles() []*fakeFile {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return slices.Clone(p.files)
+}
+
+func (p *fakeModel) deleteFile(id string) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	n := len(p.files)
+	p.files = slices.DeleteFunc(p.files, func(f *fakeFile) bool { return f.id == id })
+	return len(p.files) < n
+}
+
+// addBatch adds the batch b, whose ID has the given prefix.
+func (p *fakeModel) addBatch(prefix string, b *fakeBatch) *fakeBatch {
+	b.id, b.created = p.next(prefix), time.Now().Truncate(time.Second)
+	p.mu.Lock()
+	p.batches = append(p.batches, b)
+	p.mu.Unlock()
+	return b
+}
+
+func (p *fakeModel) batch(id string) (*fakeBatch, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, b := range p.batches {
+		if b.id == id {
+			return b, true
+		}
+	}
+	return nil, false
+}
+
+// next returns a new ID with the given prefix.
+func (p *fakeModel) next(prefix string) string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.n++
+	return prefix + strconv.Itoa(p.n)
+}
+
+func (p *fakeModel) sign() string {
+	sig := p.next("sig-")
+	p.mu.Lock()
+	p.sigs[sig] = true
+	p.mu.Unlock()
+	return sig
+}
+
+func (p *fakeModel) signed(sig string) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.sigs[sig]
+}
+
+// cachePrompt caches the prompt prefix of the given key, and reports whether it
+// was cached already.
+func (p *fakeModel) cachePrompt(key string) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	hit := p.prompts[key]
+	p.prompts[key] = true
+	return hit
+}
+
+func (p *fakeModel) reply(req *fakeRequest) (*fakeReply, error) {
+	if len(req.msgs) == 0 || req.msgs[len(req.msgs)-1].assistant {
+		return nil, errors.New("the last message must be a user message")
+	}
+	ret := &fakeReply{inputTokens: req.inputTokens()}
+	if req.prefix != "" {
+		if p.cachePrompt(req.prefix) {
+			ret.cacheRead = req.prefixTokens
+		} else {
+			ret.cacheWrite = req.prefixTokens
+		}
+	}
+	turn, thoughts, docs := 1, 0, 0
+	uses := make(map[string]bool)
+	var cited []int // indices of the cited documents of the last message
+	for j, msg := range req.msgs {
+		if msg.assistant {
+			turn++
+		}
+		for i, part := range msg.parts {
+			if part.kind == fakeDoc {
+				if part.cite && j == len(req.msgs)-1 {
+					cited = append(cited, docs)
+				}
+				docs++
+			}
+			if part.file != "" {
+				f, ok := p.file(part.file)
+				if !ok {
+					return nil, fmt.Errorf("unknown file %q", part.file)
+				}
+				msg.parts[i].text = f.mime
+			}
+			switch part.kind {
+			case fakeThinking:
+				if !p.signed(part.sig) {
+					return nil, fmt.Errorf("invalid thinking signature %q", part.sig)
+				}
+				thoughts++
+			case fakeToolUse:
+				uses["id:"+part.id], uses["name:"+part.name] = true, true
+			case fakeToolResult:
+				if part.id != "" && !uses["id:"+part.id] || part.id == "" && !uses["name:"+part.name] {
+					return nil, fmt.Errorf("tool result of unknown tool use %q", part.id+part.name)
+				}
+			}
+		}
+	}
+
+	var texts, results []string
+	var attachments strings.Builder
+	for _, part := range req.msgs[len(req.msgs)-1].parts {
+		switch part.kind {
+		case fakeText:
+			texts = append(texts, part.text)
+		case fakeImage:
+			fmt.Fprintf(&attachments, " [image %s]", part.text)
+		case fakeDoc:
+			fmt.Fprintf(&attachments, " [document %s]", part.text)
+		case fakeToolResult:
+			results = append(results, compactJSON(part.text))
+		}
+	}
+	prompt := strings.Join(texts, " ")
+	if req.thinking {
+		ret.parts = append(ret.parts, fakePart{kind: fakeThinking, text: "thinking about: " + prompt, sig: p.sign()})
+	}
+	ret.stop = stopEndTurn
+	var text string
+	var cites []fakeCite
+	switch {
+	case len(results) > 0:
+		text = "tool result: " + strings.Join(results, ", ")
+	case req.webSearch && strings.HasPrefix(prompt, "search "):
+		query := strings.TrimPrefix(prompt, "search ")
+		page := fakeCite{doc: -1, url: "https://example.com/" + url.PathEscape(query), title: query}
+		ret.parts = append(ret.parts, fakePart{kind: fakeSearch, id: p.next("search_"), text: query, cites: []fakeCite{page}})
+		text = "found: " + query
+		page.start, page.end = len(text)-len(query), len(text)
+		cites = append(cites, page)
+	case len(req.tools) > 0 && strings.HasPrefix(prompt, "call "):
+		ret.parts = append(ret.parts, fakePart{
+			kind: fakeToolUse, id: p.next("call_"), name: req.tools[0],
+			input: map[string]any{"text": strings.TrimPrefix(prompt, "call ")},
+		})
+		ret.stop = stopToolUse
+	default:
+		text = "echo: " + prompt + attachments.String()
+		for _, doc := range cited {
+			cites = append(cites, fakeCite{end: len(text), doc: doc})
+		}
+	}
+	if text != "" {
+		if req.system != "" {
+			text += " [system: " + req.system + "]"
+		}
+		if turn > 1 {
+			text += fmt.Sprintf(" (turn %d)", turn)
+		}
+		if thoughts > 0 {
+			text += fmt.Sprintf(" (thoughts %d)", thoughts)
+		}
+		if words := strings.Fields(text); req.maxTokens > 0 && int64(len(words)) > req.maxTokens {
+			text = strings.Join(words[:req.maxTokens], " ")
+			ret.stop = stopMaxTokens
+			cites = nil
+		}
+		ret.parts = append(ret.parts, fakePart{kind: fakeText, text: text, cites: cites})
+	}
+	for _, part := range ret.parts {
+		ret.outputTokens += countTokens(part)
+	}
+	return ret, nil
+}
+
+// usesFiles reports whether the request references uploaded files.
+func (p *fakeRequest) usesFiles() bool {
+	for _, msg := range p.msgs {
+		for _, part := range msg.parts {
+			if part.file != "" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// cache sets the cached prompt prefix, which consists of the system prompt, the
+// tools, the messages before the i-th one and the first n parts of it.
+func (p *fakeRequest) cache(i, n int) {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%q %q", p.system, p.tools)
+	p.prefixTokens = 0
+	for j, msg := range p.msgs[:i+1] {
+		parts := msg.parts
+		if j == i {
+			parts = parts[:n]
+		}
+		fmt.Fprintf(&b, " %v:%v", msg.assistant, parts)
+		for _, part := range parts {
+			p.prefixTokens += countTokens(part)
+		}
+	}
+	p.prefix = b.String()
+}
+
+// inputTokens counts the tokens of the messages, as reported by the usage of the
+// replies and the token counting endpoints.
+func (p *fakeRequest) inputTokens() (n int64) {
+	for _, msg := range p.msgs {
+		for _, part := range msg.parts {
+			n += countTokens(part)
+		}
+	}
+	return
+}
+
+// countTokens counts the words of text parts, and a token for other parts.
+func countTokens(part fakePart) int64 {
+	if part.kind == fakeText || part.kind == fakeThinking {
+		return int64(len(strings.Fields(part.text)))
+	}
+	return 1
+}
+
+// embedding returns the fake embedding of an input of n bytes, whose i-th value
+// is n+i. dims defaults to 3.
+func embedding(n int, dims int64) []float32 {
+	if dims <= 0 {
+		dims = 3
+	}
+	ret := make([]float32, dims)
+	for i := range ret {
+		ret[i] = float32(n + i)
+	}
+	return ret
+}
+
+func compactJSON(s string) string {
+	var b bytes.Buffer
+	if json.Compact(&b, []byte(s)) == nil {
+		return b.String()
+	}
+	return s
+}
+
+// chunks splits s into the chunks streamed, i.e. its words with the trailing
+// spaces.
+func chunks(s string) []string {
+	return strings.SplitAfter(s, " ")
+}
+
+// -----------------------------------------------------------------------------
+
+// formFile returns the filename, MIME type and content of the file field of the
+// multipart form of r.
+func formFile(r *http.Request, field string) (name, mime string, data []byte, err error) {
+	f, h, err := r.FormFile(field)
+	if err != nil {
+		return
+	}
+	defer f.Close()
+	data, err = io.ReadAll(f)
+	return h.Filename, h.Header.Get("Content-Type"), data, err
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+type sseWriter struct {
+	w http.ResponseWriter
+}
+
+func newSSEWriter(w http.ResponseWriter) sseWriter {
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	return sseWriter{w}
+}
+
+// send sends an event of data. The event line is omitted if event is empty.
+func (p sseWriter) send(event string, data any) {
+	b, _ := json.Marshal(data)
+	if event != "" {
+		fmt.Fprintf(p.w, "event: %s\n", event)
+	}
+	fmt.Fprintf(p.w, "data: %s\n\n", b)
+	if f, ok := p.w.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// -----------------------------------------------------------------------------
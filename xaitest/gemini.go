@@ -0,0 +1,622 @@
+/*
+ * Copyright (c) 2026 The XGo Authors (xgo.dev). All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package xaitest
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// -----------------------------------------------------------------------------
+
+type geminiPart struct {
+	Text             string `json:"text,omitempty"`
+	Thought          bool   `json:"thought,omitempty"`
+	ThoughtSignature []byte `json:"thoughtSignature,omitempty"`
+	InlineData       *struct {
+		MIMEType string `json:"mimeType"`
+		Data     []byte `json:"data"`
+	} `json:"inlineData,omitempty"`
+	FileData *struct {
+		MIMEType string `json:"mimeType"`
+		FileURI  string `json:"fileUri"`
+	} `json:"fileData,omitempty"`
+	FunctionCall     *geminiFunction `json:"functionCall,omitempty"`
+	FunctionResponse *geminiFunction `json:"functionResponse,omitempty"`
+}
+
+type geminiFunction struct {
+	ID       string         `json:"id,omitempty"`
+	Name     string         `json:"name"`
+	Args     map[string]any `json:"args,omitempty"`
+	Response map[string]any `json:"response,omitempty"`
+}
+
+type geminiContent struct {
+	Role  string        `json:"role,omitempty"`
+	Parts []*geminiPart `json:"parts"`
+}
+
+type geminiRequest struct {
+	Contents          []*geminiContent `json:"contents"`
+	SystemInstruction *geminiContent   `json:"systemInstruction"`
+	Tools             []struct {
+		FunctionDeclarations []struct {
+			Name string `json:"name"`
+		} `json:"functionDeclarations"`
+		GoogleSearch *struct{} `json:"googleSearch"`
+	} `json:"tools"`
+	GenerationConfig struct {
+		MaxOutputTokens int64 `json:"maxOutputTokens"`
+		ThinkingConfig  struct {
+			IncludeThoughts bool `json:"includeThoughts"`
+		} `json:"thinkingConfig"`
+	} `json:"generationConfig"`
+	CachedContent string `json:"cachedContent"`
+}
+
+func (p *geminiRequest) fake() *fakeRequest {
+	ret := &fakeRequest{
+		maxTokens: p.GenerationConfig.MaxOutputTokens,
+		thinking:  p.GenerationConfig.ThinkingConfig.IncludeThoughts,
+	}
+	if sys := p.SystemInstruction; sys != nil {
+		for _, part := range sys.Parts {
+			ret.system += part.Text
+		}
+	}
+	for _, tool := range p.Tools {
+		for _, fn := range tool.FunctionDeclarations {
+			ret.tools = append(ret.tools, fn.Name)
+		}
+		ret.webSearch = ret.webSearch || tool.GoogleSearch != nil
+	}
+	for _, c := range p.Contents {
+		m := fakeMsg{assistant: c.Role == "model"}
+		for _, part := range c.Parts {
+			var fp fakePart
+			switch {
+			case part.FunctionCall != nil:
+				fn := part.FunctionCall
+				fp = fakePart{kind: fakeToolUse, id: fn.ID, name: fn.Name, input: fn.Args}
+			case part.FunctionResponse != nil:
+				fn := part.FunctionResponse
+				b, _ := json.Marshal(fn.Response)
+				fp = fakePart{kind: fakeToolResult, id: fn.ID, name: fn.Name, text: string(b)}
+			case part.InlineData != nil || part.FileData != nil:
+				mime, file := "file", ""
+				if part.InlineData != nil {
+					mime = part.InlineData.MIMEType
+				} else if _, id, ok := strings.Cut(part.FileData.FileURI, "/v1beta/files/"); ok {
+					mime, file = part.FileData.MIMEType, id
+				}
+				fp = fakePart{kind: fakeDoc, text: mime, file: file}
+				if strings.HasPrefix(mime, "image/") {
+					fp.kind = fakeImage
+				}
+			case part.Thought:
+				fp = fakePart{kind: fakeThinking, text: part.Text, sig: string(part.ThoughtSignature)}
+			default:
+				fp = fakePart{kind: fakeText, text: part.Text}
+			}
+			m.parts = append(m.parts, fp)
+		}
+		ret.msgs = append(ret.msgs, m)
+	}
+	return ret
+}
+
+func geminiParts(part fakePart) []*geminiPart {
+	switch part.kind {
+	case fakeThinking:
+		return []*geminiPart{{Text: part.text, Thought: true, ThoughtSignature: []byte(part.sig)}}
+	case fakeToolUse:
+		// gemini doesn't return the IDs of function calls
+		return []*geminiPart{{FunctionCall: &geminiFunction{Name: part.name, Args: part.input}}}
+	case fakeSearch:
+		// web searches of gemini are only in the grounding metadata
+		return nil
+	}
+	return []*geminiPart{{Text: part.text}}
+}
+
+func geminiResponse(model string, parts []*geminiPart, reply *fakeReply) map[string]any {
+	candidate := map[string]any{
+		"content": geminiContent{Role: "model", Parts: parts},
+		"index":   0,
+	}
+	ret := map[string]any{
+		"candidates":   []any{candidate},
+		"modelVersion": model,
+	}
+	if reply != nil {
+		candidate["finishReason"] = "STOP"
+		if reply.stop == stopMaxTokens {
+			candidate["finishReason"] = "MAX_TOKENS"
+		}
+		ret["usageMetadata"] = map[string]any{
+			"promptTokenCount":     reply.inputTokens,
+			"candidatesTokenCount": reply.outputTokens,
+			"totalTokenCount":      reply.inputTokens + reply.outputTokens,
+
+			"cachedContentTokenCount": reply.cacheRead,
+		}
+		if meta := geminiGrounding(reply); meta != nil {
+			candidate["groundingMetadata"] = meta
+		}
+	}
+	return ret
+}
+
+// geminiGrounding returns the grounding metadata of the web citations of reply,
+// or nil if there are none.
+func geminiGrounding(reply *fakeReply) map[string]any {
+	var chunks, supports []map[string]any
+	i := -1 // index of the part in the response
+	for _, part := range reply.parts {
+		if part.kind == fakeSearch {
+			continue
+		}
+		i++
+		for _, c := range part.cites {
+			if c.doc >= 0 {
+				continue // gemini doesn't cite documents
+			}
+			supports = append(supports, map[string]any{
+				"segment": map[string]any{
+					"partIndex": i, "startIndex": c.start, "endIndex": c.end, "text": part.text[c.start:c.end],
+				},
+				"groundingChunkIndices": []int{len(chunks)},
+			})
+			chunks = append(chunks, map[string]any{"web": map[string]any{"uri": c.url, "title": c.title}})
+		}
+	}
+	if len(supports) == 0 {
+		return nil
+	}
+	return map[string]any{"groundingChunks": chunks, "groundingSupports": supports}
+}
+
+func geminiStream(w http.ResponseWriter, model string, reply *fakeReply) {
+	sse := newSSEWriter(w)
+	for _, part := range reply.parts {
+		switch part.kind {
+		case fakeThinking, fakeText:
+			texts := chunks(part.text)
+			for i, s := range texts {
+				p := &geminiPart{Text: s, Thought: part.kind == fakeThinking}
+				if p.Thought && i == len(texts)-1 {
+					p.ThoughtSignature = []byte(part.sig)
+				}
+				sse.send("", geminiResponse(model, []*geminiPart{p}, nil))
+			}
+		case fakeSearch:
+		default:
+			sse.send("", geminiResponse(model, geminiParts(part), nil))
+		}
+	}
+	sse.send("", geminiResponse(model, []*geminiPart{}, reply))
+}
+
+func geminiError(w http.ResponseWriter, status int, msg string) {
+	code := "INVALID_ARGUMENT"
+	switch status {
+	case http.StatusNotFound:
+		code = "NOT_FOUND"
+	case http.StatusUnauthorized:
+		code = "UNAUTHENTICATED"
+	}
+	writeJSON(w, status, map[string]any{
+		"error": map[string]any{"code": status, "message": msg, "status": code},
+	})
+}
+
+// geminiCaches serves the creation and retrieval of cached contents, which hold
+// the prompt prefixes of requests.
+type geminiCaches struct {
+	model *fakeModel
+	mu    sync.Mutex
+	items map[string]*geminiCache // by names
+}
+
+type geminiCache struct {
+	req     geminiRequest // system instruction, tools and contents
+	model   string
+	created time.Time
+	expires time.Time
+}
+
+func (p *geminiCaches) cache(name string, c *geminiCache) map[string]any {
+	return map[string]any{
+		"name": name, "model": c.model,
+		"createTime": c.created.Format(time.RFC3339), "updateTime": c.created.Format(time.RFC3339),
+		"expireTime":    c.expires.Format(time.RFC3339),
+		"usageMetadata": map[string]any{"totalTokenCount": c.req.fake().inputTokens()},
+	}
+}
+
+func (p *geminiCaches) serve(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodPost && r.URL.Path == "/v1beta/cachedContents" {
+		var in struct {
+			geminiRequest
+			Model string `json:"model"`
+			TTL   string `json:"ttl"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&in); err != nil || in.Model == "" {
+			geminiError(w, http.StatusBadRequest, "model is required")
+			return
+		}
+		ttl := time.Hour
+		if in.TTL != "" {
+			d, err := time.ParseDuration(in.TTL)
+			if err != nil {
+				geminiError(w, http.StatusBadRequest, "invalid ttl: "+in.TTL)
+				return
+			}
+			ttl = d
+		}
+		c := &geminiCache{req: in.geminiRequest, model: in.Model, created: time.Now().Truncate(time.Second)}
+		c.expires = c.created.Add(ttl)
+		name := "cachedContents/" + p.model.next("cache")
+		p.mu.Lock()
+		p.items[name] = c
+		p.mu.Unlock()
+		// the requests that use the cached content hit the cache.
+		p.model.cachePrompt(name)
+		writeJSON(w, http.StatusOK, p.cache(name, c))
+		return
+	}
+	name := strings.TrimPrefix(r.URL.Path, "/v1beta/")
+	if c, ok := p.get(name); ok && r.Method == http.MethodGet {
+		writeJSON(w, http.StatusOK, p.cache(name, c))
+		return
+	}
+	geminiError(w, http.StatusNotFound, "cached content not found: "+name)
+}
+
+func (p *geminiCaches) get(name string) (*geminiCache, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	c, ok := p.items[name]
+	if !ok || time.Now().After(c.expires) {
+		return nil, false
+	}
+	return c, true
+}
+
+// fake returns the fake request of in, whose prompt prefix is taken from its
+// cached content, if any.
+func (p *geminiCaches) fake(in *geminiRequest) (*fakeRequest, error) {
+	if in.CachedContent == "" {
+		return in.fake(), nil
+	}
+	if in.SystemInstruction != nil || len(in.Tools) > 0 {
+		return nil, errors.New("systemInstruction and tools must be empty along with cachedContent")
+	}
+	c, ok := p.get(in.CachedContent)
+	if !ok {
+		return nil, errors.New("cached content not found: " + in.CachedContent)
+	}
+	req := c.req
+	req.Contents = append(slices.Clip(req.Contents), in.Contents...)
+	req.GenerationConfig = in.GenerationConfig
+	ret := req.fake()
+	ret.prefix, ret.prefixTokens = in.CachedContent, c.req.fake().inputTokens()
+	return ret, nil
+}
+
+// geminiFiles serves the Files API, including the resumable uploads of files.
+type geminiFiles struct {
+	model   *fakeModel
+	mu      sync.Mutex
+	uploads map[string]*fakeFile // pending uploads by upload IDs
+}
+
+func (p *geminiFiles) file(r *http.Request, f *fakeFile) map[string]any {
+	return map[string]any{
+		"name": "files/" + f.id, "displayName": f.name, "mimeType": f.mime,
+		"sizeBytes": strconv.FormatInt(f.size, 10), "state": "ACTIVE",
+		"createTime":     f.created.Format(time.RFC3339),
+		"expirationTime": f.expires.Format(time.RFC3339),
+		"uri":            "http://" + r.Host + "/v1beta/files/" + f.id,
+	}
+}
+
+func (p *geminiFiles) serve(w http.ResponseWriter, r *http.Request) {
+	id := strings.TrimPrefix(r.URL.Path, "/v1beta/files/")
+	switch {
+	case r.URL.Path == "/upload/v1beta/files":
+		p.upload(w, r)
+	case r.Method == http.MethodGet && r.URL.Path == "/v1beta/files":
+		files := []map[string]any{}
+		for _, f := range p.model.listFiles() {
+			files = append(files, p.file(r, f))
+		}
+		writeJSON(w, http.StatusOK, map[string]any{"files": files})
+	case r.Method == http.MethodGet:
+		if f, ok := p.model.file(id); ok {
+			writeJSON(w, http.StatusOK, p.file(r, f))
+			return
+		}
+		geminiError(w, http.StatusNotFound, "file not found: "+id)
+	case r.Method == http.MethodDelete:
+		if p.model.deleteFile(id) {
+			writeJSON(w, http.StatusOK, map[string]any{})
+			return
+		}
+		geminiError(w, http.StatusNotFound, "file not found: "+id)
+	default:
+		geminiError(w, http.StatusNotFound, "not found: "+r.URL.Path)
+	}
+}
+
+// upload serves the resumable upload protocol: the start command returns the
+// upload URL, to which the content is uploaded in chunks, the last of which is
+// sent with the finalize command.
+func (p *geminiFiles) upload(w http.ResponseWriter, r *http.Request) {
+	cmd := r.Header.Get("X-Goog-Upload-Command")
+	if cmd == "start" {
+		var in struct {
+			File struct {
+				MIMEType    string `json:"mimeType"`
+				DisplayName string `json:"displayName"`
+			} `json:"file"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+			geminiError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		uploadID := p.model.next("upload")
+		p.mu.Lock()
+		p.uploads[uploadID] = &fakeFile{name: in.File.DisplayName, mime: in.File.MIMEType}
+		p.mu.Unlock()
+		w.Header().Set("X-Goog-Upload-Url", "http://"+r.Host+"/upload/v1beta/files?upload_id="+uploadID)
+		writeJSON(w, http.StatusOK, map[string]any{})
+		return
+	}
+	uploadID := r.URL.Query().Get("upload_id")
+	p.mu.Lock()
+	f, ok := p.uploads[uploadID]
+	p.mu.Unlock()
+	if !ok || !strings.HasPrefix(cmd, "upload") {
+		geminiError(w, http.StatusBadRequest, "invalid upload")
+		return
+	}
+	n, _ := io.Copy(io.Discard, r.Body)
+	f.size += n
+	if !strings.Contains(cmd, "finalize") {
+		w.Header().Set("X-Goog-Upload-Status", "active")
+		writeJSON(w, http.StatusOK, map[string]any{})
+		return
+	}
+	p.mu.Lock()
+	delete(p.uploads, uploadID)
+	p.mu.Unlock()
+	// files of the Gemini API expire after 48 hours.
+	f = p.model.upload(f.name, f.mime, f.size, 48*time.Hour)
+	w.Header().Set("X-Goog-Upload-Status", "final")
+	writeJSON(w, http.StatusOK, map[string]any{"file": p.file(r, f)})
+}
+
+// geminiBatch returns the operation of the batch b, which ends once created.
+func geminiBatch(b *fakeBatch) map[string]any {
+	created := b.created.Format(time.RFC3339)
+	return map[string]any{
+		"name": "batches/" + b.id,
+		"done": true,
+		"metadata": map[string]any{
+			"@type": "type.googleapis.com/google.ai.generativelanguage.v1main.GenerateContentBatch",
+			"state": "BATCH_STATE_SUCCEEDED", "createTime": created, "endTime": created, "updateTime": created,
+			"output": map[string]any{"inlinedResponses": map[string]any{"inlinedResponses": b.results}},
+		},
+	}
+}
+
+// geminiBatches serves the batchGenerateContent method of model, whose requests
+// are inlined.
+func geminiBatches(w http.ResponseWriter, r *http.Request, model *fakeModel, name string) {
+	var in struct {
+		Batch struct {
+			InputConfig struct {
+				Requests struct {
+					Requests []struct {
+						Request  geminiRequest     `json:"request"`
+						Metadata map[string]string `json:"metadata"`
+					} `json:"requests"`
+				} `json:"requests"`
+			} `json:"inputConfig"`
+		} `json:"batch"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&in); err != nil || len(in.Batch.InputConfig.Requests.Requests) == 0 {
+		geminiError(w, http.StatusBadRequest, "inlined requests are required")
+		return
+	}
+	b := new(fakeBatch)
+	for _, req := range in.Batch.InputConfig.Requests.Requests {
+		result := map[string]any{"metadata": req.Metadata}
+		if reply, err := model.reply(req.Request.fake()); err != nil {
+			result["error"] = map[string]any{"code": 3, "message": err.Error()} // INVALID_ARGUMENT
+			b.failed++
+		} else {
+			var parts []*geminiPart
+			for _, part := range reply.parts {
+				parts = append(parts, geminiParts(part)...)
+			}
+			result["response"] = geminiResponse(name, parts, reply)
+		}
+		b.results = append(b.results, result)
+	}
+	writeJSON(w, http.StatusOK, geminiBatch(model.addBatch("batch", b)))
+}
+
+// geminiModels are the models listed by the fake models.list method.
+var geminiModels = []map[string]any{
+	{
+		"name": "models/gemini-2.5-flash", "displayName": "Gemini 2.5 Flash",
+		"inputTokenLimit": 1048576, "outputTokenLimit": 65536, "thinking": true,
+		"supportedActions": []string{"generateContent", "countTokens"},
+	},
+	{
+		"name": "models/imagen-4.0-generate-001", "displayName": "Imagen 4",
+		"inputTokenLimit": 480, "outputTokenLimit": 8192, "supportedActions": []string{"predict"},
+	},
+}
+
+// NewGeminiServer starts a server that imitates the Gemini API generateContent,
+// streamGenerateContent, countTokens, batchEmbedContents, predict (of image
+// generation), batchGenerateContent and models.list methods, the Files API, the
+// batches.get method and the cachedContents.create and get methods, replying by
+// the fake model described in Do. Create the service to test with
+// "gemini:base=<server URL>&key=<any key>".
+func NewGeminiServer() *httptest.Server {
+	model := newFakeModel()
+	files := &geminiFiles{model: model, uploads: make(map[string]*fakeFile)}
+	caches := &geminiCaches{model: model, items: make(map[string]*geminiCache)}
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasPrefix(r.URL.Path, "/v1beta/files") || strings.HasPrefix(r.URL.Path, "/upload/v1beta/files") {
+			if r.Header.Get("X-Goog-Api-Key") == "" {
+				geminiError(w, http.StatusUnauthorized, "missing API key")
+				return
+			}
+			files.serve(w, r)
+			return
+		}
+		if strings.HasPrefix(r.URL.Path, "/v1beta/cachedContents") {
+			if r.Header.Get("X-Goog-Api-Key") == "" {
+				geminiError(w, http.StatusUnauthorized, "missing API key")
+				return
+			}
+			caches.serve(w, r)
+			return
+		}
+		if id, ok := strings.CutPrefix(r.URL.Path, "/v1beta/batches/"); ok {
+			if r.Header.Get("X-Goog-Api-Key") == "" {
+				geminiError(w, http.StatusUnauthorized, "missing API key")
+				return
+			}
+			if b, ok := model.batch(id); ok && r.Method == http.MethodGet {
+				writeJSON(w, http.StatusOK, geminiBatch(b))
+				return
+			}
+			geminiError(w, http.StatusNotFound, "batch not found: "+id)
+			return
+		}
+		name, method, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/v1beta/models/"), ":")
+		list := r.Method == http.MethodGet && r.URL.Path == "/v1beta/models"
+		if !list && (r.Method != http.MethodPost || name == r.URL.Path) {
+			geminiError(w, http.StatusNotFound, "not found: "+r.URL.Path)
+			return
+		}
+		if r.Header.Get("X-Goog-Api-Key") == "" {
+			geminiError(w, http.StatusUnauthorized, "missing API key")
+			return
+		}
+		if list {
+			writeJSON(w, http.StatusOK, map[string]any{"models": geminiModels})
+			return
+		}
+		switch method {
+		case "generateContent", "streamGenerateContent":
+			var in geminiRequest
+			if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+				geminiError(w, http.StatusBadRequest, err.Error())
+				return
+			}
+			req, err := caches.fake(&in)
+			if err != nil {
+				geminiError(w, http.StatusBadRequest, err.Error())
+				return
+			}
+			reply, err := model.reply(req)
+			if err != nil {
+				geminiError(w, http.StatusBadRequest, err.Error())
+				return
+			}
+			if method == "streamGenerateContent" {
+				geminiStream(w, name, reply)
+				return
+			}
+			var parts []*geminiPart
+			for _, part := range reply.parts {
+				parts = append(parts, geminiParts(part)...)
+			}
+			writeJSON(w, http.StatusOK, geminiResponse(name, parts, reply))
+		case "batchGenerateContent":
+			geminiBatches(w, r, model, name)
+		case "countTokens":
+			var in geminiRequest
+			if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+				geminiError(w, http.StatusBadRequest, err.Error())
+				return
+			}
+			writeJSON(w, http.StatusOK, map[string]any{"totalTokens": in.fake().inputTokens()})
+		case "batchEmbedContents":
+			var in struct {
+				Requests []struct {
+					Content              geminiContent `json:"content"`
+					OutputDimensionality int64         `json:"outputDimensionality"`
+				} `json:"requests"`
+			}
+			if err := json.NewDecoder(r.Body).Decode(&in); err != nil || len(in.Requests) == 0 {
+				geminiError(w, http.StatusBadRequest, "requests are required")
+				return
+			}
+			embeddings := make([]any, len(in.Requests))
+			for i, req := range in.Requests {
+				var n int
+				for _, part := range req.Content.Parts {
+					n += len(part.Text)
+					if part.InlineData != nil {
+						n += len(part.InlineData.Data)
+					}
+				}
+				embeddings[i] = map[string]any{"values": embedding(n, req.OutputDimensionality)}
+			}
+			writeJSON(w, http.StatusOK, map[string]any{"embeddings": embeddings})
+		case "predict":
+			var in struct {
+				Instances []struct {
+					Prompt string `json:"prompt"`
+				} `json:"instances"`
+			}
+			if err := json.NewDecoder(r.Body).Decode(&in); err != nil || len(in.Instances) == 0 || in.Instances[0].Prompt == "" {
+				geminiError(w, http.StatusBadRequest, "prompt is required")
+				return
+			}
+			writeJSON(w, http.StatusOK, map[string]any{
+				"predictions": []any{
+					map[string]any{"bytesBase64Encoded": fakePNG, "mimeType": "image/png"},
+				},
+			})
+		default:
+			geminiError(w, http.StatusNotFound, "unknown method: "+method)
+		}
+	}))
+}
+
+// fakePNG is a 1x1 PNG image, in base64.
+const fakePNG = "iVBORw0KGgoAAAANSUhEUgAAAAEAAAABCAQAAAC1HAwCAAAAC0lEQVR42mNkYAAAAAYAAjCB0C8AAAAASUVORK5CYII="
+
+// -----------------------------------------------------------------------------
@@ -0,0 +1,95 @@
+/*
+ * Copyright (c) 2026 The XGo Authors (xgo.dev). All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package xaitest
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+)
+
+// -----------------------------------------------------------------------------
+
+func klingReply(w http.ResponseWriter, status int, code int, msg string, data any) {
+	writeJSON(w, status, map[string]any{
+		"code":       code,
+		"message":    msg,
+		"request_id": "req-1",
+		"data":       data,
+	})
+}
+
+// NewKlingServer starts a server that imitates the task API of Kling image
+// generation (POST /v1/images/generations, and GET /v1/images/generations/<id>
+// to query the task). A task succeeds at the first query, with an image of the
+// URL <server URL>/images/<id>.png. Create the service to test with
+// "kling:base=<server URL>&token=<any token>".
+func NewKlingServer() *httptest.Server {
+	model := newFakeModel()
+	var mu sync.Mutex
+	tasks := make(map[string]bool)
+	var srv *httptest.Server
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasPrefix(r.Header.Get("Authorization"), "Bearer ") {
+			klingReply(w, http.StatusUnauthorized, 1000, "Authentication failed", nil)
+			return
+		}
+		const path = "/v1/images/generations"
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == path:
+			var in struct {
+				ModelName string `json:"model_name"`
+				Prompt    string `json:"prompt"`
+			}
+			if err := json.NewDecoder(r.Body).Decode(&in); err != nil || in.Prompt == "" || in.ModelName == "" {
+				klingReply(w, http.StatusBadRequest, 1201, "model_name and prompt are required", nil)
+				return
+			}
+			id := model.next("task-")
+			mu.Lock()
+			tasks[id] = true
+			mu.Unlock()
+			klingReply(w, http.StatusOK, 0, "SUCCEED", map[string]any{
+				"task_id": id, "task_status": "submitted", "created_at": 0, "updated_at": 0,
+			})
+		case r.Method == http.MethodGet && strings.HasPrefix(r.URL.Path, path+"/"):
+			id := strings.TrimPrefix(r.URL.Path, path+"/")
+			mu.Lock()
+			ok := tasks[id]
+			mu.Unlock()
+			if !ok {
+				klingReply(w, http.StatusNotFound, 1203, "task not found", nil)
+				return
+			}
+			klingReply(w, http.StatusOK, 0, "SUCCEED", map[string]any{
+				"task_id": id, "task_status": "succeed", "created_at": 0, "updated_at": 0,
+				"task_result": map[string]any{
+					"images": []any{
+						map[string]any{"index": 0, "url": srv.URL + "/images/" + id + ".png"},
+					},
+				},
+			})
+		default:
+			klingReply(w, http.StatusNotFound, 1203, "not found: "+r.URL.Path, nil)
+		}
+	}))
+	return srv
+}
+
+// -----------------------------------------------------------------------------
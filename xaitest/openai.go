@@ -0,0 +1,586 @@
+/*
+ * Copyright (c) 2026 The XGo Authors (xgo.dev). All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package xaitest
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// -----------------------------------------------------------------------------
+
+type openaiRequest struct {
+	Model        string          `json:"model"`
+	Instructions string          `json:"instructions"`
+	Input        json.RawMessage `json:"input"`
+	Tools        []struct {
+		Type string `json:"type"`
+		Name string `json:"name"`
+	} `json:"tools"`
+	Reasoning struct {
+		Effort  string `json:"effort"`
+		Summary string `json:"summary"`
+	} `json:"reasoning"`
+	Include         []string `json:"include"`
+	MaxOutputTokens int64    `json:"max_output_tokens"`
+	Stream          bool     `json:"stream"`
+}
+
+type openaiItem struct {
+	Type      string          `json:"type"`
+	Role      string          `json:"role"`
+	Content   json.RawMessage `json:"content"`
+	ID        string          `json:"id"`
+	CallID    string          `json:"call_id"`
+	Name      string          `json:"name"`
+	Arguments string          `json:"arguments"`
+	Output    json.RawMessage `json:"output"`
+	Summary   []struct {
+		Text string `json:"text"`
+	} `json:"summary"`
+	EncryptedContent string `json:"encrypted_content"`
+}
+
+type openaiContent struct {
+	Type     string `json:"type"`
+	Text     string `json:"text"`
+	ImageURL string `json:"image_url"`
+	FileID   string `json:"file_id"`
+	FileData string `json:"file_data"`
+	FileURL  string `json:"file_url"`
+}
+
+// openaiMIME returns the MIME type of a data URL, or kind if it isn't one.
+func openaiMIME(url, kind string) string {
+	if rest, ok := strings.CutPrefix(url, "data:"); ok {
+		if mime, _, ok := strings.Cut(rest, ";"); ok {
+			return mime
+		}
+	}
+	return kind
+}
+
+func (p *openaiContent) fake() (part fakePart, ok bool) {
+	switch p.Type {
+	case "input_text", "output_text":
+		return fakePart{kind: fakeText, text: p.Text}, true
+	case "input_image":
+		return fakePart{kind: fakeImage, text: openaiMIME(p.ImageURL, "url"), file: p.FileID}, true
+	case "input_file":
+		return fakePart{kind: fakeDoc, text: openaiMIME(p.FileData, "url"), file: p.FileID}, true
+	}
+	return
+}
+
+// openaiContents decodes content that is either a string or a list of contents.
+func openaiContents(content json.RawMessage) (ret []openaiContent, err error) {
+	var text string
+	if err = json.Unmarshal(content, &text); err == nil {
+		return []openaiContent{{Type: "input_text", Text: text}}, nil
+	}
+	err = json.Unmarshal(content, &ret)
+	return
+}
+
+// openaiReasoningID returns the ID of the reasoning item of the signature. It is
+// used as the signature if the encrypted content isn't passed back.
+func openaiReasoningID(sig string) string {
+	return "rs_" + strings.TrimPrefix(sig, "sig-")
+}
+
+func (p *openaiRequest) fake() (*fakeRequest, error) {
+	ret := &fakeRequest{
+		maxTokens: p.MaxOutputTokens,
+		thinking:  p.Reasoning.Effort != "" && p.Reasoning.Effort != "none",
+		system:    p.Instructions,
+	}
+	for _, tool := range p.Tools {
+		switch {
+		case tool.Type == "function":
+			ret.tools = append(ret.tools, tool.Name)
+		case strings.HasPrefix(tool.Type, "web_search"):
+			ret.webSearch = true
+		}
+	}
+	var items []openaiItem
+	if err := json.Unmarshal(p.Input, &items); err != nil {
+		var text string
+		if json.Unmarshal(p.Input, &text) != nil {
+			return nil, err
+		}
+		items = []openaiItem{{Role: "user", Content: p.Input}}
+	}
+	for _, item := range items {
+		var parts []fakePart
+		assistant := true
+		switch item.Type {
+		case "", "message":
+			contents, err := openaiContents(item.Content)
+			if err != nil {
+				return nil, err
+			}
+			if item.Role == "system" || item.Role == "developer" {
+				for _, c := range contents {
+					ret.system += c.Text
+				}
+				continue
+			}
+			for _, c := range contents {
+				if part, ok := c.fake(); ok {
+					parts = append(parts, part)
+				}
+			}
+			assistant = item.Role == "assistant"
+		case "reasoning":
+			part := fakePart{kind: fakeThinking, sig: item.EncryptedContent}
+			if part.sig == "" {
+				part.sig = "sig-" + strings.TrimPrefix(item.ID, "rs_")
+			}
+			for _, s := range item.Summary {
+				part.text += s.Text
+			}
+			parts = append(parts, part)
+		case "function_call":
+			var input map[string]any
+			if err := json.Unmarshal([]byte(item.Arguments), &input); err != nil {
+				return nil, err
+			}
+			parts = append(parts, fakePart{kind: fakeToolUse, id: item.CallID, name: item.Name, input: input})
+		case "function_call_output":
+			var output string
+			if err := json.Unmarshal(item.Output, &output); err != nil {
+				output = string(item.Output)
+			}
+			parts = append(parts, fakePart{kind: fakeToolResult, id: item.CallID, text: output})
+			assistant = false
+		default:
+			continue
+		}
+		// consecutive items of the same role are parts of a message
+		if n := len(ret.msgs); n > 0 && ret.msgs[n-1].assistant == assistant {
+			ret.msgs[n-1].parts = append(ret.msgs[n-1].parts, parts...)
+		} else {
+			ret.msgs = append(ret.msgs, fakeMsg{assistant: assistant, parts: parts})
+		}
+	}
+	// prompts are cached automatically, up to the last message.
+	if n := len(ret.msgs); n > 1 {
+		ret.cache(n-1, 0)
+	}
+	return ret, nil
+}
+
+type openaiServer struct {
+	model *fakeModel
+}
+
+func (p *openaiServer) item(id string, in *openaiRequest, part fakePart, done bool) map[string]any {
+	status := "completed"
+	if !done {
+		status = "in_progress"
+	}
+	switch part.kind {
+	case fakeThinking:
+		summary := []map[string]any{}
+		if done && in.Reasoning.Summary != "" {
+			summary = append(summary, map[string]any{"type": "summary_text", "text": part.text})
+		}
+		ret := map[string]any{"type": "reasoning", "id": openaiReasoningID(part.sig), "summary": summary}
+		if done && slices.Contains(in.Include, "reasoning.encrypted_content") {
+			ret["encrypted_content"] = part.sig
+		}
+		return ret
+	case fakeToolUse:
+		args := ""
+		if done {
+			b, _ := json.Marshal(part.input)
+			args = string(b)
+		}
+		return map[string]any{
+			"type": "function_call", "id": "fc_" + part.id, "call_id": part.id,
+			"name": part.name, "arguments": args, "status": status,
+		}
+	case fakeSearch:
+		action := map[string]any{"type": "search"}
+		if done {
+			action["query"] = part.text
+		}
+		return map[string]any{"type": "web_search_call", "id": "ws_" + part.id, "status": status, "action": action}
+	}
+	content := []map[string]any{}
+	if done {
+		annotations := []map[string]any{}
+		for _, c := range part.cites {
+			annotations = append(annotations, map[string]any{
+				"type": "url_citation", "url": c.url, "title": c.title, "start_index": c.start, "end_index": c.end,
+			})
+		}
+		content = append(content, map[string]any{"type": "output_text", "text": part.text, "annotations": annotations})
+	}
+	return map[string]any{
+		"type": "message", "id": "msg_" + strings.TrimPrefix(id, "resp_"), "role": "assistant",
+		"status": status, "content": content,
+	}
+}
+
+func (p *openaiServer) response(id string, in *openaiRequest, reply *fakeReply) map[string]any {
+	status, output := "in_progress", []map[string]any{}
+	if reply.stop != "" {
+		status = "completed"
+		for _, part := range reply.parts {
+			output = append(output, p.item(id, in, part, true))
+		}
+	}
+	ret := map[string]any{
+		"id":                  id,
+		"object":              "response",
+		"created_at":          0,
+		"model":               in.Model,
+		"status":              status,
+		"output":              output,
+		"parallel_tool_calls": true,
+		"tool_choice":         "auto",
+		"tools":               []any{},
+		"usage": map[string]any{
+			"input_tokens":          reply.inputTokens,
+			"input_tokens_details":  map[string]any{"cached_tokens": reply.cacheRead},
+			"output_tokens":         reply.outputTokens,
+			"output_tokens_details": map[string]any{"reasoning_tokens": 0},
+			"total_tokens":          reply.inputTokens + reply.outputTokens,
+		},
+	}
+	if reply.stop == stopMaxTokens {
+		ret["status"] = "incomplete"
+		ret["incomplete_details"] = map[string]any{"reason": "max_output_tokens"}
+	}
+	return ret
+}
+
+func (p *openaiServer) stream(w http.ResponseWriter, id string, in *openaiRequest, reply *fakeReply) {
+	sse := newSSEWriter(w)
+	seq := 0
+	send := func(typ string, ev map[string]any) {
+		ev["type"], ev["sequence_number"] = typ, seq
+		seq++
+		sse.send(typ, ev)
+	}
+	send("response.created", map[string]any{"response": p.response(id, in, &fakeReply{})})
+	for i, part := range reply.parts {
+		item := p.item(id, in, part, false)
+		itemID := item["id"]
+		send("response.output_item.added", map[string]any{"output_index": i, "item": item})
+		switch part.kind {
+		case fakeThinking:
+			if in.Reasoning.Summary != "" {
+				send("response.reasoning_summary_part.added", map[string]any{
+					"item_id": itemID, "output_index": i, "summary_index": 0,
+					"part": map[string]any{"type": "summary_text", "text": ""},
+				})
+				for _, s := range chunks(part.text) {
+					send("response.reasoning_summary_text.delta", map[string]any{
+						"item_id": itemID, "output_index": i, "summary_index": 0, "delta": s,
+					})
+				}
+			}
+		case fakeToolUse:
+			b, _ := json.Marshal(part.input)
+			send("response.function_call_arguments.delta", map[string]any{
+				"item_id": itemID, "output_index": i, "delta": string(b),
+			})
+		case fakeSearch:
+			for _, typ := range []string{"in_progress", "searching", "completed"} {
+				send("response.web_search_call."+typ, map[string]any{"item_id": itemID, "output_index": i})
+			}
+		default:
+			for _, s := range chunks(part.text) {
+				send("response.output_text.delta", map[string]any{
+					"item_id": itemID, "output_index": i, "content_index": 0, "delta": s, "logprobs": []any{},
+				})
+			}
+		}
+		send("response.output_item.done", map[string]any{"output_index": i, "item": p.item(id, in, part, true)})
+	}
+	typ := "response.completed"
+	if reply.stop == stopMaxTokens {
+		typ = "response.incomplete"
+	}
+	send(typ, map[string]any{"response": p.response(id, in, reply)})
+}
+
+func openaiError(w http.ResponseWriter, status int, typ, msg string) {
+	writeJSON(w, status, map[string]any{
+		"error": map[string]any{"type": typ, "message": msg, "param": nil, "code": nil},
+	})
+}
+
+// openaiFiles serves the Files API.
+func openaiFiles(w http.ResponseWriter, r *http.Request, model *fakeModel) {
+	object := func(f *fakeFile) map[string]any {
+		ret := map[string]any{
+			"object": "file", "id": f.id, "filename": f.name, "bytes": f.size,
+			"created_at": f.created.Unix(), "purpose": f.purpose, "status": "processed",
+		}
+		if !f.expires.IsZero() {
+			ret["expires_at"] = f.expires.Unix()
+		}
+		return ret
+	}
+	id, content := strings.CutSuffix(strings.TrimPrefix(r.URL.Path, "/v1/files/"), "/content")
+	switch {
+	case r.Method == http.MethodPost && r.URL.Path == "/v1/files":
+		name, mime, data, err := formFile(r, "file")
+		if err != nil {
+			openaiError(w, http.StatusBadRequest, "invalid_request_error", err.Error())
+			return
+		}
+		purpose := r.FormValue("purpose")
+		if purpose != "user_data" && purpose != "batch" {
+			openaiError(w, http.StatusBadRequest, "invalid_request_error", "unexpected purpose: "+purpose)
+			return
+		}
+		var ttl time.Duration
+		if secs := r.FormValue("expires_after[seconds]"); secs != "" {
+			n, err := strconv.Atoi(secs)
+			if err != nil || r.FormValue("expires_after[anchor]") != "created_at" {
+				openaiError(w, http.StatusBadRequest, "invalid_request_error", "invalid expires_after")
+				return
+			}
+			ttl = time.Duration(n) * time.Second
+		}
+		f := &fakeFile{name: name, mime: mime, size: int64(len(data)), purpose: purpose}
+		if purpose == "batch" {
+			f.data = data
+		}
+		writeJSON(w, http.StatusOK, object(model.addFile(f, ttl)))
+	case r.Method == http.MethodGet && r.URL.Path == "/v1/files":
+		data := []map[string]any{}
+		purpose := r.URL.Query().Get("purpose")
+		for _, f := range model.listFiles() {
+			if purpose == "" || f.purpose == purpose {
+				data = append(data, object(f))
+			}
+		}
+		writeJSON(w, http.StatusOK, map[string]any{"object": "list", "data": data, "has_more": false})
+	case r.Method == http.MethodGet:
+		if f, ok := model.file(id); ok && content {
+			w.Header().Set("Content-Type", "application/octet-stream")
+			w.Write(f.data)
+			return
+		} else if ok {
+			writeJSON(w, http.StatusOK, object(f))
+			return
+		}
+		openaiError(w, http.StatusNotFound, "invalid_request_error", "file not found: "+id)
+	case r.Method == http.MethodDelete:
+		if model.deleteFile(id) {
+			writeJSON(w, http.StatusOK, map[string]any{"object": "file", "id": id, "deleted": true})
+			return
+		}
+		openaiError(w, http.StatusNotFound, "invalid_request_error", "file not found: "+id)
+	default:
+		openaiError(w, http.StatusNotFound, "invalid_request_error", "not found: "+r.URL.Path)
+	}
+}
+
+// batches serves the Batch API of the Responses API, whose batches end once
+// created.
+func (p *openaiServer) batches(w http.ResponseWriter, r *http.Request) {
+	// files of batches are the input file, the output file and the error file.
+	batch := func(b *fakeBatch) map[string]any {
+		ret := map[string]any{
+			"object": "batch", "id": b.id, "endpoint": "/v1/responses", "status": "completed",
+			"completion_window": "24h", "created_at": b.created.Unix(), "completed_at": b.created.Unix(),
+			"input_file_id": b.files[0], "output_file_id": b.files[1],
+			"request_counts": map[string]any{
+				"total": len(b.results), "completed": len(b.results) - b.failed, "failed": b.failed,
+			},
+		}
+		if len(b.files) > 2 {
+			ret["error_file_id"] = b.files[2]
+		}
+		return ret
+	}
+	if r.Method == http.MethodPost && r.URL.Path == "/v1/batches" {
+		var in map[string]any
+		if err := json.NewDecoder(r.Body).Decode(&in); err != nil || in["endpoint"] != "/v1/responses" ||
+			in["completion_window"] != "24h" {
+			openaiError(w, http.StatusBadRequest, "invalid_request_error", "invalid batch")
+			return
+		}
+		id, _ := in["input_file_id"].(string)
+		f, ok := p.model.file(id)
+		if !ok || f.purpose != "batch" {
+			openaiError(w, http.StatusBadRequest, "invalid_request_error", "invalid input file: "+id)
+			return
+		}
+		var output, errs bytes.Buffer
+		b := &fakeBatch{files: []string{id}}
+		dec := json.NewDecoder(bytes.NewReader(f.data))
+		for dec.More() {
+			var line struct {
+				CustomID string        `json:"custom_id"`
+				Method   string        `json:"method"`
+				URL      string        `json:"url"`
+				Body     openaiRequest `json:"body"`
+			}
+			if err := dec.Decode(&line); err != nil || line.Method != http.MethodPost || line.URL != "/v1/responses" {
+				openaiError(w, http.StatusBadRequest, "invalid_request_error", "invalid input line")
+				return
+			}
+			reply, err := func() (*fakeReply, error) {
+				req, err := line.Body.fake()
+				if err != nil {
+					return nil, err
+				}
+				return p.model.reply(req)
+			}()
+			result := map[string]any{"id": p.model.next("batch_req_"), "custom_id": line.CustomID, "error": nil}
+			if err != nil {
+				result["response"] = map[string]any{
+					"status_code": http.StatusBadRequest,
+					"body": map[string]any{
+						"error": map[string]any{"type": "invalid_request_error", "code": "invalid_prompt", "message": err.Error()},
+					},
+				}
+				json.NewEncoder(&errs).Encode(result)
+				b.failed++
+			} else {
+				body := p.response(p.model.next("resp_"), &line.Body, reply)
+				result["response"] = map[string]any{"status_code": http.StatusOK, "body": body}
+				json.NewEncoder(&output).Encode(result)
+			}
+			b.results = append(b.results, result)
+		}
+		for _, data := range [][]byte{output.Bytes(), errs.Bytes()} {
+			if len(data) > 0 || len(b.files) == 1 {
+				out := &fakeFile{name: "batch_output.jsonl", size: int64(len(data)), purpose: "batch_output", data: data}
+				b.files = append(b.files, p.model.addFile(out, 0).id)
+			}
+		}
+		writeJSON(w, http.StatusOK, batch(p.model.addBatch("batch_", b)))
+		return
+	}
+	id := strings.TrimPrefix(r.URL.Path, "/v1/batches/")
+	if b, ok := p.model.batch(id); ok && r.Method == http.MethodGet {
+		writeJSON(w, http.StatusOK, batch(b))
+		return
+	}
+	openaiError(w, http.StatusNotFound, "invalid_request_error", "batch not found: "+id)
+}
+
+// openaiModels are the models listed by the fake Models API.
+var openaiModels = []map[string]any{
+	{"object": "model", "id": "gpt-5", "created": 1754006400, "owned_by": "openai"},
+	{"object": "model", "id": "text-embedding-3-small", "created": 1705948997, "owned_by": "openai"},
+}
+
+// NewOpenAIServer starts a server that imitates the OpenAI Responses API
+// (POST /v1/responses and /v1/responses/input_tokens), Embeddings API (POST
+// /v1/embeddings), Models API (GET /v1/models), Files API (/v1/files) and Batch
+// API (/v1/batches), replying by the fake model described in Do. Create the
+// service to test with "openai:base=<server URL>/v1/&key=<any key>".
+func NewOpenAIServer() *httptest.Server {
+	srv := &openaiServer{model: newFakeModel()}
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		count := r.URL.Path == "/v1/responses/input_tokens"
+		embed := r.URL.Path == "/v1/embeddings"
+		list := r.Method == http.MethodGet && r.URL.Path == "/v1/models"
+		files := r.URL.Path == "/v1/files" || strings.HasPrefix(r.URL.Path, "/v1/files/")
+		batches := r.URL.Path == "/v1/batches" || strings.HasPrefix(r.URL.Path, "/v1/batches/")
+		if !list && !files && !batches && (r.Method != http.MethodPost || r.URL.Path != "/v1/responses" && !count && !embed) {
+			openaiError(w, http.StatusNotFound, "invalid_request_error", "not found: "+r.URL.Path)
+			return
+		}
+		if !strings.HasPrefix(r.Header.Get("Authorization"), "Bearer ") {
+			openaiError(w, http.StatusUnauthorized, "invalid_request_error", "missing API key")
+			return
+		}
+		if list {
+			writeJSON(w, http.StatusOK, map[string]any{"object": "list", "data": openaiModels})
+			return
+		}
+		if files {
+			openaiFiles(w, r, srv.model)
+			return
+		}
+		if batches {
+			srv.batches(w, r)
+			return
+		}
+		if embed {
+			openaiEmbed(w, r)
+			return
+		}
+		var in openaiRequest
+		if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+			openaiError(w, http.StatusBadRequest, "invalid_request_error", err.Error())
+			return
+		}
+		req, err := in.fake()
+		if err != nil {
+			openaiError(w, http.StatusBadRequest, "invalid_request_error", err.Error())
+			return
+		}
+		if count {
+			writeJSON(w, http.StatusOK, map[string]any{"object": "response.input_tokens", "input_tokens": req.inputTokens()})
+			return
+		}
+		reply, err := srv.model.reply(req)
+		if err != nil {
+			openaiError(w, http.StatusBadRequest, "invalid_request_error", err.Error())
+			return
+		}
+		id := srv.model.next("resp_")
+		if in.Stream {
+			srv.stream(w, id, &in, reply)
+		} else {
+			writeJSON(w, http.StatusOK, srv.response(id, &in, reply))
+		}
+	}))
+}
+
+// -----------------------------------------------------------------------------
+
+func openaiEmbed(w http.ResponseWriter, r *http.Request) {
+	var in struct {
+		Input      []string `json:"input"`
+		Model      string   `json:"model"`
+		Dimensions int64    `json:"dimensions"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&in); err != nil || len(in.Input) == 0 {
+		openaiError(w, http.StatusBadRequest, "invalid_request_error", "input is required")
+		return
+	}
+	data := make([]any, len(in.Input))
+	var tokens int
+	for i, text := range in.Input {
+		data[i] = map[string]any{"object": "embedding", "index": i, "embedding": embedding(len(text), in.Dimensions)}
+		tokens += len(strings.Fields(text))
+	}
+	writeJSON(w, http.StatusOK, map[string]any{
+		"object": "list", "data": data, "model": in.Model,
+		"usage": map[string]any{"prompt_tokens": tokens, "total_tokens": tokens},
+	})
+}
+
+// -----------------------------------------------------------------------------
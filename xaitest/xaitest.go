@@ -17,18 +17,763 @@
 package xaitest
 
 import (
+	"bytes"
 	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/goplus/xai"
 )
 
-// Do runs the test cases for xai.
-func Do(t *testing.T, ctx context.Context, uri string) {
-	ai, err := xai.New(ctx, uri)
+// -----------------------------------------------------------------------------
+
+// Do runs the conformance suite against the service created by uri, with model
+// for its requests, and opModels for its operations (model if none is given). It
+// covers:
+//
+//   - text generation, multi-turn conversations, images and documents
+//   - tool use and tool result round trips, and thinking round trips
+//   - streaming and stop reasons
+//   - transcripts, prompt caching and citations
+//   - token counting and embeddings
+//   - model catalogs, files and batches
+//   - operations
+//
+// Cases of features that the service doesn't support are skipped.
+//
+// The service should send its requests to a fake server of this package, e.g.
+// NewAnthropicServer, whose fake model replies deterministically:
+//
+//   - It replies "echo: <text>" to the text of the last user message, followed
+//     by " [image <mime>]" or " [document <mime>]" for each attachment of it.
+//   - If the last user message has tool results, it replies "tool result: <result>"
+//     instead, where the result is compacted if it is JSON.
+//   - If tools are given and the text starts with "call ", it calls the first tool
+//     with {"text": <rest of the text>}.
+//   - If the web search tool is given and the text starts with "search ", it
+//     searches <rest> by the server tool of the provider, if any, and replies
+//     "found: <rest>", citing https://example.com/<rest> titled <rest>.
+//     Replies to documents with citations enabled cite them, if the provider
+//     supports it.
+//   - The text reply is followed by " [system: <prompt>]" if there is a system
+//     prompt, " (turn <n>)" if there are n-1 assistant messages before, and
+//     " (thoughts <n>)" if they have n thinking parts.
+//   - If thinking is enabled (and returned), it thinks "thinking about: <text>"
+//     first, with a signature that is checked when the thinking is passed back.
+//   - If max output tokens is set, the reply is truncated to that many words.
+//   - The embedding of an input of n bytes is [n, n+1, ...], of the dimensions
+//     requested (3 by default).
+//   - Uploaded files referenced by messages are replied as attachments of their
+//     MIME types.
+//
+// Requests with tool results of unknown tool uses, thinking with unknown
+// signatures, references to unknown files, or without a trailing user message
+// are rejected.
+//
+// model should be listed by `Service.Models`. The fake servers list a few models
+// of their providers, including the ones tested by the providers of this module.
+func Do(t *testing.T, ctx context.Context, uri string, model xai.Model, opModels ...xai.Model) {
+	t.Helper()
+	svc, err := xai.New(ctx, uri)
+	if err != nil {
+		t.Fatal("xai.New:", err)
+	}
+	if len(opModels) == 0 {
+		opModels = []xai.Model{model}
+	}
+	s := &suite{ctx: ctx, svc: svc, model: model}
+	features := svc.Features()
+	if features&(xai.FeatureGen|xai.FeatureGenStream) != 0 {
+		s.tool = svc.ToolDef("get_weather").Description("Get the weather of a city.").InputSchema(map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"text": map[string]any{"type": "string", "description": "the city"},
+			},
+			"required": []string{"text"},
+		})
+	}
+	if features&xai.FeatureGen != 0 {
+		t.Run("Text", s.testText)
+		t.Run("MultiTurn", s.testMultiTurn)
+		t.Run("Image", s.testImage)
+		t.Run("Document", s.testDocument)
+		t.Run("ToolUse", s.testToolUse)
+		t.Run("Thinking", s.testThinking)
+		t.Run("StopReason", s.testStopReason)
+		t.Run("Transcript", s.testTranscript)
+		t.Run("Cache", s.testCache)
+		t.Run("Citations", s.testCitations)
+	}
+	if features&xai.FeatureGenStream != 0 {
+		t.Run("Stream", s.testStream)
+		t.Run("StreamThinking", s.testStreamThinking)
+		t.Run("StreamToolUse", s.testStreamToolUse)
+	}
+	if features&xai.FeatureCountTokens != 0 {
+		t.Run("CountTokens", s.testCountTokens)
+	}
+	if features&xai.FeatureEmbed != 0 {
+		t.Run("Embed", s.testEmbed)
+	}
+	t.Run("Models", s.testModels)
+	if features&xai.FeatureFiles != 0 {
+		t.Run("Files", s.testFiles)
+	}
+	if features&xai.FeatureBatch != 0 {
+		t.Run("Batch", s.testBatch)
+	}
+	if features&xai.FeatureOperation != 0 {
+		for _, m := range opModels {
+			t.Run("Operation/"+string(m), func(t *testing.T) { s.testOperation(t, m) })
+		}
+	}
+}
+
+type suite struct {
+	ctx   context.Context
+	svc   xai.Service
+	model xai.Model
+	tool  xai.Tool
+}
+
+func (s *suite) params(msgs ...xai.MsgBuilder) xai.GenParams {
+	return s.svc.GenParams().Model(s.model).MaxOutputTokens(4096).Messages(msgs...)
+}
+
+func (s *suite) user(text string) xai.MsgBuilder {
+	return s.svc.UserMsg().Text(text)
+}
+
+// gen sends the request, and returns the response and its only candidate.
+func (s *suite) gen(t *testing.T, params xai.GenParams) (xai.GenResponse, xai.Candidate) {
+	t.Helper()
+	resp, err := s.svc.Gen(s.ctx, params)
+	if err != nil {
+		t.Fatal("Gen:", err)
+	}
+	if n := resp.Len(); n != 1 {
+		t.Fatal("Gen: unexpected number of candidates:", n)
+	}
+	c := resp.At(0)
+	checkParts(c)
+	return resp, c
+}
+
+// genText sends the request, and checks the text of the response.
+func (s *suite) genText(t *testing.T, params xai.GenParams, want string) xai.Candidate {
+	t.Helper()
+	_, c := s.gen(t, params)
+	if got := textOf(c); got != want {
+		t.Fatalf("Gen: got %q, want %q", got, want)
+	}
+	return c
+}
+
+// checkParts calls all methods of the parts, which should work for all kinds of
+// parts.
+func checkParts(c xai.Candidate) {
+	for i := 0; i < c.Parts(); i++ {
+		part := c.Part(i)
+		part.AsBlob()
+		part.AsThinking()
+		part.AsToolUse()
+		part.AsToolResult()
+		part.AsCompaction()
+		part.Text()
+		part.Underlying()
+	}
+}
+
+func textOf(c xai.Candidate) string {
+	var b strings.Builder
+	for i := 0; i < c.Parts(); i++ {
+		b.WriteString(c.Part(i).Text())
+	}
+	return b.String()
+}
+
+func thinkingOf(t *testing.T, c xai.Candidate) xai.Thinking {
+	t.Helper()
+	for i := 0; i < c.Parts(); i++ {
+		if ret, ok := c.Part(i).AsThinking(); ok {
+			return ret
+		}
+	}
+	t.Fatal("no thinking part")
+	return xai.Thinking{}
+}
+
+func toolUseOf(t *testing.T, c xai.Candidate) xai.ToolUse {
+	t.Helper()
+	for i := 0; i < c.Parts(); i++ {
+		if ret, ok := c.Part(i).AsToolUse(); ok {
+			return ret
+		}
+	}
+	t.Fatal("no tool use part")
+	return xai.ToolUse{}
+}
+
+// checkToolUse checks the tool use of the prompt "call Paris".
+func checkToolUse(t *testing.T, use xai.ToolUse) {
+	t.Helper()
+	if use.Name != "get_weather" {
+		t.Fatal("ToolUse: unexpected name:", use.Name)
+	}
+	b, ok := use.Input.(json.RawMessage)
+	if !ok {
+		var err error
+		if b, err = json.Marshal(use.Input); err != nil {
+			t.Fatal("ToolUse: invalid input:", err)
+		}
+	}
+	var input struct {
+		Text string `json:"text"`
+	}
+	if err := json.Unmarshal(b, &input); err != nil || input.Text != "Paris" {
+		t.Fatalf("ToolUse: unexpected input %s (%v)", b, err)
+	}
+}
+
+func checkStopReason(t *testing.T, c xai.Candidate, want xai.StopReason) {
+	t.Helper()
+	if got := c.StopReason(); got != want {
+		t.Fatalf("StopReason: got %q, want %q", got, want)
+	}
+}
+
+// -----------------------------------------------------------------------------
+
+func (s *suite) testText(t *testing.T) {
+	resp, c := s.gen(t, s.params(s.user("hello")).System("be brief"))
+	if got, want := textOf(c), "echo: hello [system: be brief]"; got != want {
+		t.Fatalf("Gen: got %q, want %q", got, want)
+	}
+	checkStopReason(t, c, xai.EndTurn)
+	if u := resp.Usage(); u.InputTokens <= 0 || u.OutputTokens <= 0 {
+		t.Fatalf("Usage: unexpected %+v", u)
+	}
+}
+
+func (s *suite) testMultiTurn(t *testing.T) {
+	msgs := []xai.MsgBuilder{s.user("hello")}
+	c := s.genText(t, s.params(msgs...), "echo: hello")
+	msgs = append(msgs, c.ToMsg(), s.user("again"))
+	s.genText(t, s.params(msgs...), "echo: again (turn 2)")
+
+	msgs = []xai.MsgBuilder{s.user("hello"), s.svc.AssistantMsg().Text("hi"), s.user("again")}
+	s.genText(t, s.params(msgs...), "echo: again (turn 2)")
+}
+
+func (s *suite) testImage(t *testing.T) {
+	raw, _ := base64.StdEncoding.DecodeString(fakePNG)
+	img := s.svc.Images().FromBytes(xai.ImagePNG, "pixel.png", raw)
+	if img.ImageType() != xai.ImagePNG {
+		t.Fatal("ImageType:", img.ImageType())
+	}
+	s.genText(t, s.params(s.user("describe").Image(img)), "echo: describe [image image/png]")
+
+	img, err := s.svc.Images().FromBase64(xai.ImagePNG, "pixel.png", fakePNG)
+	if err != nil {
+		t.Fatal("FromBase64:", err)
+	}
+	s.genText(t, s.params(s.user("describe").Image(img)), "echo: describe [image image/png]")
+}
+
+const fakePDF = "%PDF-1.4\n1 0 obj << /Type /Catalog >> endobj\ntrailer << /Root 1 0 R >>\n%%EOF\n"
+
+func (s *suite) testDocument(t *testing.T) {
+	doc := s.svc.Docs().FromBytes(xai.DocPDF, "doc.pdf", []byte(fakePDF))
+	if doc.DocumentType() != xai.DocPDF {
+		t.Fatal("DocumentType:", doc.DocumentType())
+	}
+	s.genText(t, s.params(s.user("summarize").Doc(doc)), "echo: summarize [document application/pdf]")
+}
+
+// citationsOf returns the citations of the parts of c, and checks that their
+// spans are in the text of their parts.
+func citationsOf(t *testing.T, c xai.Candidate) (ret []xai.Citation) {
+	t.Helper()
+	for i := 0; i < c.Parts(); i++ {
+		part := c.Part(i)
+		for _, cite := range part.Citations() {
+			if cite.Start < 0 || cite.Start > cite.End || cite.End > len(part.Text()) {
+				t.Fatalf("Citations: span [%d, %d) out of %q", cite.Start, cite.End, part.Text())
+			}
+			ret = append(ret, cite)
+		}
+	}
+	return
+}
+
+func (s *suite) testCitations(t *testing.T) {
+	params := s.params(s.user("search golang")).Tools(s.svc.WebSearchTool())
+	check := func(c xai.Candidate) {
+		t.Helper()
+		if got, want := textOf(c), "found: golang"; got != want {
+			t.Fatalf("Gen: got %q, want %q", got, want)
+		}
+		cites := citationsOf(t, c)
+		if len(cites) != 1 || cites[0].URL != "https://example.com/golang" || cites[0].Title != "golang" || cites[0].DocIndex != -1 {
+			t.Fatalf("Citations: unexpected %+v", cites)
+		}
+	}
+	_, c := s.gen(t, params)
+	check(c)
+	if s.svc.Features()&xai.FeatureGenStream != 0 {
+		_, c = s.stream(t, params)
+		check(c)
+	}
+	// the web search is a standard tool use, which the agent loop doesn't run, and
+	// it is passed back with the reply
+	for i := 0; i < c.Parts(); i++ {
+		if use, ok := c.Part(i).AsToolUse(); ok && use.Name != xai.ToolWebSearch {
+			t.Fatal("ToolUse: unexpected", use)
+		}
+	}
+	conv := xai.NewConversation(s.svc).Params(func(p xai.GenParams) { p.Model(s.model) })
+	conv.Tools(s.svc.WebSearchTool())
+	if _, err := conv.SendText(s.ctx, "search golang"); err != nil {
+		t.Fatal("Send:", err)
+	}
+	if uses := conv.ToolUses(); len(uses) != 0 {
+		t.Fatal("ToolUses: unexpected", uses)
+	}
+	resp, err := conv.SendText(s.ctx, "again")
+	if err != nil {
+		t.Fatal("Send:", err)
+	}
+	if got, want := textOf(resp.At(0)), "echo: again (turn 2)"; got != want {
+		t.Fatalf("Send: got %q, want %q", got, want)
+	}
+
+	// documents are only cited by some providers, e.g. Claude
+	doc := s.svc.Docs().FromBytes(xai.DocPDF, "doc.pdf", []byte(fakePDF))
+	c = s.genText(t, s.params(s.user("summarize").Doc(doc).DocCitations()), "echo: summarize [document application/pdf]")
+	for _, cite := range citationsOf(t, c) {
+		if cite.DocIndex != 0 {
+			t.Fatalf("Citations: unexpected %+v", cite)
+		}
+	}
+}
+
+func (s *suite) testToolUse(t *testing.T) {
+	q := s.user("call Paris")
+	_, c := s.gen(t, s.params(q).Tools(s.tool))
+	checkStopReason(t, c, xai.EndTurn)
+	use := toolUseOf(t, c)
+	checkToolUse(t, use)
+
+	result := xai.ToolResult{ID: use.ID, Name: use.Name, Result: map[string]any{"temp": 20}}
+	msgs := []xai.MsgBuilder{q, c.ToMsg(), s.svc.UserMsg().ToolResult(result)}
+	s.genText(t, s.params(msgs...).Tools(s.tool), `tool result: {"temp":20} (turn 2)`)
+
+	// the tool use is passed back by MsgBuilder.ToolUse
+	msgs = []xai.MsgBuilder{q, s.svc.AssistantMsg().ToolUse(use), s.svc.UserMsg().ToolResult(result)}
+	s.genText(t, s.params(msgs...).Tools(s.tool), `tool result: {"temp":20} (turn 2)`)
+
+	result = xai.ToolResult{ID: use.ID, Name: use.Name, Result: errors.New("no data"), IsError: true}
+	msgs = []xai.MsgBuilder{q, c.ToMsg(), s.svc.UserMsg().ToolResult(result)}
+	_, c = s.gen(t, s.params(msgs...).Tools(s.tool))
+	if text := textOf(c); !strings.HasPrefix(text, "tool result: ") || !strings.Contains(text, "no data") {
+		t.Fatal("Gen: unexpected error result:", text)
+	}
+}
+
+func (s *suite) testThinking(t *testing.T) {
+	thinking := xai.ThinkingBudget(2048, true)
+	q := s.user("hello")
+	c := s.genText(t, s.params(q).Thinking(thinking), "echo: hello")
+	th := thinkingOf(t, c)
+	if th.Text != "thinking about: hello" || th.Signature == "" {
+		t.Fatalf("Thinking: unexpected %+v", th)
+	}
+
+	msgs := []xai.MsgBuilder{q, c.ToMsg(), s.user("again")}
+	s.genText(t, s.params(msgs...).Thinking(thinking), "echo: again (turn 2) (thoughts 1)")
+
+	// the thinking is passed back by MsgBuilder.Thinking
+	msgs = []xai.MsgBuilder{q, s.svc.AssistantMsg().Thinking(th).Text(textOf(c)), s.user("again")}
+	s.genText(t, s.params(msgs...).Thinking(thinking), "echo: again (turn 2) (thoughts 1)")
+}
+
+func (s *suite) testStopReason(t *testing.T) {
+	c := s.genText(t, s.params(s.user("one two three")).MaxOutputTokens(2), "echo: one")
+	checkStopReason(t, c, xai.StopMaxTokens)
+}
+
+func (s *suite) testTranscript(t *testing.T) {
+	thinking := xai.ThinkingBudget(2048, true)
+	raw, _ := base64.StdEncoding.DecodeString(fakePNG)
+	img := s.svc.Images().FromBytes(xai.ImagePNG, "pixel.png", raw)
+	q := s.user("call Paris").Image(img)
+	_, c := s.gen(t, s.params(q).Thinking(thinking).Tools(s.tool))
+	use := toolUseOf(t, c)
+	result := xai.ToolResult{ID: use.ID, Name: use.Name, Result: map[string]any{"temp": 20}}
+	msgs := []xai.MsgBuilder{q, c.ToMsg(), s.svc.UserMsg().ToolResult(result)}
+	want := `tool result: {"temp":20} (turn 2) (thoughts 1)`
+	s.genText(t, s.params(msgs...).Thinking(thinking).Tools(s.tool), want)
+
+	tr, err := xai.ExportMsgs(msgs...)
+	if err != nil {
+		t.Fatal("ExportMsgs:", err)
+	}
+	b, err := json.Marshal(tr)
+	if err != nil {
+		t.Fatal("json.Marshal:", err)
+	}
+	tr = new(xai.Transcript)
+	if err = json.Unmarshal(b, tr); err != nil {
+		t.Fatal("json.Unmarshal:", err)
+	}
+	var types []xai.PartType
+	for _, m := range tr.Messages {
+		for _, part := range m.Parts {
+			types = append(types, part.Type)
+		}
+	}
+	if got := fmt.Sprint(types); got != "[text image thinking tool_use tool_result]" {
+		t.Fatal("ExportMsgs: unexpected parts:", got)
+	}
+	if r := tr.Messages[2].Parts[0]; r.Name != use.Name || string(r.Result) != `{"temp":20}` {
+		t.Fatalf("ExportMsgs: unexpected tool result %+v", r)
+	}
+	s.genText(t, s.params(tr.Msgs(s.svc)...).Thinking(thinking).Tools(s.tool), want)
+}
+
+func (s *suite) testCache(t *testing.T) {
+	params := func() xai.GenParams {
+		msgs := []xai.MsgBuilder{
+			s.svc.UserMsg().Text("a long context to cache").CacheBreakpoint(time.Hour),
+			s.svc.AssistantMsg().Text("ok"),
+			s.user("hello"),
+		}
+		return s.params(msgs...).System("be brief").CacheSystem(0).Tools(s.tool).CacheTools(0)
+	}
+	want := "echo: hello [system: be brief] (turn 2)"
+	s.genText(t, params(), want)
+
+	// the prefix is read from the cache the second time
+	resp, c := s.gen(t, params())
+	if got := textOf(c); got != want {
+		t.Fatalf("Gen: got %q, want %q", got, want)
+	}
+	if u := resp.Usage(); u.CacheReadTokens <= 0 || u.CacheReadTokens > u.InputTokens {
+		t.Fatalf("Usage: unexpected %+v", u)
+	}
+}
+
+func (s *suite) testCountTokens(t *testing.T) {
+	msgs := []xai.MsgBuilder{s.user("hello"), s.svc.AssistantMsg().Text("hi there"), s.user("how are you")}
+	n, err := s.svc.CountTokens(s.ctx, s.params(msgs...))
 	if err != nil {
-		t.Fatalf("failed to create xai client: %v\n", err)
+		t.Fatal("CountTokens:", err)
+	}
+	if n != 6 {
+		t.Fatal("CountTokens: got", n, "want 6")
+	}
+	if s.svc.Features()&xai.FeatureGen != 0 {
+		resp, _ := s.gen(t, s.params(msgs...))
+		if u := resp.Usage(); u.InputTokens != n {
+			t.Fatal("CountTokens: got", n, "but Gen used", u.InputTokens)
+		}
+	}
+}
+
+func (s *suite) testEmbed(t *testing.T) {
+	params := xai.EmbedParams{Model: s.model, Texts: []string{"hello", "hi"}, Task: xai.EmbedRetrievalQuery}
+	emb, err := s.svc.Embed(s.ctx, params)
+	if err != nil {
+		t.Fatal("Embed:", err)
+	}
+	if got := fmt.Sprint(emb.Vectors); got != "[[5 6 7] [2 3 4]]" {
+		t.Fatal("Embed: unexpected vectors", got)
+	}
+	params.Dimensions = 2
+	if emb, err = s.svc.Embed(s.ctx, params); err != nil || len(emb.Vectors) != 2 || len(emb.Vectors[1]) != 2 {
+		t.Fatal("Embed: unexpected dimensions", emb, err)
+	}
+}
+
+// -----------------------------------------------------------------------------
+
+// stream sends the request by GenStream, checks the order of the events, and
+// returns the accumulator of the events.
+func (s *suite) stream(t *testing.T, params xai.GenParams) (*xai.Accumulator, xai.Candidate) {
+	t.Helper()
+	var acc xai.Accumulator
+	open := make(map[int]bool)
+	n := 0
+	for ev, err := range s.svc.GenStream(s.ctx, params) {
+		if err != nil {
+			t.Fatal("GenStream:", err)
+		}
+		if (n == 0) != (ev.Type == xai.EventStart) {
+			t.Fatalf("GenStream: unexpected event %d at %d", ev.Type, n)
+		}
+		switch ev.Type {
+		case xai.EventBlockStart:
+			open[ev.Index] = true
+		case xai.EventDelta, xai.EventBlockStop:
+			if !open[ev.Index] {
+				t.Fatalf("GenStream: event %d of block %d not started", ev.Type, ev.Index)
+			}
+			if ev.Type == xai.EventBlockStop {
+				delete(open, ev.Index)
+			}
+		case xai.EventStop:
+			if len(open) > 0 {
+				t.Fatal("GenStream: blocks not stopped:", open)
+			}
+		}
+		acc.Add(ev)
+		n++
 	}
-	// TODO(xsw): implement test cases for xai.
-	_ = ai
+	resp := acc.Response()
+	if resp == nil {
+		t.Fatal("GenStream: no EventStop")
+	}
+	if n := resp.Len(); n != 1 {
+		t.Fatal("GenStream: unexpected number of candidates:", n)
+	}
+	c := resp.At(0)
+	checkParts(c)
+	if got, want := acc.Text(), textOf(c); got != want {
+		t.Fatalf("GenStream: streamed %q, but got %q", got, want)
+	}
+	return &acc, c
+}
+
+func (s *suite) testStream(t *testing.T) {
+	q := s.user("hello world")
+	_, c := s.stream(t, s.params(q))
+	if got, want := textOf(c), "echo: hello world"; got != want {
+		t.Fatalf("GenStream: got %q, want %q", got, want)
+	}
+	checkStopReason(t, c, xai.EndTurn)
+
+	msgs := []xai.MsgBuilder{q, c.ToMsg(), s.user("again")}
+	s.genText(t, s.params(msgs...), "echo: again (turn 2)")
+
+	_, c = s.stream(t, s.params(s.user("one two three")).MaxOutputTokens(2))
+	checkStopReason(t, c, xai.StopMaxTokens)
+}
+
+func (s *suite) testStreamThinking(t *testing.T) {
+	thinking := xai.ThinkingBudget(2048, true)
+	q := s.user("hello")
+	acc, c := s.stream(t, s.params(q).Thinking(thinking))
+	if got, want := acc.Thinking(), "thinking about: hello"; got != want {
+		t.Fatalf("GenStream: thinking %q, want %q", got, want)
+	}
+	if th := thinkingOf(t, c); th.Text != "thinking about: hello" || th.Signature == "" {
+		t.Fatalf("Thinking: unexpected %+v", th)
+	}
+
+	msgs := []xai.MsgBuilder{q, c.ToMsg(), s.user("again")}
+	s.genText(t, s.params(msgs...).Thinking(thinking), "echo: again (turn 2) (thoughts 1)")
 }
+
+func (s *suite) testStreamToolUse(t *testing.T) {
+	q := s.user("call Paris")
+	acc, c := s.stream(t, s.params(q).Tools(s.tool))
+	uses := acc.ToolUses()
+	if len(uses) != 1 {
+		t.Fatal("GenStream: unexpected tool uses:", uses)
+	}
+	checkToolUse(t, uses[0])
+	use := toolUseOf(t, c)
+	checkToolUse(t, use)
+
+	result := xai.ToolResult{ID: use.ID, Name: use.Name, Result: map[string]any{"temp": 20}}
+	msgs := []xai.MsgBuilder{q, c.ToMsg(), s.svc.UserMsg().ToolResult(result)}
+	s.genText(t, s.params(msgs...).Tools(s.tool), `tool result: {"temp":20} (turn 2)`)
+}
+
+// -----------------------------------------------------------------------------
+
+func (s *suite) testModels(t *testing.T) {
+	models, err := s.svc.Models(s.ctx)
+	if err != nil {
+		t.Fatal("Models:", err)
+	}
+	seen := make(map[xai.Model]bool)
+	for _, m := range models {
+		if m.ID == "" || seen[m.ID] {
+			t.Fatalf("Models: empty or duplicate ID %q", m.ID)
+		}
+		seen[m.ID] = true
+	}
+	info, err := xai.FindModel(s.ctx, s.svc, s.model)
+	if err != nil {
+		t.Fatal("FindModel:", err)
+	}
+	if !info.Accepts(xai.ModalityText) {
+		t.Fatal("FindModel: text isn't accepted by", info.ID, info.InputModalities)
+	}
+	for _, action := range info.Actions {
+		if !slices.Contains(s.svc.Actions(s.model), action) {
+			t.Fatal("FindModel: unsupported action", action)
+		}
+	}
+	if _, err = xai.FindModel(s.ctx, s.svc, "xaitest-no-such-model"); !errors.Is(err, xai.ErrNotFound) {
+		t.Fatal("FindModel: want ErrNotFound, got", err)
+	}
+}
+
+func (s *suite) testFiles(t *testing.T) {
+	files := s.svc.Files()
+	raw, _ := base64.StdEncoding.DecodeString(fakePNG)
+	img, err := files.Upload(s.ctx, bytes.NewReader(raw), xai.UploadParams{Name: "pixel.png", MIME: "image/png"})
+	if err != nil {
+		t.Fatal("Upload:", err)
+	}
+	if img.ID == "" || img.Size != int64(len(raw)) || img.MIME != "image/png" {
+		t.Fatal("Upload:", img)
+	}
+	doc, err := files.Upload(s.ctx, strings.NewReader(fakePDF), xai.UploadParams{Name: "doc.pdf", MIME: "application/pdf"})
+	if err != nil {
+		t.Fatal("Upload:", err)
+	}
+	if f, err := files.Get(s.ctx, img.ID); err != nil || f.ID != img.ID || f.Size != img.Size || f.Name != "pixel.png" {
+		t.Fatal("Get:", f, err)
+	}
+	var ids []string
+	for f, err := range files.List(s.ctx) {
+		if err != nil {
+			t.Fatal("List:", err)
+		}
+		ids = append(ids, f.ID)
+	}
+	if !slices.Contains(ids, img.ID) || !slices.Contains(ids, doc.ID) {
+		t.Fatal("List:", ids)
+	}
+
+	s.genText(t, s.params(s.user("describe").ImageFile(img.AsImage())), "echo: describe [image image/png]")
+	s.genText(t, s.params(s.user("summarize").DocFile(doc.AsDoc())), "echo: summarize [document application/pdf]")
+
+	for _, f := range []*xai.File{img, doc} {
+		if err = files.Delete(s.ctx, f.ID); err != nil {
+			t.Fatal("Delete:", err)
+		}
+	}
+	if _, err = files.Get(s.ctx, img.ID); !errors.Is(err, xai.ErrNotFound) {
+		t.Fatal("Get: want ErrNotFound, got", err)
+	}
+}
+
+// -----------------------------------------------------------------------------
+
+func (s *suite) testBatch(t *testing.T) {
+	reqs := []xai.GenParams{
+		s.params(s.user("hello")),
+		s.params(s.user("world")).System("be brief"),
+		s.params(s.svc.AssistantMsg().Text("hi")), // rejected without a trailing user message
+	}
+	resp, err := s.svc.SubmitBatch(s.ctx, reqs)
+	if err != nil {
+		t.Fatal("SubmitBatch:", err)
+	}
+	if resp.ID() == "" {
+		t.Fatal("ID: empty")
+	}
+	progress := 0
+	results, err := resp.Wait(s.ctx, resp.WaitParams().Progress(func(xai.OperationResponse) {
+		progress++
+	}))
+	if err != nil {
+		t.Fatal("Wait:", err)
+	}
+	if !resp.Done() || progress == 0 {
+		t.Fatal("Wait: done", resp.Done(), "progress", progress)
+	}
+	if ended, total := resp.Counts(); ended != len(reqs) || total != len(reqs) || results.Len() != len(reqs) {
+		t.Fatal("Counts:", ended, total, "Len:", results.Len())
+	}
+	want := []string{"echo: hello", "echo: world [system: be brief]"}
+	for i := range results.Len() {
+		r, ok := results.At(i).(*xai.BatchResult)
+		if !ok || r.CustomID != strconv.Itoa(i) {
+			t.Fatal("At:", i, results.At(i))
+		}
+		if i == len(want) {
+			if r.Err == nil || r.Response != nil {
+				t.Fatal("At: want error, got", r.Response)
+			}
+			continue
+		}
+		if r.Err != nil {
+			t.Fatal("At:", i, r.Err)
+		}
+		if got := r.Response.At(0).Part(0).Text(); got != want[i] {
+			t.Fatalf("At(%d): got %q, want %q", i, got, want[i])
+		}
+	}
+}
+
+// -----------------------------------------------------------------------------
+
+func (s *suite) testOperation(t *testing.T, model xai.Model) {
+	actions := s.svc.Actions(model)
+	if len(actions) == 0 {
+		t.Skip("no actions")
+	}
+	for _, action := range actions {
+		op, err := s.svc.Operation(model, action)
+		if err != nil {
+			t.Fatal("Operation:", action, err)
+		}
+		schema := op.InputSchema()
+		for _, f := range schema.Fields() {
+			schema.Restriction(f.Name)
+		}
+		if op.CallParams() == nil {
+			t.Fatal("CallParams: nil of", action)
+		}
+	}
+	if _, err := s.svc.Operation(model, "xaitest_unknown"); !errors.Is(err, xai.ErrNotFound) {
+		t.Fatal("Operation: unexpected error of unknown action:", err)
+	}
+	for _, action := range actions {
+		if action == xai.GenImage {
+			s.testGenImage(t, model)
+		}
+	}
+}
+
+func (s *suite) testGenImage(t *testing.T, model xai.Model) {
+	op, err := s.svc.Operation(model, xai.GenImage)
+	if err != nil {
+		t.Fatal("Operation:", err)
+	}
+	resp, err := op.Call(s.ctx, op.CallParams().Set("Prompt", "a cat"))
+	if err != nil {
+		t.Fatal("Call:", err)
+	}
+	results, err := resp.Wait(s.ctx, resp.WaitParams())
+	if err != nil {
+		t.Fatal("Wait:", err)
+	}
+	if !resp.Done() && results == nil {
+		t.Fatal("Wait: not done")
+	}
+	if results.Len() == 0 {
+		t.Fatal("Wait: no results")
+	}
+	img, ok := results.At(0).(*xai.OutputImage)
+	if !ok || img.Image == nil {
+		t.Fatalf("Results: unexpected %#v", results.At(0))
+	}
+	if img.Blob() == nil && img.StgUri() == "" {
+		t.Fatal("Results: empty image")
+	}
+}
+
+// -----------------------------------------------------------------------------